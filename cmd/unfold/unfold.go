@@ -5,11 +5,14 @@ import (
 	"os"
 	"runtime/debug"
 
+	"github.com/aryannr97/unfold/pkg/audit"
 	"github.com/aryannr97/unfold/pkg/azure"
 	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/github"
 	"github.com/aryannr97/unfold/pkg/google"
 	"github.com/aryannr97/unfold/pkg/helpers"
 	"github.com/aryannr97/unfold/pkg/registry"
+	"github.com/aryannr97/unfold/pkg/scim"
 	"github.com/aryannr97/unfold/pkg/spinner"
 )
 
@@ -48,20 +51,7 @@ func run(reg registry.Registry) string {
 
 	// Get the command from the arguments
 	inputCommand := os.Args[1]
-	switch inputCommand {
-	case commands.Azure:
-		// Initialize the azure service
-		err := azure.StartService()
-		if err != nil {
-			return fmt.Sprintf("[unfold] %s", err.Error())
-		}
-	case commands.Google:
-		// Initialize the google service
-		err := google.StartService()
-		if err != nil {
-			return fmt.Sprintf("[unfold] %s", err.Error())
-		}
-	case commands.Version:
+	if inputCommand == commands.Version {
 		return getVersion()
 	}
 
@@ -73,22 +63,38 @@ func run(reg registry.Registry) string {
 	go spinner.Start()
 	defer spinner.Clear()
 
-	// Check if the sub-command or value is provided
-	if len(os.Args) < 3 {
+	// Check if the sub-command or value is provided, commands without sub-commands may be run without value
+	if _, ok := reg[inputCommand][commands.Value]; len(os.Args) < 3 && !ok {
 		output = "[unfold] provide valid sub-command or value for the command"
 	} else {
-		inputSubCommand := os.Args[2]
+		inputSubCommand := ""
+		if len(os.Args) > 2 {
+			inputSubCommand = os.Args[2]
+		}
 
 		if base, ok := reg[inputCommand]; !ok {
 			output = fmt.Sprintf("[unfold] %s command not found", inputCommand)
 		} else {
-			if cmd, ok := base[inputSubCommand]; !ok {
+			cmd, ok := base[inputSubCommand]
+			name, args := inputCommand+" "+inputSubCommand, os.Args[min(len(os.Args), 3):]
+			if !ok || inputSubCommand == commands.Value {
+				// Commands without sub-commands take the value as first argument
+				cmd, ok = base[commands.Value]
+				name, args = inputCommand, os.Args[2:]
+			}
+			if !ok {
 				output = fmt.Sprintf("[unfold] %s %s command not found", inputCommand, inputSubCommand)
 			} else {
-				err := cmd.GetFlagSet().Parse(os.Args[3:])
+				err := cmd.GetFlagSet().Parse(args)
+				if err == nil {
+					// Services are started after parsing the flags as they may alter the configuration
+					err = startService(inputCommand)
+				}
 				if err != nil {
 					output = fmt.Sprintf("[unfold] %s", err.Error())
 				} else {
+					// The mutating operations of the command are recorded in the audit log along with its arguments
+					audit.Begin(name, args)
 					output = cmd.Execute()
 				}
 			}
@@ -97,3 +103,22 @@ func run(reg registry.Registry) string {
 
 	return output
 }
+
+// startService initializes the service backing the given command
+func startService(command string) error {
+	switch command {
+	case commands.Azure:
+		// Initialize the azure service
+		return azure.StartService()
+	case commands.Google:
+		// Initialize the google service
+		return google.StartService()
+	case commands.GitHub:
+		// Initialize the github service
+		return github.StartService()
+	case commands.SCIM:
+		// Initialize the scim service
+		return scim.StartService()
+	}
+	return nil
+}
@@ -134,6 +134,51 @@ func Test_run(t *testing.T) {
 			cmdArgs:        []string{"unfold", "test", "subcommand", "-flag"},
 			expectedOutput: "[unfold] flag provided but not defined: -flag",
 		},
+		{
+			name: "test command taking a value",
+			args: args{
+				reg: registry.Registry{
+					"test": {
+						"": &MockCommand{
+							Output:  "test output",
+							FlagSet: flag.NewFlagSet("test", flag.ContinueOnError),
+						},
+					},
+				},
+			},
+			cmdArgs:        []string{"unfold", "test", "-flag", "value.json"},
+			expectedOutput: "[unfold] flag provided but not defined: -flag",
+		},
+		{
+			name: "test command taking a value success",
+			args: args{
+				reg: registry.Registry{
+					"test": {
+						"": &MockCommand{
+							Output:  "test output",
+							FlagSet: flag.NewFlagSet("test", flag.ContinueOnError),
+						},
+					},
+				},
+			},
+			cmdArgs:        []string{"unfold", "test", "value.json"},
+			expectedOutput: "test output",
+		},
+		{
+			name: "test command taking a value run without value",
+			args: args{
+				reg: registry.Registry{
+					"test": {
+						"": &MockCommand{
+							Output:  "test output",
+							FlagSet: flag.NewFlagSet("test", flag.ContinueOnError),
+						},
+					},
+				},
+			},
+			cmdArgs:        []string{"unfold", "test"},
+			expectedOutput: "test output",
+		},
 		{
 			name: "azure command failed service not started",
 			args: args{
@@ -0,0 +1,52 @@
+package apply
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// OperationResult represents the outcome of an operation of an applied plan
+type OperationResult struct {
+	Operation plan.Operation
+	Output    string
+	Err       error
+}
+
+// Validate starts the modules used by the plan and checks every operation is still current,
+// nothing is applied unless the whole plan is
+func Validate(p *plan.Plan) error {
+	started := map[string]bool{}
+	var errs []error
+	for i, op := range p.Operations {
+		k, ok := kinds[op.Kind]
+		if !ok {
+			errs = append(errs, fmt.Errorf("operation %d: unsupported kind %s", i+1, op.Kind))
+			continue
+		}
+		if !started[k.service] {
+			if err := services[k.service](); err != nil {
+				return fmt.Errorf("unable to start the %s service: %w", k.service, err)
+			}
+			started[k.service] = true
+		}
+		if err := k.validate(op); err != nil {
+			errs = append(errs, fmt.Errorf("operation %d %s: %w", i+1, op, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Apply executes the operations of the validated plan in order and stops at the first failure
+func Apply(p *plan.Plan) ([]OperationResult, error) {
+	results := make([]OperationResult, 0, len(p.Operations))
+	for i, op := range p.Operations {
+		output, err := kinds[op.Kind].apply(op)
+		results = append(results, OperationResult{Operation: op, Output: output, Err: err})
+		if err != nil {
+			return results, fmt.Errorf("operation %d failed, %d operation(s) not applied", i+1, len(p.Operations)-i-1)
+		}
+	}
+	return results, nil
+}
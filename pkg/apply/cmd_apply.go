@@ -0,0 +1,68 @@
+package apply
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// commandApplyConfig represents the configuration for the apply command
+type commandApplyConfig struct {
+	FlagSet *flag.FlagSet
+	// Hash is the hash of the plan approved at review
+	Hash *string
+}
+
+// Execute executes the apply command, running the operations of the approved plan file once they are all still current
+func (c commandApplyConfig) Execute() string {
+	path, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if path == "" {
+		return "[unfold] provide the plan file, unfold apply --hash sha256:<hash> plan.json"
+	}
+
+	p, err := plan.Read(path)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if err := p.Verify(*c.Hash); err != nil {
+		return fmt.Sprintf("[unfold] %s, nothing was applied", err.Error())
+	}
+	if err := Validate(p); err != nil {
+		return fmt.Sprintf("[unfold] the plan is no longer current, nothing was applied\n%s\nwrite a new plan with --plan-out", helpers.RedValue(err.Error()))
+	}
+
+	results, err := Apply(p)
+	lines := make([]string, 0, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			lines = append(lines, fmt.Sprintf("%d. %s %s", i+1, r.Operation, helpers.RedValue(r.Err.Error())))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%d. %s %s", i+1, r.Operation, helpers.GreenValue(r.Output)))
+	}
+	if err != nil {
+		return fmt.Sprintf("[unfold] plan %s %s\n%s", p.Hash, helpers.RedValue(err.Error()), strings.Join(lines, "\n"))
+	}
+	return fmt.Sprintf("[unfold] plan %s applied\n%s", p.Hash, strings.Join(lines, "\n"))
+}
+
+// GetFlagSet returns the flag set for the apply command
+func (c commandApplyConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandApplyConfig fetches the command apply config
+func fetchCommandApplyConfig() commandApplyConfig {
+	flagSet := flag.NewFlagSet(commands.Apply, flag.ContinueOnError)
+	return commandApplyConfig{
+		FlagSet: flagSet,
+		Hash:    flagSet.String("hash", "", "hash of the reviewed plan, as printed when the plan was written"),
+	}
+}
@@ -0,0 +1,154 @@
+package apply
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// fakeBackend records the operations applied by the fake kinds
+type fakeBackend struct {
+	stale   map[string]bool
+	fail    map[string]bool
+	applied []string
+}
+
+// useFakeKinds replaces the supported kinds by a fake one for the duration of the test
+func useFakeKinds(t *testing.T, backend *fakeBackend) {
+	t.Helper()
+	savedKinds, savedServices := kinds, services
+	t.Cleanup(func() { kinds, services = savedKinds, savedServices })
+	kinds = map[string]kind{
+		"fake": {
+			service: "fake",
+			validate: func(op plan.Operation) error {
+				if backend.stale[op.Member] {
+					return errors.New(op.Member + " changed")
+				}
+				return nil
+			},
+			apply: func(op plan.Operation) (string, error) {
+				if backend.fail[op.Member] {
+					return "", errors.New("http call error")
+				}
+				backend.applied = append(backend.applied, op.Member)
+				return "done", nil
+			},
+		},
+	}
+	services = map[string]func() error{"fake": func() error { return nil }}
+}
+
+func TestCommandApplyConfig_Execute(t *testing.T) {
+	ops := []plan.Operation{
+		{Kind: "fake", Mode: plan.AddMode, Member: "alice"},
+		{Kind: "fake", Mode: plan.AddMode, Member: "bob"},
+	}
+	tests := []struct {
+		name        string
+		ops         []plan.Operation
+		backend     *fakeBackend
+		want        []string
+		wantApplied []string
+	}{
+		{
+			name:        "apply every operation",
+			ops:         ops,
+			backend:     &fakeBackend{},
+			want:        []string{"applied", "1. add fake", "2. add fake"},
+			wantApplied: []string{"alice", "bob"},
+		},
+		{
+			name:    "refuse a stale plan",
+			ops:     ops,
+			backend: &fakeBackend{stale: map[string]bool{"bob": true}},
+			want:    []string{"the plan is no longer current, nothing was applied", "operation 2 add fake: bob changed"},
+		},
+		{
+			name:    "refuse an unsupported kind",
+			ops:     []plan.Operation{{Kind: "other", Mode: plan.AddMode}},
+			backend: &fakeBackend{},
+			want:    []string{"operation 1: unsupported kind other"},
+		},
+		{
+			name:        "stop at the first failure",
+			ops:         append(ops, plan.Operation{Kind: "fake", Mode: plan.AddMode, Member: "carol"}),
+			backend:     &fakeBackend{fail: map[string]bool{"bob": true}},
+			want:        []string{"operation 2 failed, 1 operation(s) not applied", "http call error"},
+			wantApplied: []string{"alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakeKinds(t, tt.backend)
+			path := filepath.Join(t.TempDir(), "plan.json")
+			p := plan.New(tt.ops...)
+			if err := p.Write(path); err != nil {
+				t.Fatalf("Plan.Write() error = %v", err)
+			}
+
+			c := fetchCommandApplyConfig()
+			c.GetFlagSet().Parse([]string{"--hash", p.Hash, path})
+			got := c.Execute()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandApplyConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+			if strings.Join(tt.backend.applied, ",") != strings.Join(tt.wantApplied, ",") {
+				t.Errorf("commandApplyConfig.Execute() applied %v, want %v", tt.backend.applied, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestCommandApplyConfig_Execute_InvalidPlan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	p := plan.New(plan.Operation{Kind: "fake", Mode: plan.AddMode, Member: "alice"})
+	if err := p.Write(path); err != nil {
+		t.Fatalf("Plan.Write() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "missing plan file",
+			want: "provide the plan file",
+		},
+		{
+			name: "unreadable plan file",
+			args: []string{filepath.Join(t.TempDir(), "missing.json")},
+			want: "no such file or directory",
+		},
+		{
+			name: "missing approved hash",
+			args: []string{path},
+			want: "provide the hash of the reviewed plan with --hash " + p.Hash,
+		},
+		{
+			name: "hash other than the approved one",
+			args: []string{path, "--hash", "sha256:" + strings.Repeat("0", 64)},
+			want: "the plan changed since its review, nothing was applied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &fakeBackend{}
+			useFakeKinds(t, backend)
+			c := fetchCommandApplyConfig()
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandApplyConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			if len(backend.applied) != 0 {
+				t.Errorf("commandApplyConfig.Execute() applied %v, want nothing", backend.applied)
+			}
+		})
+	}
+}
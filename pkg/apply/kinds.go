@@ -0,0 +1,73 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/azure"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/google"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// kind executes the operations of a kind of a plan
+type kind struct {
+	// service is the module started before the operations are validated
+	service string
+	// validate checks the operation is still current
+	validate func(op plan.Operation) error
+	// apply executes the operation and returns its output
+	apply func(op plan.Operation) (string, error)
+}
+
+var (
+	// kinds are the operations supported in a plan
+	kinds = map[string]kind{
+		plan.AzurePrivateAudience: {
+			service:  commands.Azure,
+			validate: azure.ValidatePlannedConfiguration,
+			apply:    applyAzurePrivateAudience,
+		},
+		plan.AzurePreviewAudience: {
+			service:  commands.Azure,
+			validate: azure.ValidatePlannedPreviewAudience,
+			apply:    applyAzurePreviewAudience,
+		},
+		plan.GoogleMembership: {
+			service:  commands.Google,
+			validate: google.ValidatePlannedMembership,
+			apply:    applyGoogleMembership,
+		},
+	}
+
+	// services start the modules used by the operations
+	services = map[string]func() error{
+		commands.Azure:  azure.StartService,
+		commands.Google: google.StartService,
+	}
+)
+
+// applyAzurePrivateAudience submits the private audience change and returns the azure job
+func applyAzurePrivateAudience(op plan.Operation) (string, error) {
+	logger, err := azure.ApplyPlannedConfiguration(op)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("azure job %s %s", logger.AzureJobID, logger.AzureJobResult), nil
+}
+
+// applyAzurePreviewAudience submits the preview audience change and returns the azure job
+func applyAzurePreviewAudience(op plan.Operation) (string, error) {
+	logger, err := azure.ApplyPlannedPreviewAudience(op)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("azure job %s %s", logger.AzureJobID, logger.AzureJobResult), nil
+}
+
+// applyGoogleMembership creates or deletes the membership
+func applyGoogleMembership(op plan.Operation) (string, error) {
+	if err := google.ApplyPlannedMembership(op); err != nil {
+		return "", err
+	}
+	return "done", nil
+}
@@ -0,0 +1,13 @@
+package apply
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandApplyConfig commandApplyConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	return &CommandModule{
+		CommandApplyConfig: fetchCommandApplyConfig(),
+	}
+}
@@ -0,0 +1,231 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+	"github.com/aryannr97/unfold/pkg/state"
+)
+
+const (
+	// ResultSucceeded is the result of an operation which completed
+	ResultSucceeded = "succeeded"
+	// ResultFailed is the result of an operation which failed
+	ResultFailed = "failed"
+
+	// redacted replaces the values of the secret arguments
+	redacted = "[REDACTED]"
+)
+
+// secretRegex matches the names of the flags and variables holding secrets
+var secretRegex = regexp.MustCompile(`(?i)(token|secret|password|passwd|credential|api-?key)`)
+
+// stderr is the writer the failures to write or forward the audit log are reported on
+var stderr io.Writer = os.Stderr
+
+// Entry represents a mutating operation in the audit log
+type Entry struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+	// User is the OS user who ran the command
+	User string `json:"user"`
+	// Profile is the label of the environment set in UNFOLD_PROFILE, e.g. prod
+	Profile string `json:"profile,omitempty"`
+	// Command and Args are the command line the operation was executed by, the secrets redacted
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Action is the mutation, e.g. google membership add
+	Action string `json:"action"`
+	// Targets are the resolved ids the operation changed, e.g. offer, product, plans and audience
+	Targets    []string `json:"targets"`
+	Result     string   `json:"result"`
+	Error      string   `json:"error,omitempty"`
+	AzureJobID string   `json:"azureJobId,omitempty"`
+	// Operation is the resolved operation of the access changes, as written to plan files
+	Operation *plan.Operation `json:"operation,omitempty"`
+	// Undoes is the id of the entry reverted by the operation
+	Undoes string `json:"undoes,omitempty"`
+}
+
+// invocation holds the command line of the running command, operations are only recorded once it is set
+var invocation struct {
+	command string
+	args    []string
+	undoes  string
+}
+
+// Begin sets the command line recorded with the operations executed by the running command
+func Begin(command string, args []string) {
+	invocation.command, invocation.args, invocation.undoes = command, Redact(args), ""
+}
+
+// Undoing marks the operations executed from now on by the running command as reverting the entry
+func Undoing(id string) {
+	invocation.undoes = id
+}
+
+// Path returns the path of the audit log.
+// UNFOLD_AUDIT_LOG takes precedence over audit.jsonl in the state directory.
+func Path() (string, error) {
+	if path := os.Getenv("UNFOLD_AUDIT_LOG"); path != "" {
+		return path, nil
+	}
+	dir, err := state.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+// Record appends the outcome of the mutating operation to the audit log and forwards it to the configured sinks.
+// The audit log is best effort, failures are reported but must not hide the outcome of the already executed operation.
+func Record(entry Entry, err error) {
+	if invocation.command == "" {
+		return
+	}
+	entry.Time = time.Now().UTC()
+	entry.ID = newID(entry.Time)
+	entry.User = helpers.CurrentUser()
+	entry.Profile = os.Getenv("UNFOLD_PROFILE")
+	entry.Command, entry.Args, entry.Undoes = invocation.command, invocation.args, invocation.undoes
+	entry.Result = ResultSucceeded
+	if err != nil {
+		entry.Result, entry.Error = ResultFailed, err.Error()
+	}
+
+	if err := appendEntry(entry); err != nil {
+		fmt.Fprintf(stderr, "[unfold] unable to write the audit log %s\n", helpers.RedValue(err.Error()))
+	}
+	if err := forward(entry); err != nil {
+		fmt.Fprintf(stderr, "[unfold] unable to forward the audit log %s\n", helpers.RedValue(err.Error()))
+	}
+}
+
+// newID returns a unique id of an entry recorded at the time, sortable by time
+func newID(t time.Time) string {
+	b := make([]byte, 3)
+	rand.Read(b) //nolint:errcheck
+	return fmt.Sprintf("%s-%s", t.Format("20060102T150405Z"), hex.EncodeToString(b))
+}
+
+// appendEntry appends the entry as a single line to the audit log
+func appendEntry(entry Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Filter selects the entries of the audit log
+type Filter struct {
+	// Since drops the entries recorded before, when set
+	Since time.Time
+	// Target keeps the entries having the target, when set
+	Target string
+}
+
+// matches checks the entry is selected by the filter
+func (f Filter) matches(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Target == "" {
+		return true
+	}
+	for _, target := range e.Targets {
+		if strings.EqualFold(target, f.Target) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the entries of the audit log selected by the filter, the most recent first
+func List(filter Filter) ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		// a line cut short by an interrupted write is skipped, the following entries are still valid
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if filter.matches(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// Redact returns the arguments with the values of the secret flags and name=value variables redacted
+func Redact(args []string) []string {
+	out := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		out[i] = arg
+		if redactNext {
+			out[i], redactNext = redacted, false
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !secretRegex.MatchString(name) {
+			continue
+		}
+		switch {
+		case hasValue:
+			out[i] = arg[:strings.Index(arg, "=")+1] + redacted
+		case strings.HasPrefix(arg, "-"):
+			redactNext = true
+		}
+	}
+	return out
+}
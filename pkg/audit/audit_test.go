@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// useAuditLog points the audit log at a temporary file and begins the invocation for the duration of the test
+func useAuditLog(t *testing.T, command string, args ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("UNFOLD_AUDIT_LOG", path)
+	t.Setenv("UNFOLD_AUDIT_SYSLOG", "")
+	t.Setenv("UNFOLD_AUDIT_HTTP_URL", "")
+	Begin(command, args)
+	t.Cleanup(func() { Begin("", nil) })
+	return path
+}
+
+func TestRecord(t *testing.T) {
+	path := useAuditLog(t, "azure configure", "-r", "-sid", "s-1", "-o", "offer-a")
+	t.Setenv("UNFOLD_PROFILE", "prod")
+
+	op := plan.Operation{Kind: plan.AzurePrivateAudience, Mode: plan.RemoveMode, Offer: "offer-a", AudienceID: "s-1"}
+	Record(Entry{Action: "azure private-audience remove", Targets: []string{"s-1", "offer-a"}, AzureJobID: "job-1", Operation: &op}, nil)
+	Record(Entry{Action: "google membership add", Targets: []string{"team", "bob@example.com"}}, errors.New("http call error"))
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines != 2 {
+		t.Fatalf("Record() wrote %d lines, want 2", lines)
+	}
+
+	entries, err := List(Filter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("List() = %d entries, want 2", len(entries))
+	}
+	var configure Entry
+	for _, e := range entries {
+		if e.Action == "azure private-audience remove" {
+			configure = e
+		}
+	}
+	if configure.ID == "" || configure.User == "" || configure.Profile != "prod" || configure.Command != "azure configure" ||
+		configure.Result != ResultSucceeded || configure.AzureJobID != "job-1" || !reflect.DeepEqual(configure.Operation, &op) {
+		t.Errorf("List() entry = %+v", configure)
+	}
+	if !reflect.DeepEqual(configure.Args, []string{"-r", "-sid", "s-1", "-o", "offer-a"}) {
+		t.Errorf("List() args = %v", configure.Args)
+	}
+}
+
+func TestRecord_WithoutInvocation(t *testing.T) {
+	path := useAuditLog(t, "")
+	Record(Entry{Action: "google membership add"}, nil)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Record() wrote the audit log outside of a command, stat error = %v", err)
+	}
+}
+
+func TestRecord_UnwritableLog(t *testing.T) {
+	useAuditLog(t, "google configure")
+	t.Setenv("UNFOLD_AUDIT_LOG", t.TempDir())
+	var buf bytes.Buffer
+	stderr = &buf
+	t.Cleanup(func() { stderr = os.Stderr })
+
+	Record(Entry{Action: "google membership add"}, nil)
+	if !strings.Contains(buf.String(), "unable to write the audit log") {
+		t.Errorf("Record() reported %q", buf.String())
+	}
+}
+
+func TestList_Filter(t *testing.T) {
+	path := useAuditLog(t, "google configure")
+	now := time.Now().UTC()
+	lines := []string{
+		`{"id":"old","time":"` + now.AddDate(0, 0, -10).Format(time.RFC3339) + `","action":"google membership add","targets":["team","bob@example.com"]}`,
+		`{"id":"recent","time":"` + now.AddDate(0, 0, -1).Format(time.RFC3339) + `","action":"google membership add","targets":["team","Alice@example.com"]}`,
+		`{"id":"cut`,
+		`{"id":"latest","time":"` + now.Format(time.RFC3339) + `","action":"google membership remove","targets":["team","bob@example.com"]}`,
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{
+			name: "all, the most recent first, skipping the cut line",
+			want: []string{"latest", "recent", "old"},
+		},
+		{
+			name:   "since",
+			filter: Filter{Since: now.AddDate(0, 0, -7)},
+			want:   []string{"latest", "recent"},
+		},
+		{
+			name:   "target",
+			filter: Filter{Target: "bob@example.com"},
+			want:   []string{"latest", "old"},
+		},
+		{
+			name:   "target ignores the case",
+			filter: Filter{Target: "alice@example.com"},
+			want:   []string{"recent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := List(tt.filter)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			got := []string{}
+			for _, e := range entries {
+				got = append(got, e.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "flag value",
+			args: []string{"-t", "team", "-token", "ghp_secret", "-r"},
+			want: []string{"-t", "team", "-token", "[REDACTED]", "-r"},
+		},
+		{
+			name: "flag with equal sign",
+			args: []string{"--token=ghp_secret", "-base-url=https://example.com"},
+			want: []string{"--token=[REDACTED]", "-base-url=https://example.com"},
+		},
+		{
+			name: "workflow variable",
+			args: []string{"onboard.yaml", "-var", "client_secret=abc", "-var", "tenant=t-1"},
+			want: []string{"onboard.yaml", "-var", "client_secret=[REDACTED]", "-var", "tenant=t-1"},
+		},
+		{
+			name: "nothing secret",
+			args: []string{"-sid", "s-1", "-o", "offer-a"},
+			want: []string{"-sid", "s-1", "-o", "offer-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Redact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandListConfig represents the configuration for the list command
+type commandListConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		Since  *string
+		Target *string
+		Limit  *int
+	}
+}
+
+// Execute executes the list command, listing the recent entries of the audit log
+func (c commandListConfig) Execute() string {
+	filter := Filter{Target: *c.Opts.Target}
+	if *c.Opts.Since != "" {
+		since, err := parseSince(*c.Opts.Since, time.Now())
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		filter.Since = since
+	}
+
+	entries, err := List(filter)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to read the audit log %s", helpers.RedValue(err.Error()))
+	}
+	if len(entries) == 0 {
+		return "[unfold] no audit entries recorded"
+	}
+	if *c.Opts.Limit > 0 && len(entries) > *c.Opts.Limit {
+		entries = entries[:*c.Opts.Limit]
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %s  %-24s %s  %s", e.ID, e.User, e.Action, strings.Join(e.Targets, ","), resultValue(e.Result))
+		if e.Profile != "" {
+			line += "  profile " + e.Profile
+		}
+		if e.AzureJobID != "" {
+			line += "  job " + e.AzureJobID
+		}
+		if e.Error != "" {
+			line += "  " + e.Error
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf("[unfold] audit entries\n%s", strings.Join(lines, "\n"))
+}
+
+// GetFlagSet returns the flag set for the list command
+func (c commandListConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// resultValue colors the result of an entry
+func resultValue(result string) string {
+	if result == ResultSucceeded {
+		return helpers.GreenValue(result)
+	}
+	return helpers.RedValue(result)
+}
+
+// parseSince returns the time of the since flag, a duration before now such as 7d or 12h, or a RFC 3339 date
+func parseSince(since string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(since, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(since); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, since); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, use a duration such as 7d or 12h, or a date such as 2006-01-02", since)
+}
+
+// fetchCommandListConfig fetches the command list config
+func fetchCommandListConfig() commandListConfig {
+	flagSet := flag.NewFlagSet(commands.List, flag.ContinueOnError)
+	return commandListConfig{
+		Opts: struct {
+			Since  *string
+			Target *string
+			Limit  *int
+		}{
+			Since:  flagSet.String("since", "", "only list the entries recorded since the duration, e.g. 7d or 12h, or the date"),
+			Target: flagSet.String("target", "", "only list the entries of the target, e.g. a subscription, tenant, group or email"),
+			Limit:  flagSet.Int("n", 50, "number of recent entries to list, 0 lists all"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_commandListConfig_Execute(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "list all",
+			want: []string{"audit entries", "azure private-audience add", "s-1,offer-a", "job job-1", "google membership remove", "http call error"},
+		},
+		{
+			name:    "list a target",
+			args:    []string{"--target", "S-1"},
+			want:    []string{"azure private-audience add"},
+			notWant: []string{"google membership remove"},
+		},
+		{
+			name: "list an unknown target",
+			args: []string{"-target", "unknown"},
+			want: []string{"no audit entries recorded"},
+		},
+		{
+			name: "invalid since",
+			args: []string{"--since", "yesterday"},
+			want: []string{"invalid since \"yesterday\""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useAuditLog(t, "azure configure")
+			Record(Entry{Action: "azure private-audience add", Targets: []string{"s-1", "offer-a"}, AzureJobID: "job-1"}, nil)
+			Record(Entry{Action: "google membership remove", Targets: []string{"team", "bob@example.com"}}, errors.New("http call error"))
+
+			c := fetchCommandListConfig()
+			if err := c.GetFlagSet().Parse(append([]string{"--since", "7d"}, tt.args...)); err != nil {
+				t.Fatal(err)
+			}
+			got := c.Execute()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandListConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("commandListConfig.Execute() = %v, do not want %v", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func Test_parseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since   string
+		want    time.Time
+		wantErr bool
+	}{
+		{since: "7d", want: time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)},
+		{since: "12h", want: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)},
+		{since: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{since: "2024-05-01T08:00:00Z", want: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)},
+		{since: "-7d", wantErr: true},
+		{since: "last week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.since, func(t *testing.T) {
+			got, err := parseSince(tt.since, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSince() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package audit
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandListConfig commandListConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	return &CommandModule{
+		CommandListConfig: fetchCommandListConfig(),
+	}
+}
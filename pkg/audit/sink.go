@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// syslogPriority is the priority of the forwarded syslog messages, facility log audit (13) and severity info (6)
+	syslogPriority = 13*8 + 6
+	// sinkTimeout bounds the forwarding of an entry to a sink
+	sinkTimeout = 10 * time.Second
+)
+
+// forward sends the entry to the syslog and HTTP sinks configured in the environment
+func forward(entry Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if addr := os.Getenv("UNFOLD_AUDIT_SYSLOG"); addr != "" {
+		if err := forwardSyslog(addr, b, entry.Time); err != nil {
+			errs = append(errs, fmt.Errorf("syslog %s: %w", addr, err))
+		}
+	}
+	if u := os.Getenv("UNFOLD_AUDIT_HTTP_URL"); u != "" {
+		if err := forwardHTTP(u, os.Getenv("UNFOLD_AUDIT_HTTP_TOKEN"), b); err != nil {
+			errs = append(errs, fmt.Errorf("http %s: %w", u, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// forwardSyslog sends the entry as RFC 5424 message to the syslog address, e.g. udp://host:514, tcp://host:514 or unix:///dev/log
+func forwardSyslog(addr string, b []byte, t time.Time) error {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return err
+	}
+	network, address := u.Scheme, u.Host
+	switch network {
+	case "udp", "tcp":
+	case "unix":
+		network, address = "unixgram", u.Path
+	default:
+		return fmt.Errorf("unsupported scheme %q, use udp|tcp|unix", u.Scheme)
+	}
+
+	conn, err := net.DialTimeout(network, address, sinkTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	msg := fmt.Sprintf("<%d>1 %s %s unfold %d audit - %s", syslogPriority, t.Format(time.RFC3339), hostname, os.Getpid(), b)
+	if network == "tcp" {
+		// non-transparent framing, the message ends at the newline
+		msg += "\n"
+	}
+	conn.SetDeadline(time.Now().Add(sinkTimeout)) //nolint:errcheck
+	_, err = conn.Write([]byte(msg))
+	return err
+}
+
+// forwardHTTP posts the entry as JSON to the URL, with the bearer token when given
+func forwardHTTP(u, token string, b []byte) error {
+	req, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := (&http.Client{Timeout: sinkTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("returned %v", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestForward_HTTP(t *testing.T) {
+	var got Entry
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got) //nolint:errcheck
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	useAuditLog(t, "scim configure")
+	t.Setenv("UNFOLD_AUDIT_HTTP_URL", server.URL)
+	t.Setenv("UNFOLD_AUDIT_HTTP_TOKEN", "sink-token")
+
+	Record(Entry{Action: "scim membership add", Targets: []string{"engineering", "bob"}}, nil)
+	if got.Action != "scim membership add" || got.Command != "scim configure" || auth != "Bearer sink-token" {
+		t.Errorf("forward() posted %+v with authorization %q", got, auth)
+	}
+}
+
+func TestForward_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	t.Setenv("UNFOLD_AUDIT_SYSLOG", "")
+	t.Setenv("UNFOLD_AUDIT_HTTP_URL", server.URL)
+
+	if err := forward(Entry{}); err == nil || !strings.Contains(err.Error(), "returned 500") {
+		t.Errorf("forward() error = %v, want returned 500", err)
+	}
+}
+
+func TestForward_Syslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	t.Setenv("UNFOLD_AUDIT_SYSLOG", "udp://"+conn.LocalAddr().String())
+	t.Setenv("UNFOLD_AUDIT_HTTP_URL", "")
+	if err := forward(Entry{Action: "google membership remove", Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}); err != nil {
+		t.Fatalf("forward() error = %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second)) //nolint:errcheck
+	b := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(b)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	msg := string(b[:n])
+	if !strings.HasPrefix(msg, "<110>1 2024-05-01T10:00:00Z ") || !strings.Contains(msg, ` unfold `) || !strings.Contains(msg, `"action":"google membership remove"`) {
+		t.Errorf("forward() sent %q", msg)
+	}
+}
+
+func TestForward_SyslogInvalidScheme(t *testing.T) {
+	t.Setenv("UNFOLD_AUDIT_SYSLOG", "http://localhost:514")
+	t.Setenv("UNFOLD_AUDIT_HTTP_URL", "")
+	if err := forward(Entry{}); err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+		t.Errorf("forward() error = %v, want unsupported scheme", err)
+	}
+}
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
+	"github.com/aryannr97/unfold/pkg/cache"
 	"github.com/aryannr97/unfold/pkg/helpers"
 )
 
@@ -16,7 +21,43 @@ type Resources struct {
 
 // TreeResource represents the tree resource returned by the Partner Center API
 type TreeResource struct {
+	Schema           string            `json:"$schema"`
+	ID               string            `json:"id"`
+	Plan             string            `json:"plan"`
+	Identity         TreeIdentity      `json:"identity"`
 	PrivateAudiences []PrivateAudience `json:"privateAudiences"`
+	PreviewAudiences []PreviewAudience `json:"previewAudiences"`
+	// CustomMeters are the metering dimensions of the offer, or their enablement on the plan, keyed by meter id
+	CustomMeters map[string]CustomMeter `json:"customMeters,omitempty"`
+}
+
+// CustomMeter represents a custom meter of the price and availability of an offer or a plan
+type CustomMeter struct {
+	// UniqueID is the dimension id of the usage events, only set on the offer meters
+	UniqueID      string `json:"uniqueID,omitempty"`
+	DisplayName   string `json:"displayName,omitempty"`
+	UnitOfMeasure string `json:"unitOfMeasure,omitempty"`
+	// Enabled reports whether the meter is enabled on the plan, only set on the plan meters
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// TreeIdentity represents the identity of a tree resource, externalId is the plan id shown in Partner Center
+type TreeIdentity struct {
+	ExternalID string `json:"externalId"`
+}
+
+// AudienceHit represents a private audience of an offer plan matching a searched id
+type AudienceHit struct {
+	Offer   string
+	Plan    string
+	Audtype string
+}
+
+// OfferSearchResult is the outcome of searching an id in the private audiences of one offer
+type OfferSearchResult struct {
+	Offer string
+	Hits  []AudienceHit
+	Err   error
 }
 
 // PrivateAudience represents the private audience returned by the Partner Center API
@@ -43,31 +84,148 @@ func Search(id string, offer string) string {
 	return fmt.Sprintf("given id %s in private audience", helpers.RedValue("not found"))
 }
 
+// SearchAllOffers concurrently searches for a given id in the private audiences of every configured offer
+// and returns the results sorted by offer name.
+func SearchAllOffers(id string) []OfferSearchResult {
+	results := make([]OfferSearchResult, 0, len(config.Offers))
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for name, offer := range config.Offers {
+		wg.Add(1)
+		go func(name, productID string) {
+			defer wg.Done()
+			hits, err := searchOffer(id, name, productID)
+			mu.Lock()
+			results = append(results, OfferSearchResult{Offer: name, Hits: hits, Err: err})
+			mu.Unlock()
+		}(name, offer.ProductDurableID)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Offer < results[j].Offer
+	})
+	return results
+}
+
+// searchOffer returns the plans of the offer whose private audience contains the id
+func searchOffer(id, offer, productID string) ([]AudienceHit, error) {
+	res, err := getResourceTree(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	// plan resources carry the plan name shown in Partner Center as external id
+	planNames := map[string]string{}
+	for _, obj := range res.Resources {
+		if strings.HasPrefix(obj.ID, "plan/") && obj.Identity.ExternalID != "" {
+			planNames[obj.ID] = obj.Identity.ExternalID
+		}
+	}
+
+	hits := []AudienceHit{}
+	for _, obj := range res.Resources {
+		for _, audience := range obj.PrivateAudiences {
+			if audience.ID != id {
+				continue
+			}
+			plan := obj.Plan
+			if name, ok := planNames[obj.Plan]; ok {
+				plan = name
+			}
+			if plan == "" {
+				plan = "-"
+			}
+			hits = append(hits, AudienceHit{Offer: offer, Plan: plan, Audtype: audience.Audtype})
+		}
+	}
+	return hits, nil
+}
+
+// formatSearchMatrix renders the search results as an offer x plan x audience type matrix
+func formatSearchMatrix(id string, results []OfferSearchResult) string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OFFER\tPLAN\tAUDIENCE TYPE") //nolint:errcheck
+
+	found := 0
+	for _, result := range results {
+		if result.Err != nil {
+			// keep multi-line marketplace responses on the row of the offer
+			fmt.Fprintf(w, "%s\t-\t%s\n", result.Offer, helpers.RedValue(strings.Join(strings.Fields(result.Err.Error()), " "))) //nolint:errcheck
+			continue
+		}
+		for _, hit := range result.Hits {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", hit.Offer, hit.Plan, helpers.GreenValue(hit.Audtype)) //nolint:errcheck
+			found++
+		}
+	}
+	w.Flush() //nolint:errcheck
+
+	if found == 0 {
+		sb.Reset()
+		fmt.Fprintf(sb, "given id %s in private audience of %d offer(s)", helpers.RedValue("not found"), len(results))
+		for _, result := range results {
+			if result.Err != nil {
+				fmt.Fprintf(sb, "\n%s: %s", result.Offer, helpers.RedValue(result.Err.Error()))
+			}
+		}
+		return sb.String()
+	}
+	return fmt.Sprintf("found %s in %d private audience(s) across %d offer(s)\n%s", helpers.GreenValue(id), found, len(results), strings.TrimSuffix(sb.String(), "\n"))
+}
+
 // GetPrivateAudienceListForOffer makes a GET request to the Partner Center API
 // to retrieve the private audience list for a specified offer.
 func GetPrivateAudienceListForOffer(offerID string) (TreeResource, error) {
-	reqURL := fmt.Sprintf("/rp/product-ingestion/resource-tree/product/%s", offerID)
-	url := instances[graphResourceIndex].BaseURL + reqURL
+	res, err := getResourceTree(offerID)
+	if err != nil {
+		return TreeResource{}, err
+	}
+
+	for _, obj := range res.Resources {
+		if len(obj.PrivateAudiences) > 0 {
+			return obj, nil
+		}
+	}
+	return TreeResource{}, fmt.Errorf("no private audience found for the offer %s", offerID)
+}
 
-	resp, httpErr := instances[graphResourceIndex].httpClient.Get(url)
+// getResourceTree returns the resource tree of the product, from the on-disk cache if still valid
+func getResourceTree(productID string) (Resources, error) {
+	var res Resources
+	if cache.Get(resourceTreeCacheKey(productID), &res) {
+		return res, nil
+	}
+
+	reqURL := fmt.Sprintf("/rp/product-ingestion/resource-tree/product/%s", productID)
+	url := instances[ingestionEndpoint].BaseURL + reqURL
+
+	resp, httpErr := instances[ingestionEndpoint].httpClient.Get(url)
 	if httpErr != nil {
-		return TreeResource{}, httpErr
+		return Resources{}, httpErr
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusOK {
-		var res Resources
-		json.NewDecoder(resp.Body).Decode(&res) //nolint:errcheck
-		for _, obj := range res.Resources {
-			if len(obj.PrivateAudiences) > 0 {
-				return obj, nil
-			}
-		}
+	if resp.StatusCode != http.StatusOK {
+		resBody := map[string]any{}
+		b, _ := io.ReadAll(resp.Body)
+		json.Unmarshal(b, &resBody) //nolint:errcheck
+		mb, _ := json.MarshalIndent(resBody, "", " ")
+		return Resources{}, fmt.Errorf("marketplace returned %d with response \n%v", resp.StatusCode, string(mb))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Resources{}, fmt.Errorf("json decode %w", err)
 	}
-	resBody := map[string]any{}
-	b, _ := io.ReadAll(resp.Body)
-	json.Unmarshal(b, &resBody) //nolint:errcheck
-	mb, _ := json.MarshalIndent(resBody, "", " ")
-	return TreeResource{}, fmt.Errorf("marketplace returned %d with response \n%v", resp.StatusCode, string(mb))
+
+	cache.Set(resourceTreeCacheKey(productID), res, resourceTreeCacheTTL)
+	return res, nil
+}
+
+// resourceTreeCacheKey returns the on-disk cache key for the resource tree of the product
+func resourceTreeCacheKey(productID string) string {
+	return "azure/resource-tree/" + productID
 }
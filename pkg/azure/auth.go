@@ -0,0 +1,430 @@
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // x5t thumbprints are SHA-1 by definition
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+	oauth2cc "golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	// SecretAuthMethod authenticates the app registration with its client secret
+	SecretAuthMethod = "secret"
+	// CertificateAuthMethod authenticates the app registration with a signed client assertion
+	CertificateAuthMethod = "certificate"
+	// WorkloadIdentityAuthMethod authenticates with the federated token of a workload identity
+	WorkloadIdentityAuthMethod = "workloadidentity"
+	// ManagedIdentityAuthMethod fetches tokens from an IMDS compatible managed identity endpoint
+	ManagedIdentityAuthMethod = "managedidentity"
+	// AzureCLIAuthMethod reuses the tokens of an existing az login
+	AzureCLIAuthMethod = "azcli"
+
+	// TokenVersionV1 uses the v1 token endpoint with the resource parameter
+	TokenVersionV1 = "v1"
+	// TokenVersionV2 uses the v2.0 token endpoint with the scope parameter
+	TokenVersionV2 = "v2"
+
+	// defaultIdentityEndpoint is the token endpoint of the Azure instance metadata service
+	defaultIdentityEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+	// clientAssertionType is the client_assertion_type for JWT client assertions
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// clientAssertionLifetime is the validity of a signed client assertion
+	clientAssertionLifetime = 10 * time.Minute
+)
+
+// AuthConfig contains the settings selecting how unfold authenticates against Microsoft Entra ID
+type AuthConfig struct {
+	// Method is one of secret (default), certificate, workloadidentity, managedidentity or azcli
+	Method string `json:"method" yaml:"method"`
+	// TokenVersion is the version of the token endpoint, v1 (default) or v2
+	TokenVersion string `json:"tokenVersion" yaml:"tokenVersion"`
+	// ClientCertificateFile is a PEM file holding the certificate and private key of the app registration
+	ClientCertificateFile string `json:"clientCertificateFile" yaml:"clientCertificateFile"`
+	// FederatedTokenFile is the file holding the federated token of a workload identity
+	FederatedTokenFile string `json:"federatedTokenFile" yaml:"federatedTokenFile"`
+	// IdentityEndpoint is the IMDS compatible token endpoint of a managed identity
+	IdentityEndpoint string `json:"identityEndpoint" yaml:"identityEndpoint"`
+	// IdentityHeader is the secret header value required by App Service style identity endpoints
+	IdentityHeader string `json:"identityHeader" yaml:"identityHeader"`
+}
+
+// azCLICommand runs the az CLI with the given arguments and returns its output, replaceable in tests
+var azCLICommand = func(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "az", args...).Output() //nolint:gosec // arguments are not user controlled
+}
+
+// loadAuthConfig loads the credentials and authentication settings from the environment
+func (c *AZConfig) loadAuthConfig() {
+	c.ClientID = os.Getenv("AZURE_CLIENT_ID")
+	c.ClientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+	c.TenantID = os.Getenv("AZURE_TENANT_ID")
+	c.TokenURL = os.Getenv("AZURE_TOKEN_URL")
+	c.Auth = AuthConfig{
+		Method:                os.Getenv("AZURE_AUTH_METHOD"),
+		TokenVersion:          os.Getenv("AZURE_TOKEN_VERSION"),
+		ClientCertificateFile: os.Getenv("AZURE_CLIENT_CERTIFICATE_FILE"),
+		FederatedTokenFile:    os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+		IdentityEndpoint:      firstNonEmpty(os.Getenv("AZURE_IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_ENDPOINT")),
+		IdentityHeader:        os.Getenv("IDENTITY_HEADER"),
+	}
+	if c.Auth.Method == "" {
+		c.Auth.Method = SecretAuthMethod
+	}
+	if c.Auth.TokenVersion == "" {
+		c.Auth.TokenVersion = TokenVersionV1
+	}
+}
+
+// tokenURL returns the token endpoint of the tenant, TokenURL takes precedence when set
+func (c *AZConfig) tokenURL() string {
+	if c.TokenURL != "" {
+		return c.TokenURL
+	}
+	if c.Auth.TokenVersion == TokenVersionV2 {
+		return fmt.Sprintf("%s/%s/oauth2/v2.0/token", c.authority(), c.TenantID)
+	}
+	return fmt.Sprintf("%s/%s/oauth2/token", c.authority(), c.TenantID)
+}
+
+// loginBaseURL returns the scheme and host of the login endpoint the token url points to
+func (c *AZConfig) loginBaseURL() string {
+	u, err := url.Parse(c.tokenURL())
+	if err != nil || u.Host == "" {
+		return c.authority()
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// tokenSource returns the token source issuing access tokens for the resource
+// with the configured authentication method.
+func (c *AZConfig) tokenSource(ctx context.Context, resource string) (oauth2.TokenSource, error) {
+	switch c.Auth.Method {
+	case "", SecretAuthMethod:
+		return c.clientCredentials(resource, nil).TokenSource(ctx), nil
+	case CertificateAuthMethod:
+		cert, key, err := loadClientCertificate(c.Auth.ClientCertificateFile)
+		if err != nil {
+			return nil, err
+		}
+		return oauth2.ReuseTokenSource(nil, &assertionTokenSource{
+			ctx:      ctx,
+			config:   c,
+			resource: resource,
+			assertion: func() (string, error) {
+				return signClientAssertion(c.ClientID, c.tokenURL(), cert, key)
+			},
+		}), nil
+	case WorkloadIdentityAuthMethod:
+		if c.Auth.FederatedTokenFile == "" {
+			return nil, errors.New("federated token file is required, set AZURE_FEDERATED_TOKEN_FILE")
+		}
+		return oauth2.ReuseTokenSource(nil, &assertionTokenSource{
+			ctx:      ctx,
+			config:   c,
+			resource: resource,
+			assertion: func() (string, error) {
+				// the federated token is rotated on disk, read it on every token request
+				b, err := os.ReadFile(c.Auth.FederatedTokenFile)
+				return strings.TrimSpace(string(b)), err
+			},
+		}), nil
+	case ManagedIdentityAuthMethod:
+		return oauth2.ReuseTokenSource(nil, &managedIdentityTokenSource{
+			ctx:      ctx,
+			config:   c,
+			resource: resource,
+		}), nil
+	case AzureCLIAuthMethod:
+		return oauth2.ReuseTokenSource(nil, &azureCLITokenSource{
+			ctx:      ctx,
+			tenantID: c.TenantID,
+			resource: resource,
+		}), nil
+	default:
+		return nil, fmt.Errorf("invalid auth method %s, must be one of %s|%s|%s|%s|%s", c.Auth.Method,
+			SecretAuthMethod, CertificateAuthMethod, WorkloadIdentityAuthMethod, ManagedIdentityAuthMethod, AzureCLIAuthMethod)
+	}
+}
+
+// clientCredentials returns the client credentials config for the resource,
+// requesting it as resource on the v1 endpoint and as scope on the v2 endpoint.
+func (c *AZConfig) clientCredentials(resource string, params url.Values) *oauth2cc.Config {
+	if params == nil {
+		params = url.Values{}
+	}
+
+	var scopes []string
+	if c.Auth.TokenVersion == TokenVersionV2 {
+		scopes = []string{strings.TrimSuffix(resource, "/") + "/.default"}
+	} else {
+		params.Set("resource", resource)
+	}
+
+	return &oauth2cc.Config{
+		ClientID:       c.ClientID,
+		ClientSecret:   c.ClientSecret,
+		TokenURL:       c.tokenURL(),
+		Scopes:         scopes,
+		EndpointParams: params,
+		AuthStyle:      oauth2.AuthStyleInParams,
+	}
+}
+
+// assertionTokenSource requests tokens with a client assertion instead of a client secret
+type assertionTokenSource struct {
+	ctx       context.Context
+	config    *AZConfig
+	resource  string
+	assertion func() (string, error)
+}
+
+// Token requests a new token with a fresh client assertion
+func (a *assertionTokenSource) Token() (*oauth2.Token, error) {
+	assertion, err := a.assertion()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client assertion: %w", err)
+	}
+
+	conf := *a.config
+	conf.ClientSecret = ""
+	return conf.clientCredentials(a.resource, url.Values{
+		"client_assertion_type": {clientAssertionType},
+		"client_assertion":      {assertion},
+	}).Token(a.ctx)
+}
+
+// loadClientCertificate loads the certificate and its RSA private key from a PEM file
+func loadClientCertificate(path string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	if path == "" {
+		return nil, nil, errors.New("client certificate file is required, set AZURE_CLIENT_CERTIFICATE_FILE")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cert *x509.Certificate
+	var key *rsa.PrivateKey
+	for block, rest := pem.Decode(b); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			if cert == nil {
+				cert, err = x509.ParseCertificate(block.Bytes)
+			}
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			var k any
+			k, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if rsaKey, ok := k.(*rsa.PrivateKey); ok {
+				key = rsaKey
+			} else if err == nil {
+				err = errors.New("only RSA private keys are supported")
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse client certificate file: %w", err)
+		}
+	}
+
+	if cert == nil || key == nil {
+		return nil, nil, errors.New("client certificate file must contain a certificate and its private key")
+	}
+	return cert, key, nil
+}
+
+// signClientAssertion returns a client assertion for the token endpoint signed with the certificate key
+func signClientAssertion(clientID, audience string, cert *x509.Certificate, key *rsa.PrivateKey) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{audience},
+		Issuer:    clientID,
+		Subject:   clientID,
+		ID:        hex.EncodeToString(jti),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(clientAssertionLifetime)),
+	})
+
+	thumbprint := sha1.Sum(cert.Raw) //nolint:gosec // x5t thumbprints are SHA-1 by definition
+	token.Header["x5t"] = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+
+	return token.SignedString(key)
+}
+
+// managedIdentityTokenSource requests tokens from an IMDS compatible managed identity endpoint
+type managedIdentityTokenSource struct {
+	ctx      context.Context
+	config   *AZConfig
+	resource string
+}
+
+// managedIdentityToken is the token response of a managed identity endpoint.
+// Expiries are numbers or numeric strings depending on the endpoint.
+type managedIdentityToken struct {
+	AccessToken string      `json:"access_token"`
+	TokenType   string      `json:"token_type"`
+	ExpiresIn   json.Number `json:"expires_in"`
+	ExpiresOn   json.Number `json:"expires_on"`
+}
+
+// Token requests a token for the resource from the managed identity endpoint
+func (m *managedIdentityTokenSource) Token() (*oauth2.Token, error) {
+	endpoint := m.config.Auth.IdentityEndpoint
+	if endpoint == "" {
+		endpoint = defaultIdentityEndpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("api-version", "2018-02-01")
+	q.Set("resource", m.resource)
+	if m.config.Auth.IdentityHeader != "" {
+		// App Service and Container Apps identity endpoints
+		q.Set("api-version", "2019-08-01")
+	}
+	if m.config.ClientID != "" {
+		// user assigned managed identity
+		q.Set("client_id", m.config.ClientID)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(m.ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	if m.config.Auth.IdentityHeader != "" {
+		req.Header.Set("X-IDENTITY-HEADER", m.config.Auth.IdentityHeader)
+	}
+
+	resp, err := contextClient(m.ctx).Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("managed identity endpoint returned %v with response %v", resp.StatusCode, string(b))
+	}
+
+	var res managedIdentityToken
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("json decode %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: res.AccessToken,
+		TokenType:   res.TokenType,
+		Expiry:      tokenExpiry(res.ExpiresOn, res.ExpiresIn),
+	}, nil
+}
+
+// azureCLITokenSource reuses the tokens of an existing az login
+type azureCLITokenSource struct {
+	ctx      context.Context
+	tenantID string
+	resource string
+}
+
+// azureCLIToken is the output of az account get-access-token
+type azureCLIToken struct {
+	AccessToken string      `json:"accessToken"`
+	TokenType   string      `json:"tokenType"`
+	ExpiresOn   string      `json:"expiresOn"`
+	ExpiresOnTS json.Number `json:"expires_on"`
+}
+
+// Token retrieves a token for the resource from the az CLI token cache
+func (a *azureCLITokenSource) Token() (*oauth2.Token, error) {
+	args := []string{"account", "get-access-token", "--resource", a.resource, "--output", "json"}
+	if a.tenantID != "" {
+		args = append(args, "--tenant", a.tenantID)
+	}
+
+	out, err := azCLICommand(a.ctx, args...)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("az account get-access-token failed, run az login: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("az account get-access-token failed, run az login: %w", err)
+	}
+
+	var res azureCLIToken
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, fmt.Errorf("json decode %w", err)
+	}
+
+	expiry := tokenExpiry(res.ExpiresOnTS, "")
+	if expiry.IsZero() {
+		// older CLI versions only report the local expiry time
+		expiry, _ = time.ParseInLocation("2006-01-02 15:04:05.999999", res.ExpiresOn, time.Local)
+	}
+
+	return &oauth2.Token{
+		AccessToken: res.AccessToken,
+		TokenType:   res.TokenType,
+		Expiry:      expiry,
+	}, nil
+}
+
+// tokenExpiry returns the expiry from the unix timestamp expiresOn, or from expiresIn seconds from now
+func tokenExpiry(expiresOn, expiresIn json.Number) time.Time {
+	if ts, err := strconv.ParseInt(expiresOn.String(), 10, 64); err == nil {
+		return time.Unix(ts, 0)
+	}
+	if secs, err := strconv.ParseInt(expiresIn.String(), 10, 64); err == nil {
+		return time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return time.Time{}
+}
+
+// contextClient returns the http client of the context as used by oauth2, or the default client
+func contextClient(ctx context.Context) *http.Client {
+	if c, ok := ctx.Value(oauth2.HTTPClient).(*http.Client); ok && c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
+
+// firstNonEmpty returns the first non empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
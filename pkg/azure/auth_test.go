@@ -0,0 +1,306 @@
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubTokenServer returns a local token server validating the token request
+// of the v1/v2 token endpoints and of IMDS compatible managed identity endpoints.
+func stubTokenServer(t *testing.T, validate func(r *http.Request) error) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			r.ParseForm() //nolint:errcheck
+		}
+		if err := validate(r); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error": "invalid_request", "error_description": %q}`, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "stub-token", "token_type": "Bearer", "expires_in": "3599"}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeTestCertificate writes a self-signed certificate and its private key to a PEM file
+func writeTestCertificate(t *testing.T) (string, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "unfold-test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	keyDER, _ := x509.MarshalPKCS8PrivateKey(key)
+	b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	b = append(b, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})...)
+
+	path := filepath.Join(t.TempDir(), "client-cert.pem")
+	os.WriteFile(path, b, 0o600) //nolint:errcheck
+	return path, cert
+}
+
+func TestAZConfig_tokenSource(t *testing.T) {
+	certFile, cert := writeTestCertificate(t)
+	federatedTokenFile := filepath.Join(t.TempDir(), "federated-token")
+	os.WriteFile(federatedTokenFile, []byte("federated-token\n"), 0o600) //nolint:errcheck
+
+	resource := "https://management.azure.com"
+
+	tests := []struct {
+		name     string
+		auth     AuthConfig
+		validate func(r *http.Request) error
+		azCLI    func(ctx context.Context, args ...string) ([]byte, error)
+		wantErr  string
+	}{
+		{
+			name: "client secret with v1 endpoint",
+			auth: AuthConfig{Method: SecretAuthMethod, TokenVersion: TokenVersionV1},
+			validate: func(r *http.Request) error {
+				if r.PostForm.Get("client_secret") != "test-secret" || r.PostForm.Get("resource") != resource {
+					return errors.New("expected client secret and resource")
+				}
+				return nil
+			},
+		},
+		{
+			name: "client secret with v2 endpoint",
+			auth: AuthConfig{Method: SecretAuthMethod, TokenVersion: TokenVersionV2},
+			validate: func(r *http.Request) error {
+				if r.PostForm.Get("scope") != resource+"/.default" || r.PostForm.Has("resource") {
+					return errors.New("expected scope without resource")
+				}
+				return nil
+			},
+		},
+		{
+			name: "client certificate assertion",
+			auth: AuthConfig{Method: CertificateAuthMethod, TokenVersion: TokenVersionV2, ClientCertificateFile: certFile},
+			validate: func(r *http.Request) error {
+				if r.PostForm.Has("client_secret") || r.PostForm.Get("client_assertion_type") != clientAssertionType {
+					return errors.New("expected client assertion without client secret")
+				}
+				claims := jwt.RegisteredClaims{}
+				token, err := jwt.ParseWithClaims(r.PostForm.Get("client_assertion"), &claims, func(*jwt.Token) (any, error) {
+					return cert.PublicKey, nil
+				})
+				if err != nil || token.Header["x5t"] == nil || claims.Subject != "test-client" {
+					return fmt.Errorf("invalid client assertion: %v", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:    "client certificate file missing",
+			auth:    AuthConfig{Method: CertificateAuthMethod},
+			wantErr: "client certificate file is required",
+		},
+		{
+			name:    "client certificate file without key",
+			auth:    AuthConfig{Method: CertificateAuthMethod, ClientCertificateFile: "testdata/test_ca_cert.txt"},
+			wantErr: "must contain a certificate and its private key",
+		},
+		{
+			name: "workload identity federated token",
+			auth: AuthConfig{Method: WorkloadIdentityAuthMethod, TokenVersion: TokenVersionV2, FederatedTokenFile: federatedTokenFile},
+			validate: func(r *http.Request) error {
+				if r.PostForm.Get("client_assertion") != "federated-token" {
+					return errors.New("expected federated token as client assertion")
+				}
+				return nil
+			},
+		},
+		{
+			name:    "workload identity federated token file missing",
+			auth:    AuthConfig{Method: WorkloadIdentityAuthMethod},
+			wantErr: "federated token file is required",
+		},
+		{
+			name: "managed identity endpoint",
+			auth: AuthConfig{Method: ManagedIdentityAuthMethod},
+			validate: func(r *http.Request) error {
+				if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != resource || r.URL.Query().Get("client_id") != "test-client" {
+					return errors.New("expected metadata header, resource and client id")
+				}
+				return nil
+			},
+		},
+		{
+			name: "managed identity endpoint with identity header",
+			auth: AuthConfig{Method: ManagedIdentityAuthMethod, IdentityHeader: "secret-header"},
+			validate: func(r *http.Request) error {
+				if r.Header.Get("X-IDENTITY-HEADER") != "secret-header" || r.URL.Query().Get("api-version") != "2019-08-01" {
+					return errors.New("expected identity header")
+				}
+				return nil
+			},
+		},
+		{
+			name: "managed identity endpoint error",
+			auth: AuthConfig{Method: ManagedIdentityAuthMethod},
+			validate: func(*http.Request) error {
+				return errors.New("identity not found")
+			},
+			wantErr: "managed identity endpoint returned 400",
+		},
+		{
+			name: "azure cli token",
+			auth: AuthConfig{Method: AzureCLIAuthMethod},
+			azCLI: func(_ context.Context, args ...string) ([]byte, error) {
+				if strings.Join(args, " ") != "account get-access-token --resource "+resource+" --output json --tenant test-tenant" {
+					return nil, fmt.Errorf("unexpected arguments %v", args)
+				}
+				return []byte(fmt.Sprintf(`{"accessToken": "stub-token", "tokenType": "Bearer", "expires_on": %d}`, time.Now().Add(time.Hour).Unix())), nil
+			},
+		},
+		{
+			name: "azure cli token with local expiry",
+			auth: AuthConfig{Method: AzureCLIAuthMethod},
+			azCLI: func(context.Context, ...string) ([]byte, error) {
+				return []byte(`{"accessToken": "stub-token", "tokenType": "Bearer", "expiresOn": "2999-01-01 00:00:00.000000"}`), nil
+			},
+		},
+		{
+			name: "azure cli not logged in",
+			auth: AuthConfig{Method: AzureCLIAuthMethod},
+			azCLI: func(context.Context, ...string) ([]byte, error) {
+				return nil, errors.New("exit status 1")
+			},
+			wantErr: "run az login",
+		},
+		{
+			name:    "invalid auth method",
+			auth:    AuthConfig{Method: "password"},
+			wantErr: "invalid auth method password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &AZConfig{
+				ClientID:     "test-client",
+				ClientSecret: "test-secret",
+				TenantID:     "test-tenant",
+				Auth:         tt.auth,
+			}
+			if tt.validate != nil {
+				server := stubTokenServer(t, tt.validate)
+				c.TokenURL = server.URL + "/test-tenant/oauth2/token"
+				c.Auth.IdentityEndpoint = server.URL + "/metadata/identity/oauth2/token"
+			}
+			if tt.azCLI != nil {
+				original := azCLICommand
+				azCLICommand = tt.azCLI
+				defer func() { azCLICommand = original }()
+			}
+
+			ts, err := c.tokenSource(context.Background(), resource)
+			if err == nil {
+				var token interface{ Valid() bool }
+				token, err = ts.Token()
+				if err == nil && !token.Valid() {
+					err = errors.New("invalid token")
+				}
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("tokenSource() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("tokenSource() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAZConfig_tokenURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		config AZConfig
+		want   string
+	}{
+		{
+			name:   "v1 endpoint",
+			config: AZConfig{TenantID: "tenant"},
+			want:   "https://login.microsoftonline.com/tenant/oauth2/token",
+		},
+		{
+			name:   "v2 endpoint",
+			config: AZConfig{TenantID: "tenant", Auth: AuthConfig{TokenVersion: TokenVersionV2}},
+			want:   "https://login.microsoftonline.com/tenant/oauth2/v2.0/token",
+		},
+		{
+			name:   "v2 endpoint of the china cloud",
+			config: AZConfig{TenantID: "tenant", Endpoints: clouds[ChinaCloud], Auth: AuthConfig{TokenVersion: TokenVersionV2}},
+			want:   "https://login.chinacloudapi.cn/tenant/oauth2/v2.0/token",
+		},
+		{
+			name:   "explicit token url",
+			config: AZConfig{TenantID: "tenant", TokenURL: "http://localhost/token"},
+			want:   "http://localhost/token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.tokenURL(); got != tt.want {
+				t.Errorf("tokenURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAZConfig_loginBaseURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		config AZConfig
+		want   string
+	}{
+		{
+			name:   "default login endpoint",
+			config: AZConfig{TenantID: "tenant"},
+			want:   "https://login.microsoftonline.com",
+		},
+		{
+			name:   "login endpoint of explicit token url",
+			config: AZConfig{TokenURL: "http://localhost:8080/tenant/oauth2/token"},
+			want:   "http://localhost:8080",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.loginBaseURL(); got != tt.want {
+				t.Errorf("loginBaseURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package azure
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// PublicCloud is the global Azure cloud
+	PublicCloud = "public"
+	// USGovCloud is Azure Government
+	USGovCloud = "usgov"
+	// ChinaCloud is Azure China operated by 21Vianet
+	ChinaCloud = "china"
+	// CustomCloud takes every endpoint from the configuration
+	CustomCloud = "custom"
+
+	// managementEndpoint names the Azure Resource Manager service
+	managementEndpoint = "management"
+	// ingestionEndpoint names the Partner Center product ingestion service
+	ingestionEndpoint = "ingestion"
+	// marketplaceEndpoint names the Marketplace SaaS fulfillment and metering service
+	marketplaceEndpoint = "marketplace"
+
+	// marketplaceResource is the application id of the Marketplace SaaS API, the audience of its tokens
+	marketplaceResource = "20e940b3-4c77-4b0b-9a53-9e16a1b010a7"
+)
+
+// Endpoints contains the base URLs of the Microsoft services called by unfold
+type Endpoints struct {
+	// Authority is the Microsoft Entra ID login endpoint issuing the tokens
+	Authority string `json:"authority" yaml:"authority"`
+	// Management is the Azure Resource Manager endpoint
+	Management string `json:"management" yaml:"management"`
+	// Ingestion is the Partner Center product ingestion endpoint, also the audience of its tokens
+	Ingestion string `json:"ingestion" yaml:"ingestion"`
+	// Marketplace is the Marketplace SaaS fulfillment and metering endpoint, only offered in the public cloud
+	Marketplace string `json:"marketplace" yaml:"marketplace"`
+}
+
+// clouds contains the endpoints of the known Azure clouds
+var clouds = map[string]Endpoints{
+	PublicCloud: {
+		Authority:   "https://login.microsoftonline.com",
+		Management:  "https://management.azure.com",
+		Ingestion:   "https://graph.microsoft.com",
+		Marketplace: "https://marketplaceapi.microsoft.com",
+	},
+	USGovCloud: {
+		Authority:  "https://login.microsoftonline.us",
+		Management: "https://management.usgovcloudapi.net",
+		Ingestion:  "https://graph.microsoft.us",
+	},
+	ChinaCloud: {
+		Authority:  "https://login.chinacloudapi.cn",
+		Management: "https://management.chinacloudapi.cn",
+		Ingestion:  "https://microsoftgraph.chinacloudapi.cn",
+	},
+}
+
+// loadCloudConfig selects the endpoints of the configured cloud. The endpoints of a known cloud
+// can be overridden individually, a custom cloud requires all of them.
+func (c *AZConfig) loadCloudConfig() error {
+	c.Cloud = strings.ToLower(firstNonEmpty(os.Getenv("AZURE_CLOUD"), PublicCloud))
+
+	endpoints, ok := clouds[c.Cloud]
+	if !ok && c.Cloud != CustomCloud {
+		return fmt.Errorf("invalid cloud %s, use %s|%s|%s|%s", c.Cloud, PublicCloud, USGovCloud, ChinaCloud, CustomCloud)
+	}
+
+	endpoints.Authority = firstNonEmpty(os.Getenv("AZURE_AUTHORITY_HOST"), endpoints.Authority)
+	endpoints.Management = firstNonEmpty(os.Getenv("AZURE_MANAGEMENT_ENDPOINT"), endpoints.Management)
+	endpoints.Ingestion = firstNonEmpty(os.Getenv("AZURE_INGESTION_ENDPOINT"), endpoints.Ingestion)
+	endpoints.Marketplace = firstNonEmpty(os.Getenv("AZURE_MARKETPLACE_ENDPOINT"), endpoints.Marketplace)
+
+	if endpoints.Authority == "" || endpoints.Management == "" || endpoints.Ingestion == "" {
+		return fmt.Errorf("%s cloud requires AZURE_AUTHORITY_HOST, AZURE_MANAGEMENT_ENDPOINT and AZURE_INGESTION_ENDPOINT", c.Cloud)
+	}
+
+	c.Endpoints = Endpoints{
+		Authority:   strings.TrimSuffix(endpoints.Authority, "/"),
+		Management:  strings.TrimSuffix(endpoints.Management, "/"),
+		Ingestion:   strings.TrimSuffix(endpoints.Ingestion, "/"),
+		Marketplace: strings.TrimSuffix(endpoints.Marketplace, "/"),
+	}
+	return nil
+}
+
+// endpoint returns the base URL of the named service
+func (c *AZConfig) endpoint(name string) (string, error) {
+	switch name {
+	case managementEndpoint:
+		return c.Endpoints.Management, nil
+	case ingestionEndpoint:
+		return c.Endpoints.Ingestion, nil
+	case marketplaceEndpoint:
+		if c.Endpoints.Marketplace == "" {
+			return "", fmt.Errorf("marketplace endpoint is not available in the %s cloud, set AZURE_MARKETPLACE_ENDPOINT", c.Cloud)
+		}
+		return c.Endpoints.Marketplace, nil
+	}
+	return "", fmt.Errorf("unknown azure endpoint %s", name)
+}
+
+// tokenResource returns the audience of the tokens for the named service,
+// the base URL except for the Marketplace SaaS API identified by its application id.
+func tokenResource(name, baseURL string) string {
+	if name == marketplaceEndpoint {
+		return marketplaceResource
+	}
+	return baseURL
+}
+
+// authority returns the login endpoint of the configured cloud
+func (c *AZConfig) authority() string {
+	return firstNonEmpty(c.Endpoints.Authority, clouds[PublicCloud].Authority)
+}
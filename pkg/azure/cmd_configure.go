@@ -4,7 +4,10 @@ import (
 	"flag"
 	"fmt"
 
+	"github.com/aryannr97/unfold/pkg/cache"
 	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
 )
 
 // commandConfigureConfig represents the configuration for the configure command
@@ -15,6 +18,7 @@ type commandConfigureConfig struct {
 		SubscriptionID *string
 		TenantID       *string
 		Offer          *string
+		PlanOut        *string
 	}
 }
 
@@ -33,6 +37,18 @@ func (c commandConfigureConfig) Execute() string {
 		resource = "tenant"
 		resourceID = *c.AddRemoveOpts.TenantID
 	}
+
+	if *c.AddRemoveOpts.PlanOut != "" {
+		op, err := PlanConfiguration(*c.AddRemoveOpts.Offer, resourceID, resource, mode)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		summary, err := plan.Save(*c.AddRemoveOpts.PlanOut, *op)
+		if err != nil {
+			return fmt.Sprintf("[unfold] unable to write the plan %s", helpers.RedValue(err.Error()))
+		}
+		return fmt.Sprintf("[unfold] %s", summary)
+	}
 	return fmt.Sprintf("[unfold] %v", MakeConfigurationRequest(*c.AddRemoveOpts.Offer, resourceID, resource, mode))
 }
 
@@ -49,17 +65,21 @@ func (c *commandConfigureConfig) IsSub() bool {
 // fetchCommandConfigureConfig fetches the command configure config
 func fetchCommandConfigureConfig() commandConfigureConfig {
 	flagSet := flag.NewFlagSet(commands.Configure, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindRetryFlags(flagSet)
 	return commandConfigureConfig{
 		AddRemoveOpts: struct {
 			RemoveFlag     *bool
 			SubscriptionID *string
 			TenantID       *string
 			Offer          *string
+			PlanOut        *string
 		}{
 			RemoveFlag:     flagSet.Bool("r", false, "remove resource from respective private audience"),
 			SubscriptionID: flagSet.String("sid", "", "provide a valid azure subscription id"),
 			TenantID:       flagSet.String("tid", "", "provide a valid azure tenant id"),
 			Offer:          flagSet.String("o", "", "provide a valid azure offer name"),
+			PlanOut:        flagSet.String("plan-out", "", "write the resolved operation to the plan file instead of executing it"),
 		},
 		FlagSet: flagSet,
 	}
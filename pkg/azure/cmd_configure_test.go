@@ -3,12 +3,32 @@ package azure
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/aryannr97/unfold/pkg/plan"
 )
 
+// recordingRoundTripper records the bodies of the requests before answering them with the mock transport
+type recordingRoundTripper struct {
+	MockHTTPRoundTripper
+	bodies []string
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		r.bodies = append(r.bodies, string(b))
+	}
+	return r.MockHTTPRoundTripper.RoundTrip(req)
+}
+
 func Test_commandConfigureConfig_Execute(t *testing.T) {
 	prepareTestEnvironment()
 	tests := []struct {
@@ -147,6 +167,25 @@ func Test_commandConfigureConfig_Execute(t *testing.T) {
 			httpCallError: nil,
 			want:          "json decode",
 		},
+		{
+			name: "add subscription with unreadable resource tree",
+			args: []string{"-sid", "12345678-1234-1234-1234-123456789abc", "-o", "offer-2"},
+			transport: map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/plan?product=product/87654321-4321-4321-4321-210987654321&$version=2022-03-01-preview2": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"value": [{"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#", "id": "12345678-1234-1234-1234-12345678plan-1"}]}`)),
+				},
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321": {
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+				},
+				"https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"jobId": "12345678-1234-1234-1234-123456789def", "jobStatus": "pending", "jobResult": "pending", "errors": []}`)),
+				},
+			},
+			want: "configure response",
+		},
 		{
 			name: "add subscription configure http call error",
 			args: []string{"-sid", "12345678-1234-1234-1234-123456789abc", "-o", "offer-2"},
@@ -156,7 +195,7 @@ func Test_commandConfigureConfig_Execute(t *testing.T) {
 					Body:       io.NopCloser(bytes.NewBufferString(`{"value": [{"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#", "id": "12345678-1234-1234-1234-12345678plan-1"}]}`)),
 				},
 			},
-			errorOnIndex:  1,
+			errorOnIndex:  2,
 			httpCallError: errors.New("http call error"),
 			want:          "http call error",
 		},
@@ -165,9 +204,17 @@ func Test_commandConfigureConfig_Execute(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewCommandModule().CommandConfigureConfig
 			c.GetFlagSet().Parse(tt.args)
-			instances[graphResourceIndex].httpClient = &http.Client{
+			// the private audiences are read before the change is submitted
+			transport := map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resources": []}`)),
+				},
+			}
+			maps.Copy(transport, tt.transport)
+			instances[ingestionEndpoint].httpClient = &http.Client{
 				Transport: &MockHTTPRoundTripper{
-					Transport:    tt.transport,
+					Transport:    transport,
 					Error:        tt.httpCallError,
 					ErrorOnIndex: tt.errorOnIndex,
 				},
@@ -178,3 +225,254 @@ func Test_commandConfigureConfig_Execute(t *testing.T) {
 		})
 	}
 }
+
+func Test_commandConfigureConfig_PlanOut(t *testing.T) {
+	prepareTestEnvironment()
+	t.Setenv("UNFOLD_NO_CACHE", "true")
+	plansURL := "https://graph.microsoft.com/rp/product-ingestion/plan?product=product/87654321-4321-4321-4321-210987654321&$version=2022-03-01-preview2"
+	configureURL := "https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2"
+	plansResponse := func(ids ...string) *http.Response {
+		values := []string{}
+		for _, id := range ids {
+			values = append(values, fmt.Sprintf(`{"id": "%s"}`, id))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"value": [` + strings.Join(values, ",") + `]}`))}
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	c := NewCommandModule().CommandConfigureConfig
+	c.GetFlagSet().Parse([]string{"-tid", "12345678-1234-1234-1234-123456789abc", "-o", "offer-2", "-r", "--plan-out", path})
+	// the configure endpoint is not mocked, the plan must not be executed
+	instances[ingestionEndpoint].httpClient = &http.Client{Transport: &MockHTTPRoundTripper{Transport: map[string]*http.Response{
+		plansURL: plansResponse("plan/87654321-4321-4321-4321-210987654321/plan-a", "plan/87654321-4321-4321-4321-210987654321/plan-b"),
+	}}}
+	if got := c.Execute(); !strings.Contains(got, "plan with 1 operation(s) written to "+path) {
+		t.Fatalf("commandConfigureConfig.Execute() = %v, want the plan summary", got)
+	}
+
+	p, err := plan.Read(path)
+	if err != nil {
+		t.Fatalf("plan.Read() error = %v", err)
+	}
+	want := plan.Operation{
+		Kind:             plan.AzurePrivateAudience,
+		Mode:             plan.RemoveMode,
+		Offer:            "offer-2",
+		ProductDurableID: "87654321-4321-4321-4321-210987654321",
+		PlanIDs:          []string{"plan/87654321-4321-4321-4321-210987654321/plan-a", "plan/87654321-4321-4321-4321-210987654321/plan-b"},
+		AudienceType:     "tenant",
+		AudienceID:       "12345678-1234-1234-1234-123456789abc",
+	}
+	if !reflect.DeepEqual(p.Operations, []plan.Operation{want}) {
+		t.Fatalf("plan = %+v, want %+v", p, want)
+	}
+
+	tests := []struct {
+		name      string
+		transport map[string]*http.Response
+		wantErr   string
+	}{
+		{
+			name: "plans are unchanged",
+			transport: map[string]*http.Response{
+				plansURL: plansResponse("plan/87654321-4321-4321-4321-210987654321/plan-b", "plan/87654321-4321-4321-4321-210987654321/plan-a"),
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resources": []}`)),
+				},
+				configureURL: {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"jobId": "job-plan", "jobStatus": "running", "jobResult": "pending"}`)),
+				},
+			},
+		},
+		{
+			name:      "a plan was added",
+			transport: map[string]*http.Response{plansURL: plansResponse("plan/87654321-4321-4321-4321-210987654321/plan-a")},
+			wantErr:   "the plans of offer offer-2 changed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UNFOLD_STATE_DIR", t.TempDir())
+			transport := &recordingRoundTripper{MockHTTPRoundTripper: MockHTTPRoundTripper{Transport: tt.transport}}
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: transport}
+
+			err := ValidatePlannedConfiguration(p.Operations[0])
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ValidatePlannedConfiguration() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidatePlannedConfiguration() error = %v", err)
+			}
+
+			res, err := ApplyPlannedConfiguration(p.Operations[0])
+			if err != nil || res.AzureJobID != "job-plan" || res.TenantID != want.AudienceID {
+				t.Fatalf("ApplyPlannedConfiguration() = %+v, %v", res, err)
+			}
+			body := transport.bodies[len(transport.bodies)-1]
+			for _, planID := range want.PlanIDs {
+				if !strings.Contains(body, `"plan":"`+planID+`"`) {
+					t.Errorf("configure request %s does not target %s", body, planID)
+				}
+			}
+			if !strings.Contains(body, `"remove":[{"type":"tenant","id":"12345678-1234-1234-1234-123456789abc"}]`) {
+				t.Errorf("configure request %s does not remove the tenant", body)
+			}
+		})
+	}
+}
+
+func TestPlanUndo(t *testing.T) {
+	prepareTestEnvironment()
+	t.Setenv("UNFOLD_NO_CACHE", "true")
+	plansURL := "https://graph.microsoft.com/rp/product-ingestion/plan?product=product/87654321-4321-4321-4321-210987654321&$version=2022-03-01-preview2"
+	treeURL := "https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321"
+	planA, planB := "plan/87654321-4321-4321-4321-210987654321/plan-a", "plan/87654321-4321-4321-4321-210987654321/plan-b"
+	tenant := "12345678-1234-1234-1234-123456789abc"
+	tree := func(plans ...string) *http.Response {
+		resources := []string{}
+		for _, p := range plans {
+			resources = append(resources, fmt.Sprintf(`{"plan": "%s", "privateAudiences": [{"id": "%s", "type": "tenant"}]}`, p, tenant))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"resources": [` + strings.Join(resources, ",") + `]}`))}
+	}
+	plans := func() *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(fmt.Sprintf(`{"value": [{"id": "%s"}, {"id": "%s"}]}`, planA, planB)))}
+	}
+	op := func(mode string) plan.Operation {
+		return plan.Operation{Kind: plan.AzurePrivateAudience, Mode: mode, Offer: "offer-2", ProductDurableID: "87654321-4321-4321-4321-210987654321",
+			PlanIDs: []string{planA, planB}, AudienceType: "tenant", AudienceID: tenant}
+	}
+
+	tests := []struct {
+		name      string
+		op        plan.Operation
+		unchanged []string
+		tree      *http.Response
+		wantMode  string
+		wantPlans []string
+		wantErr   string
+	}{
+		{
+			name:     "undo an add",
+			op:       op(plan.AddMode),
+			tree:     tree(planA, planB),
+			wantMode: plan.RemoveMode,
+		},
+		{
+			name:     "undo a remove",
+			op:       op(plan.RemoveMode),
+			tree:     tree(),
+			wantMode: plan.AddMode,
+		},
+		{
+			name:      "undo an add on the plans it changed",
+			op:        op(plan.AddMode),
+			unchanged: []string{planA},
+			tree:      tree(planA, planB),
+			wantMode:  plan.RemoveMode,
+			wantPlans: []string{planB},
+		},
+		{
+			name:      "undo an add that changed nothing",
+			op:        op(plan.AddMode),
+			unchanged: []string{planA, planB},
+			tree:      tree(planA, planB),
+			wantErr:   "the operation did not change the private audience of any plan of offer-2, nothing to undo",
+		},
+		{
+			name:      "undo a remove that changed nothing",
+			op:        op(plan.RemoveMode),
+			unchanged: []string{planA, planB},
+			tree:      tree(),
+			wantErr:   "the operation did not change the private audience of any plan of offer-2, nothing to undo",
+		},
+		{
+			name:      "audience added on a plan the remove did not change",
+			op:        op(plan.RemoveMode),
+			unchanged: []string{planB},
+			tree:      tree(planB),
+			wantMode:  plan.AddMode,
+			wantPlans: []string{planA},
+		},
+		{
+			name:    "audience removed since the add",
+			op:      op(plan.AddMode),
+			tree:    tree(planA),
+			wantErr: tenant + " is no longer in the private audience of " + planB,
+		},
+		{
+			name:    "audience added since the remove",
+			op:      op(plan.RemoveMode),
+			tree:    tree(planB),
+			wantErr: tenant + " is in the private audience of " + planB + " again",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: &MockHTTPRoundTripper{Transport: map[string]*http.Response{
+				plansURL: plans(),
+				treeURL:  tt.tree,
+			}}}
+			tt.op.Unchanged = tt.unchanged
+			got, err := PlanUndo(tt.op)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("PlanUndo() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanUndo() error = %v", err)
+			}
+			want := tt.op
+			want.Mode, want.Unchanged = tt.wantMode, nil
+			if tt.wantPlans != nil {
+				want.PlanIDs = tt.wantPlans
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("PlanUndo() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func Test_recordUnchangedPlans(t *testing.T) {
+	prepareTestEnvironment()
+	treeURL := "https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321"
+	planA, planB := "plan/87654321-4321-4321-4321-210987654321/plan-a", "plan/87654321-4321-4321-4321-210987654321/plan-b"
+	tenant := "12345678-1234-1234-1234-123456789abc"
+	tree := fmt.Sprintf(`{"resources": [{"plan": "%s", "privateAudiences": [{"id": "%s", "type": "tenant"}]}, {"plan": "%s", "privateAudiences": []}]}`, planA, tenant, planB)
+
+	tests := []struct {
+		name    string
+		mode    string
+		treeErr bool
+		want    []string
+	}{
+		{name: "add skips the plans having the audience", mode: plan.AddMode, want: []string{planA}},
+		{name: "remove skips the plans without the audience", mode: plan.RemoveMode, want: []string{planB}},
+		{name: "unreadable tree leaves the plans unknown", mode: plan.AddMode, treeErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := map[string]*http.Response{
+				treeURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+			}
+			if tt.treeErr {
+				transport = nil
+			}
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: &MockHTTPRoundTripper{Transport: transport}}
+			op := plan.Operation{Kind: plan.AzurePrivateAudience, Mode: tt.mode, ProductDurableID: "87654321-4321-4321-4321-210987654321",
+				PlanIDs: []string{planA, planB}, AudienceType: "tenant", AudienceID: strings.ToUpper(tenant), Unchanged: []string{"stale"}}
+			recordUnchangedPlans(&op)
+			if !reflect.DeepEqual(op.Unchanged, tt.want) {
+				t.Errorf("recordUnchangedPlans() = %v, want %v", op.Unchanged, tt.want)
+			}
+		})
+	}
+}
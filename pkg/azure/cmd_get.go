@@ -3,35 +3,135 @@ package azure
 import (
 	"flag"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
 )
 
+// stdin is the reader used when subscriptions are read from the standard input
+var stdin io.Reader = os.Stdin
+
 // commandGetConfig represents the configuration for the get command
 type commandGetConfig struct {
 	FlagSet *flag.FlagSet
 	Opts    struct {
-		TenantFlag *string
-		StatusFlag *string
+		TenantFlag  *bool
+		StatusFlag  *string
+		DomainFlag  *string
+		DetailsFlag *bool
+		RawFlag     *bool
+	}
+	BulkOpts struct {
+		File    *string
+		Workers *int
+		Format  *string
+		Out     *string
 	}
 }
 
 // Execute executes the get command
 func (c commandGetConfig) Execute() string {
-	if *c.Opts.TenantFlag != "" {
+	// -t is a mode flag, the subscription follows it as positional argument so that -t -f subs.txt resolves the file
+	subscription, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.BulkOpts.File != "" {
+		if subscription != "" {
+			return "[unfold] provide either a subscription id or a file with -f"
+		}
+		return c.resolveTenants()
+	} else if *c.Opts.TenantFlag {
+		if subscription == "" {
+			return "[unfold] provide a subscription id after -t, or a file with -f"
+		}
+		atf := NewTenantFinder()
+		tenants, err := atf.GetTenantBySubscriptionID(subscription)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		if !*c.Opts.DetailsFlag {
+			return fmt.Sprintf("[unfold] retrieved tenant(s): %v", strings.Join(tenants, ","))
+		}
+		return tenantDetails(atf, tenants)
+	} else if *c.Opts.DomainFlag != "" {
 		atf := NewTenantFinder()
-		tenants, err := atf.GetTenantBySubscriptionID(*c.Opts.TenantFlag)
+		metadata, err := atf.GetTenantMetadata(*c.Opts.DomainFlag)
 		if err != nil {
 			return fmt.Sprintf("[unfold] %s", err.Error())
 		}
-		return fmt.Sprintf("[unfold] retrieved tenant(s): %v", strings.Join(tenants, ","))
+		if !*c.Opts.DetailsFlag {
+			return fmt.Sprintf("[unfold] retrieved tenant: %v", metadata.TenantID)
+		}
+		return tenantDetails(atf, []string{metadata.TenantID})
 	} else if *c.Opts.StatusFlag != "" {
-		return fmt.Sprintf("[unfold] %s", GetAzureJobStatus(*c.Opts.StatusFlag))
+		return fmt.Sprintf("[unfold] %s", GetAzureJobStatus(*c.Opts.StatusFlag, *c.Opts.RawFlag))
 	}
 	return "[unfold] something went wrong"
 }
 
+// tenantDetails returns the tenants along with the metadata of their openid configuration
+func tenantDetails(atf *TenantFinder, tenants []string) string {
+	sb := &strings.Builder{}
+	sb.WriteString(fmt.Sprintf("[unfold] retrieved tenant(s): %v", strings.Join(tenants, ",")))
+	for _, tenant := range tenants {
+		metadata, err := atf.GetTenantMetadata(tenant)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("\n\ntenant: %s\nerror: %s", tenant, helpers.RedValue(err.Error())))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n\ntenant: %s\nregion scope: %s\ncloud instance: %s\nissuer: %s",
+			helpers.GreenValue(tenant), metadata.TenantRegionScope, metadata.CloudInstanceName, metadata.Issuer))
+	}
+	return sb.String()
+}
+
+// resolveTenants resolves the tenants of the subscriptions listed in the file, or stdin for -,
+// and writes the results to the output file or returns them.
+func (c commandGetConfig) resolveTenants() string {
+	in := stdin
+	if *c.BulkOpts.File != "-" {
+		f, err := os.Open(*c.BulkOpts.File)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		defer f.Close()
+		in = f
+	}
+
+	ids, err := readSubscriptionIDs(in)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to read subscriptions %s", err.Error())
+	}
+
+	results := NewTenantFinder().ResolveTenants(ids, *c.BulkOpts.Workers)
+
+	failed := 0
+	for _, res := range results {
+		if res.Error != "" {
+			failed++
+		}
+	}
+	summary := fmt.Sprintf("[unfold] resolved %s subscription(s), %s failed", helpers.GreenValue(fmt.Sprint(len(results)-failed)), helpers.RedValue(fmt.Sprint(failed)))
+
+	sb := &strings.Builder{}
+	if err := writeTenantResolutions(sb, results, *c.BulkOpts.Format); err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.BulkOpts.Out == "" {
+		return fmt.Sprintf("%s\n%s", summary, strings.TrimSuffix(sb.String(), "\n"))
+	}
+	if err := os.WriteFile(*c.BulkOpts.Out, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Sprintf("[unfold] unable to write results %s", err.Error())
+	}
+	return fmt.Sprintf("%s, results written to %s", summary, *c.BulkOpts.Out)
+}
+
 // GetFlagSet returns the flag set for the get command
 func (c commandGetConfig) GetFlagSet() *flag.FlagSet {
 	return c.FlagSet
@@ -40,13 +140,31 @@ func (c commandGetConfig) GetFlagSet() *flag.FlagSet {
 // fetchCommandGetConfig fetches the command get config
 func fetchCommandGetConfig() commandGetConfig {
 	flagSet := flag.NewFlagSet(commands.Get, flag.ContinueOnError)
+	bindRetryFlags(flagSet)
 	return commandGetConfig{
 		Opts: struct {
-			TenantFlag *string
-			StatusFlag *string
+			TenantFlag  *bool
+			StatusFlag  *string
+			DomainFlag  *string
+			DetailsFlag *bool
+			RawFlag     *bool
+		}{
+			TenantFlag:  flagSet.Bool("t", false, "used to fetch tenant for the subscription given as argument, or listed in the file given with -f"),
+			StatusFlag:  flagSet.String("s", "", "used to fetch status for given job id"),
+			DomainFlag:  flagSet.String("d", "", "used to fetch tenant for given domain name"),
+			DetailsFlag: flagSet.Bool("details", false, "show region scope, cloud instance and issuer of the tenant(s)"),
+			RawFlag:     flagSet.Bool("raw", false, "show the original payload of the job status"),
+		},
+		BulkOpts: struct {
+			File    *string
+			Workers *int
+			Format  *string
+			Out     *string
 		}{
-			TenantFlag: flagSet.String("t", "", "used to fetch tenant for given subscription"),
-			StatusFlag: flagSet.String("s", "", "used to fetch status for given job id"),
+			File:    flagSet.String("f", "", "used to fetch tenants for the subscriptions listed in the file, - for stdin"),
+			Workers: flagSet.Int("workers", 8, "number of subscriptions resolved concurrently"),
+			Format:  flagSet.String("format", CSVFormat, "output format of bulk results csv|json"),
+			Out:     flagSet.String("out", "", "file to write the bulk results to, defaults to the command output"),
 		},
 		FlagSet: flagSet,
 	}
@@ -6,7 +6,10 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
 )
 
 func Test_commandGetConfig_Execute(t *testing.T) {
@@ -16,10 +19,60 @@ func Test_commandGetConfig_Execute(t *testing.T) {
 		name          string
 		args          []string
 		transport     map[string]*http.Response
+		anonTransport map[string]*http.Response
 		httpCallError error
 		resource      string
 		want          string
 	}{
+		{
+			name: "get tenant by subscription id from authentication challenge",
+			args: []string{"-t", "12345678-1234-1234-1234-123456789abc"},
+			anonTransport: map[string]*http.Response{
+				"https://management.azure.com/subscriptions/12345678-1234-1234-1234-123456789abc?api-version=2022-12-01": {
+					StatusCode: http.StatusUnauthorized,
+					Header: http.Header{
+						"Www-Authenticate": []string{`Bearer authorization_uri="https://login.windows.net/0b37927b-359e-4a60-8aac-67f88409ac5a", error="invalid_token", error_description="The authentication failed because of missing 'Authorization' header."`},
+					},
+					Body: io.NopCloser(bytes.NewBufferString(`{"error":{"code":"AuthenticationFailed","message":"Authentication failed. The 'Authorization' header is missing."}}`)),
+				},
+			},
+			httpCallError: nil,
+			resource:      "management",
+			want:          "retrieved tenant(s): 0b37927b-359e-4a60-8aac-67f88409ac5a",
+		},
+		{
+			name: "get tenant by unknown subscription id",
+			args: []string{"-t", "12345678-1234-1234-1234-123456789abc"},
+			anonTransport: map[string]*http.Response{
+				"https://management.azure.com/subscriptions/12345678-1234-1234-1234-123456789abc?api-version=2022-12-01": {
+					StatusCode: http.StatusNotFound,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"code":"SubscriptionNotFound","message":"The subscription '12345678-1234-1234-1234-123456789abc' could not be found."}}`)),
+				},
+			},
+			httpCallError: nil,
+			resource:      "management",
+			want:          "subscription not found 12345678-1234-1234-1234-123456789abc (SubscriptionNotFound)",
+		},
+		{
+			name: "get tenant by disabled subscription id",
+			args: []string{"-t", "12345678-1234-1234-1234-123456789abc"},
+			transport: map[string]*http.Response{
+				"https://management.azure.com/subscriptions/12345678-1234-1234-1234-123456789abc?api-version=2022-12-01": {
+					StatusCode: http.StatusConflict,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"error":{"code":"ReadOnlyDisabledSubscription","message":"The subscription '12345678-1234-1234-1234-123456789abc' is disabled and therefore marked as read only."}}`)),
+				},
+			},
+			httpCallError: nil,
+			resource:      "management",
+			want:          "subscription disabled 12345678-1234-1234-1234-123456789abc (ReadOnlyDisabledSubscription)",
+		},
+		{
+			name:          "get tenant http call error",
+			args:          []string{"-t", "12345678-1234-1234-1234-123456789abc"},
+			httpCallError: errors.New("http call error"),
+			resource:      "management",
+			want:          "http call error",
+		},
 		{
 			name: "get single tenant by subscription id",
 			args: []string{"-t", "12345678-1234-1234-1234-123456789abc"},
@@ -83,7 +136,39 @@ func Test_commandGetConfig_Execute(t *testing.T) {
 			},
 			httpCallError: nil,
 			resource:      "graph",
-			want:          "job status response",
+			want:          "job status report\njob: 12345678-1234-1234-1234-123456789abc\nstatus: completed\nresult: " + helpers.GreenValue("success"),
+		},
+		{
+			name: "get job status report with timings and error breakdown",
+			args: []string{"-s", "12345678-1234-1234-1234-123456789abc"},
+			transport: map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/configure/12345678-1234-1234-1234-123456789abc/status?$version=2022-07-01": {
+					StatusCode: http.StatusOK,
+					Body: io.NopCloser(bytes.NewBufferString(`{"jobResult":"failed","jobId":"12345678-1234-1234-1234-123456789abc","jobStatus":"completed","jobStart":"2026-01-01T10:00:00Z","jobEnd":"2026-01-01T10:01:30Z",
+						"errors":[{"code":"businessValidationError","message":"Invalid private audience 0b37927b-359e-4a60-8aac-67f88409ac5a","resourceId":"price-and-availability-plan/87654321-4321-4321-4321-210987654321/plan/87654321-4321-4321-4321-210987654321/aaaa",
+						"details":[{"code":"invalidAudience","message":"Tenant 0b37927b-359e-4a60-8aac-67f88409ac5a does not exist","target":"privateAudiences"}]}]}`)),
+				},
+			},
+			httpCallError: nil,
+			resource:      "graph",
+			want: "result: " + helpers.RedValue("failed") + "\nstarted: 2026-01-01T10:00:00Z\nended: 2026-01-01T10:01:30Z\nduration: 1m30s\nerrors (1):\n" +
+				"1. [businessValidationError] " + helpers.RedValue("Invalid private audience 0b37927b-359e-4a60-8aac-67f88409ac5a") +
+				"\n   resource: price-and-availability-plan/87654321-4321-4321-4321-210987654321/plan/87654321-4321-4321-4321-210987654321/aaaa" +
+				"\n   plan: aaaa\n   audience: 0b37927b-359e-4a60-8aac-67f88409ac5a" +
+				"\n   detail: code=invalidAudience, message=Tenant 0b37927b-359e-4a60-8aac-67f88409ac5a does not exist, target=privateAudiences",
+		},
+		{
+			name: "get raw job status",
+			args: []string{"-s", "12345678-1234-1234-1234-123456789abc", "-raw"},
+			transport: map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/configure/12345678-1234-1234-1234-123456789abc/status?$version=2022-07-01": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"jobResult":"failed","jobId":"12345678-1234-1234-1234-123456789abc","jobStatus":"completed","errors":[{"code":"x","message":"y","details":[{"a":1}]}]}`)),
+				},
+			},
+			httpCallError: nil,
+			resource:      "graph",
+			want:          `job status response ` + "\n" + `{"jobResult":"failed","jobId":"12345678-1234-1234-1234-123456789abc","jobStatus":"completed","errors":[{"code":"x","message":"y","details":[{"a":1}]}]}`,
 		},
 		{
 			name: "get job status failed",
@@ -137,6 +222,65 @@ func Test_commandGetConfig_Execute(t *testing.T) {
 			resource:      "graph",
 			want:          "http call error",
 		},
+		{
+			name: "get tenant by domain",
+			args: []string{"-d", "contoso.com"},
+			anonTransport: map[string]*http.Response{
+				"https://login.microsoftonline.com/contoso.com/v2.0/.well-known/openid-configuration": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"issuer":"https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0","tenant_region_scope":"EU","cloud_instance_name":"microsoftonline.com"}`)),
+				},
+			},
+			resource: "management",
+			want:     "retrieved tenant: 0b37927b-359e-4a60-8aac-67f88409ac5a",
+		},
+		{
+			name: "get tenant by domain with details",
+			args: []string{"-d", "contoso.com", "-details"},
+			anonTransport: map[string]*http.Response{
+				"https://login.microsoftonline.com/contoso.com/v2.0/.well-known/openid-configuration": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"issuer":"https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0","tenant_region_scope":"EU","cloud_instance_name":"microsoftonline.com"}`)),
+				},
+				"https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0/.well-known/openid-configuration": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"issuer":"https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0","tenant_region_scope":"EU","cloud_instance_name":"microsoftonline.com"}`)),
+				},
+			},
+			resource: "management",
+			want:     "region scope: EU\ncloud instance: microsoftonline.com\nissuer: https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0",
+		},
+		{
+			name: "get tenant by unknown domain",
+			args: []string{"-d", "unknown.example"},
+			anonTransport: map[string]*http.Response{
+				"https://login.microsoftonline.com/unknown.example/v2.0/.well-known/openid-configuration": {
+					StatusCode: http.StatusBadRequest,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"error":"invalid_tenant","error_description":"AADSTS90002: Tenant 'unknown.example' not found."}`)),
+				},
+			},
+			resource: "management",
+			want:     "tenant not found unknown.example",
+		},
+		{
+			name: "get tenant by subscription id with details",
+			args: []string{"-t", "12345678-1234-1234-1234-123456789abc", "-details"},
+			anonTransport: map[string]*http.Response{
+				"https://management.azure.com/subscriptions/12345678-1234-1234-1234-123456789abc?api-version=2022-12-01": {
+					StatusCode: http.StatusUnauthorized,
+					Header: http.Header{
+						"Www-Authenticate": []string{`Bearer authorization_uri="https://login.windows.net/0b37927b-359e-4a60-8aac-67f88409ac5a"`},
+					},
+					Body: io.NopCloser(bytes.NewBufferString(`{}`)),
+				},
+				"https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0/.well-known/openid-configuration": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"issuer":"https://login.microsoftonline.com/0b37927b-359e-4a60-8aac-67f88409ac5a/v2.0","tenant_region_scope":"EU","cloud_instance_name":"microsoftonline.com"}`)),
+				},
+			},
+			resource: "management",
+			want:     "region scope: EU",
+		},
 		{
 			name:          "required flags empty",
 			args:          []string{},
@@ -151,14 +295,20 @@ func Test_commandGetConfig_Execute(t *testing.T) {
 			c.GetFlagSet().Parse(tt.args)
 			switch tt.resource {
 			case "management":
-				instances[managementResourceIndex].httpClient = &http.Client{
+				instances[managementEndpoint].httpClient = &http.Client{
 					Transport: &MockHTTPRoundTripper{
 						Transport: tt.transport,
 						Error:     tt.httpCallError,
 					},
 				}
+				instances[managementEndpoint].anonClient = &http.Client{
+					Transport: &MockHTTPRoundTripper{
+						Transport: tt.anonTransport,
+						Error:     tt.httpCallError,
+					},
+				}
 			case "graph":
-				instances[graphResourceIndex].httpClient = &http.Client{
+				instances[ingestionEndpoint].httpClient = &http.Client{
 					Transport: &MockHTTPRoundTripper{
 						Transport: tt.transport,
 						Error:     tt.httpCallError,
@@ -177,9 +327,14 @@ type MockHTTPRoundTripper struct {
 	Error        error
 	ErrorOnIndex int
 	CurrentIndex int
+	mu           sync.Mutex
 }
 
 func (m *MockHTTPRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// requests of concurrent searches share the round tripper
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.Error != nil && m.ErrorOnIndex == m.CurrentIndex {
 		return nil, m.Error
 	}
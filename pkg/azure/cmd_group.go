@@ -0,0 +1,94 @@
+package azure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/directory"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// membershipCommand is a directory command run as action of the group command
+type membershipCommand interface {
+	Execute() string
+	GetFlagSet() *flag.FlagSet
+}
+
+// commandGroupConfig represents the configuration for the Entra group command,
+// the membership actions are the directory commands of the Entra group provider
+type commandGroupConfig struct {
+	FlagSet   *flag.FlagSet
+	GroupOpts struct {
+		Group *string
+	}
+	Search    directory.CommandSearchConfig
+	Configure directory.CommandConfigureConfig
+	Export    directory.CommandExportConfig
+}
+
+// Execute executes the group command
+func (c commandGroupConfig) Execute() string {
+	var membership membershipCommand
+	switch c.FlagSet.Arg(0) {
+	case commands.Get:
+		return c.get()
+	case commands.Search:
+		membership = c.Search
+	case commands.Configure:
+		membership = c.Configure
+	case commands.Export:
+		membership = c.Export
+	default:
+		return fmt.Sprintf("[unfold] provide a valid action %s|%s|%s|%s", commands.Get, commands.Search, commands.Configure, commands.Export)
+	}
+
+	if err := membership.GetFlagSet().Parse(c.FlagSet.Args()[1:]); err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	return membership.Execute()
+}
+
+// get returns the details of the group
+func (c commandGroupConfig) get() string {
+	if _, err := helpers.ParseAction(c.FlagSet); err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.GroupOpts.Group == "" {
+		return "[unfold] group cannot be empty"
+	}
+
+	g, err := GetEntraGroup(*c.GroupOpts.Group)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	b, _ := json.MarshalIndent(g, "", " ")
+	return fmt.Sprintf("[unfold] group details \n%s", string(b))
+}
+
+// GetFlagSet returns the flag set for the group command
+func (c commandGroupConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandGroupConfig fetches the command group config
+func fetchCommandGroupConfig() commandGroupConfig {
+	flagSet := flag.NewFlagSet(commands.Group, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindRetryFlags(flagSet)
+	provider := NewEntraGroupProvider()
+	return commandGroupConfig{
+		GroupOpts: struct {
+			Group *string
+		}{
+			Group: flagSet.String("g", "", "provide an entra group object id, mail or display name"),
+		},
+		Search:    directory.NewCommandSearchConfig(provider, cache.BindFlags, bindRetryFlags),
+		Configure: directory.NewCommandConfigureConfig(provider, cache.BindFlags, bindRetryFlags),
+		Export:    directory.NewCommandExportConfig(provider, cache.BindFlags, bindRetryFlags),
+		FlagSet:   flagSet,
+	}
+}
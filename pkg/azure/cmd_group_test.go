@@ -0,0 +1,232 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	stubGroupID  = "11111111-1111-1111-1111-111111111111"
+	stubUserID   = "22222222-2222-2222-2222-222222222222"
+	nestedUserID = "33333333-3333-3333-3333-333333333333"
+)
+
+// stubGraphServer serves the Graph directory endpoints of a group with one direct and one nested member
+func stubGraphServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, graphVersion)
+		filter := r.URL.Query().Get("$filter")
+		switch {
+		case r.Method == http.MethodGet && path == "/groups":
+			switch filter {
+			case "displayName eq 'Customer Admins'", "mail eq 'admins@contoso.com'":
+				fmt.Fprintf(w, `{"value": [{"id": "%s", "displayName": "Customer Admins"}]}`, stubGroupID)
+			case "displayName eq 'Duplicates'":
+				fmt.Fprint(w, `{"value": [{"id": "a"}, {"id": "b"}]}`)
+			case "displayName eq 'O''Brien'":
+				fmt.Fprint(w, `{"value": []}`)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		case r.Method == http.MethodGet && path == "/groups/"+stubGroupID:
+			fmt.Fprintf(w, `{"id": "%s", "displayName": "Customer Admins", "mail": "admins@contoso.com", "securityEnabled": true, "groupTypes": []}`, stubGroupID)
+		case r.Method == http.MethodGet && path == "/users/alice@contoso.com":
+			fmt.Fprintf(w, `{"id": "%s"}`, stubUserID)
+		case r.Method == http.MethodGet && path == "/users/bob@contoso.com":
+			fmt.Fprintf(w, `{"id": "%s"}`, nestedUserID)
+		case r.Method == http.MethodGet && path == "/groups/"+stubGroupID+"/members":
+			if r.Header.Get("ConsistencyLevel") != "eventual" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if filter == fmt.Sprintf("id eq '%s'", stubUserID) {
+				fmt.Fprintf(w, `{"value": [{"id": "%s"}]}`, stubUserID)
+				return
+			}
+			fmt.Fprint(w, `{"value": []}`)
+		case r.Method == http.MethodGet && path == "/groups/"+stubGroupID+"/members/microsoft.graph.user":
+			fmt.Fprintf(w, `{"value": [{"id": "%s", "userPrincipalName": "alice@contoso.com"}]}`, stubUserID)
+		case r.Method == http.MethodGet && path == "/groups/"+stubGroupID+"/transitiveMembers/microsoft.graph.user":
+			if r.URL.Query().Get("$skiptoken") == "" {
+				fmt.Fprintf(w, `{"value": [{"id": "%s", "userPrincipalName": "alice@contoso.com"}], "@odata.nextLink": "http://%s%s/groups/%s/transitiveMembers/microsoft.graph.user?$skiptoken=2"}`, stubUserID, r.Host, graphVersion, stubGroupID)
+				return
+			}
+			fmt.Fprintf(w, `{"value": [{"id": "%s", "userPrincipalName": "bob@contoso.com"}]}`, nestedUserID)
+		case r.Method == http.MethodPost && strings.HasSuffix(path, "/checkMemberGroups"):
+			if strings.Contains(path, nestedUserID) {
+				fmt.Fprintf(w, `{"value": ["%s"]}`, stubGroupID)
+				return
+			}
+			fmt.Fprint(w, `{"value": []}`)
+		case r.Method == http.MethodPost && path == "/groups/"+stubGroupID+"/members/$ref":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body) //nolint:errcheck
+			if strings.HasSuffix(body["@odata.id"], "/v1.0/directoryObjects/"+stubUserID) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error": {"code": "Request_BadRequest", "message": "One or more added object references already exist"}}`)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && path == "/groups/"+stubGroupID+"/members/"+stubUserID+"/$ref":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "Request_ResourceNotFound"}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func useStubGraphServer(t *testing.T, server *httptest.Server) {
+	instance := instances[ingestionEndpoint]
+	baseURL, httpClient := instance.BaseURL, instance.httpClient
+	t.Cleanup(func() {
+		instance.BaseURL, instance.httpClient = baseURL, httpClient
+	})
+	instance.BaseURL, instance.httpClient = server.URL, server.Client()
+}
+
+func Test_commandGroupConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	useStubGraphServer(t, stubGraphServer(t))
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "get group by display name",
+			args: []string{"get", "-g", "Customer Admins"},
+			want: "group details \n{\n \"id\": \"" + stubGroupID + "\",\n \"displayName\": \"Customer Admins\"",
+		},
+		{
+			name: "get group by mail",
+			args: []string{"get", "-g", "admins@contoso.com"},
+			want: "\"mail\": \"admins@contoso.com\"",
+		},
+		{
+			name: "get group by object id",
+			args: []string{"get", "-g", stubGroupID},
+			want: "\"securityEnabled\": true",
+		},
+		{
+			name: "get unknown group by object id",
+			args: []string{"get", "-g", "44444444-4444-4444-4444-444444444444"},
+			want: "entra group not found: 44444444-4444-4444-4444-444444444444",
+		},
+		{
+			name: "get unknown group with quoted name",
+			args: []string{"get", "-g", "O'Brien"},
+			want: "entra group not found: O'Brien",
+		},
+		{
+			name: "get ambiguous group name",
+			args: []string{"get", "-g", "Duplicates"},
+			want: "2 groups are named Duplicates, use the object id a|b",
+		},
+		{
+			name: "search direct member",
+			args: []string{"search", "-g", "Customer Admins", "-id", "alice@contoso.com"},
+			want: "emailID is found to be " + helpers.GreenValue("direct") + " of the group with membership name " + helpers.GreenValue(stubUserID),
+		},
+		{
+			name: "search transitive member",
+			args: []string{"search", "-g", stubGroupID, "-id", "bob@contoso.com"},
+			want: "emailID is found to be " + helpers.GreenValue("transitive") + " of the group with membership name " + helpers.GreenValue(nestedUserID),
+		},
+		{
+			name: "search non member by object id",
+			args: []string{"search", "-g", stubGroupID, "-id", "44444444-4444-4444-4444-444444444444"},
+			want: "[unfold] member not found",
+		},
+		{
+			name: "search unknown user",
+			args: []string{"search", "-g", stubGroupID, "-id", "carol@contoso.com"},
+			want: "user carol@contoso.com not found",
+		},
+		{
+			name: "search without user",
+			args: []string{"search", "-g", stubGroupID},
+			want: "something went wrong",
+		},
+		{
+			name: "add member",
+			args: []string{"configure", "-g", "Customer Admins", "-id", "bob@contoso.com"},
+			want: "successfully added the member to the given group",
+		},
+		{
+			name: "add existing member",
+			args: []string{"configure", "-g", "Customer Admins", "-id", "alice@contoso.com"},
+			want: "graph returned 400 for POST /v1.0/groups/" + stubGroupID + "/members/$ref",
+		},
+		{
+			name: "add member with transitive role",
+			args: []string{"configure", "-g", "Customer Admins", "-id", "bob@contoso.com", "-role", "transitive"},
+			want: "invalid role transitive, use direct",
+		},
+		{
+			name: "remove member",
+			args: []string{"configure", "-r", "-g", stubGroupID, "-id", "alice@contoso.com"},
+			want: "successfully removed the member from the group",
+		},
+		{
+			name: "remove non member",
+			args: []string{"configure", "-r", "-g", stubGroupID, "-id", "bob@contoso.com"},
+			want: "bob@contoso.com is not a direct member of the group Customer Admins",
+		},
+		{
+			name: "export direct and transitive members",
+			args: []string{"export", "-g", "Customer Admins"},
+			want: "exported " + helpers.GreenValue("2") + " member(s) of Customer Admins\nmember,roles,membership\nalice@contoso.com,direct," + stubUserID + "\nbob@contoso.com,transitive," + nestedUserID,
+		},
+		{
+			name: "export unknown group",
+			args: []string{"export", "-g", "O'Brien"},
+			want: "entra group not found: O'Brien",
+		},
+		{
+			name: "group is empty",
+			args: []string{"get"},
+			want: "group cannot be empty",
+		},
+		{
+			name: "invalid action",
+			args: []string{"delete", "-g", stubGroupID},
+			want: "provide a valid action get|search|configure|export",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandGroupConfig
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandGroupConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_listEntraUsers_foreignNextLink(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent to the foreign next link %s", r.URL)
+	}))
+	t.Cleanup(foreign.Close)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"value": [], "@odata.nextLink": %q}`, foreign.URL+graphVersion+"/groups/"+stubGroupID+"/members")
+	}))
+	t.Cleanup(server.Close)
+	useStubGraphServer(t, server)
+
+	if _, err := listEntraUsers("/groups/" + stubGroupID + "/members/microsoft.graph.user"); err == nil || !strings.Contains(err.Error(), "does not match the graph endpoint") {
+		t.Errorf("listEntraUsers() error = %v, want next link rejected", err)
+	}
+}
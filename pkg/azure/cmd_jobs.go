@@ -0,0 +1,164 @@
+package azure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/state"
+)
+
+// commandJobsConfig represents the configuration for the jobs command
+type commandJobsConfig struct {
+	FlagSet  *flag.FlagSet
+	JobsOpts struct {
+		JobID  *string
+		Status *string
+	}
+}
+
+// Execute executes the jobs command
+func (c commandJobsConfig) Execute() string {
+	action, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	switch action {
+	case commands.List:
+		return c.list()
+	case commands.Show:
+		return c.show()
+	case commands.Refresh:
+		return c.refresh()
+	}
+	return fmt.Sprintf("[unfold] provide a valid action %s|%s|%s", commands.List, commands.Show, commands.Refresh)
+}
+
+// list returns the jobs of the local job history, optionally filtered by status
+func (c commandJobsConfig) list() string {
+	records, err := ListJobs()
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to read the job history %s", helpers.RedValue(err.Error()))
+	}
+
+	filtered := []JobRecord{}
+	for _, record := range records {
+		switch *c.JobsOpts.Status {
+		case "running":
+			if !record.Running() {
+				continue
+			}
+		case "failed":
+			if record.JobResult != "failed" {
+				continue
+			}
+		}
+		filtered = append(filtered, record)
+	}
+
+	if len(filtered) == 0 {
+		return fmt.Sprintf("[unfold] no jobs found in the job history at %s", jobHistoryPath())
+	}
+	return fmt.Sprintf("[unfold] %d job(s) in the job history\n%s", len(filtered), formatJobRecords(filtered))
+}
+
+// show returns the recorded details of the job, refresh updates its status
+func (c commandJobsConfig) show() string {
+	if *c.JobsOpts.JobID == "" {
+		return "[unfold] job id cannot be empty"
+	}
+
+	records, err := ListJobs()
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to read the job history %s", helpers.RedValue(err.Error()))
+	}
+	for _, record := range records {
+		if record.JobID == *c.JobsOpts.JobID {
+			b, _ := json.MarshalIndent(record, "", " ")
+			return fmt.Sprintf("[unfold] job details \n%s", string(b))
+		}
+	}
+	return fmt.Sprintf("[unfold] job %s not found in the job history", *c.JobsOpts.JobID)
+}
+
+// refresh re-queries the status of the running jobs, or of the given job
+func (c commandJobsConfig) refresh() string {
+	ids := []string{}
+	if *c.JobsOpts.JobID != "" {
+		ids = append(ids, *c.JobsOpts.JobID)
+	}
+
+	refreshed, failures, err := RefreshJobs(ids...)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to update the job history %s", helpers.RedValue(err.Error()))
+	}
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "[unfold] refreshed %s job(s), %s failed", helpers.GreenValue(fmt.Sprint(len(refreshed))), helpers.RedValue(fmt.Sprint(len(failures))))
+	if len(refreshed) > 0 {
+		fmt.Fprintf(sb, "\n%s", formatJobRecords(refreshed))
+	}
+	ids = ids[:0]
+	for id := range failures {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(sb, "\n%s: %s", id, helpers.RedValue(strings.Join(strings.Fields(failures[id].Error()), " ")))
+	}
+	return sb.String()
+}
+
+// formatJobRecords renders the job records as a table
+func formatJobRecords(records []JobRecord) string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB ID\tOFFER\tMODE\tAUDIENCE\tSUBMITTED\tUSER\tSTATUS\tRESULT") //nolint:errcheck
+	for _, r := range records {
+		audience := "-"
+		if len(r.AudienceIDs) > 0 {
+			audience = strings.Join(r.AudienceIDs, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.JobID, r.Offer, r.Mode, audience, //nolint:errcheck
+			r.SubmittedAt.Local().Format(time.DateTime), r.User, r.JobStatus, jobResultValue(r.JobResult))
+	}
+	w.Flush() //nolint:errcheck
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// GetFlagSet returns the flag set for the jobs command
+func (c commandJobsConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandJobsConfig fetches the command jobs config
+func fetchCommandJobsConfig() commandJobsConfig {
+	flagSet := flag.NewFlagSet(commands.Jobs, flag.ContinueOnError)
+	bindRetryFlags(flagSet)
+	return commandJobsConfig{
+		JobsOpts: struct {
+			JobID  *string
+			Status *string
+		}{
+			JobID:  flagSet.String("j", "", "provide a job id of the job history"),
+			Status: flagSet.String("status", "", "only list running|failed jobs"),
+		},
+		FlagSet: flagSet,
+	}
+}
+
+// jobHistoryPath returns the path of the job history file
+func jobHistoryPath() string {
+	path, err := state.Path(jobHistoryName)
+	if err != nil {
+		return jobHistoryName
+	}
+	return path
+}
@@ -0,0 +1,192 @@
+package azure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+func Test_commandJobsConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+
+	history := `[
+		{"jobId": "job-1", "offer": "offer-2", "productId": "87654321-4321-4321-4321-210987654321", "audienceIds": ["12345678-1234-1234-1234-123456789abc"], "audienceType": "subscription", "mode": "add", "submittedAt": "2026-01-01T10:00:00Z", "user": "alice", "jobStatus": "running", "jobResult": "pending"},
+		{"jobId": "job-2", "offer": "offer-1", "productId": "12345678-1234-1234-1234-123456789abc", "mode": "publish-live", "submittedAt": "2026-01-02T10:00:00Z", "user": "bob", "jobStatus": "completed", "jobResult": "failed"},
+		{"jobId": "job-3", "offer": "offer-1", "productId": "12345678-1234-1234-1234-123456789abc", "mode": "remove", "submittedAt": "2026-01-03T10:00:00Z", "user": "bob", "jobStatus": "running", "jobResult": "pending"}
+	]`
+	statusURL := "https://graph.microsoft.com/rp/product-ingestion/configure/%s/status?$version=2022-07-01"
+
+	tests := []struct {
+		name        string
+		args        []string
+		history     string
+		transport   map[string]*http.Response
+		want        []string
+		wantHistory string
+	}{
+		{
+			name:    "list jobs most recent first",
+			args:    []string{"list"},
+			history: history,
+			want:    []string{"3 job(s) in the job history", "JOB ID", "job-3   offer-1  remove", "job-2   offer-1  publish-live  -"},
+		},
+		{
+			name:    "list running jobs",
+			args:    []string{"list", "-status", "running"},
+			history: history,
+			want:    []string{"2 job(s) in the job history"},
+		},
+		{
+			name:    "list failed jobs",
+			args:    []string{"list", "-status", "failed"},
+			history: history,
+			want:    []string{"1 job(s) in the job history", helpers.RedValue("failed")},
+		},
+		{
+			name: "list empty job history",
+			args: []string{"list"},
+			want: []string{"no jobs found in the job history at"},
+		},
+		{
+			name:    "list corrupted job history",
+			args:    []string{"list"},
+			history: "[",
+			want:    []string{"unable to read the job history"},
+		},
+		{
+			name:    "show job",
+			args:    []string{"show", "-j", "job-1"},
+			history: history,
+			want:    []string{"job details", `"audienceIds": [`, `"user": "alice"`},
+		},
+		{
+			name:    "show unknown job",
+			args:    []string{"show", "-j", "job-4"},
+			history: history,
+			want:    []string{"job job-4 not found in the job history"},
+		},
+		{
+			name: "show without job id",
+			args: []string{"show"},
+			want: []string{"job id cannot be empty"},
+		},
+		{
+			name:    "refresh running jobs",
+			args:    []string{"refresh"},
+			history: history,
+			transport: map[string]*http.Response{
+				strings.Replace(statusURL, "%s", "job-1", 1): {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"jobId": "job-1", "jobStatus": "completed", "jobResult": "succeeded"}`)),
+				},
+			},
+			want:        []string{"refreshed " + helpers.GreenValue("1") + " job(s), " + helpers.RedValue("1") + " failed", "job-1   offer-2", "job-3: " + helpers.RedValue(`marketplace returned 404 with response { "error": "not found" }`)},
+			wantHistory: `"jobResult": "succeeded"`,
+		},
+		{
+			name:    "refresh single job",
+			args:    []string{"refresh", "-j", "job-2"},
+			history: history,
+			transport: map[string]*http.Response{
+				strings.Replace(statusURL, "%s", "job-2", 1): {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"jobId": "job-2", "jobStatus": "completed", "jobResult": "failed"}`)),
+				},
+			},
+			want: []string{"refreshed " + helpers.GreenValue("1") + " job(s), " + helpers.RedValue("0") + " failed", "job-2"},
+		},
+		{
+			name: "invalid action",
+			args: []string{"delete"},
+			want: []string{"provide a valid action list|show|refresh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("UNFOLD_STATE_DIR", dir)
+			if tt.history != "" {
+				os.WriteFile(filepath.Join(dir, jobHistoryName+".json"), []byte(tt.history), 0o600) //nolint:errcheck
+			}
+
+			c := NewCommandModule().CommandJobsConfig
+			c.GetFlagSet().Parse(tt.args)
+			instances[ingestionEndpoint].httpClient = &http.Client{
+				Transport: &MockHTTPRoundTripper{Transport: tt.transport},
+			}
+			got := c.Execute()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandJobsConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+			if tt.wantHistory != "" {
+				b, _ := os.ReadFile(filepath.Join(dir, jobHistoryName+".json"))
+				if !strings.Contains(string(b), tt.wantHistory) {
+					t.Errorf("job history = %v, want %v", string(b), tt.wantHistory)
+				}
+			}
+		})
+	}
+}
+
+func Test_recordJob(t *testing.T) {
+	prepareTestEnvironment()
+	t.Setenv("UNFOLD_STATE_DIR", t.TempDir())
+
+	instances[ingestionEndpoint].httpClient = &http.Client{
+		Transport: &MockHTTPRoundTripper{
+			Transport: map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/plan?product=product/87654321-4321-4321-4321-210987654321&$version=2022-03-01-preview2": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"value": [{"id": "plan/87654321-4321-4321-4321-210987654321/aaaa"}]}`)),
+				},
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resources": []}`)),
+				},
+				"https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"jobId": "job-1", "jobStatus": "notStarted", "jobResult": "pending"}`)),
+				},
+			},
+		},
+	}
+	MakeConfigurationRequest("offer-2", "12345678-1234-1234-1234-123456789abc", "sub", "add")
+
+	records, err := ListJobs()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("ListJobs() = %v, %v, want one record", records, err)
+	}
+	got := records[0]
+	if got.JobID != "job-1" || got.Offer != "offer-2" || got.Mode != "add" || got.AudienceType != "subscription" ||
+		len(got.Plans) != 1 || got.Plans[0] != "plan/87654321-4321-4321-4321-210987654321/aaaa" || got.SubmittedAt.IsZero() {
+		t.Errorf("ListJobs()[0] = %+v", got)
+	}
+}
+
+func TestCheckJobCompleted(t *testing.T) {
+	prepareTestEnvironment()
+	statusURL := "https://graph.microsoft.com/rp/product-ingestion/configure/%s/status?$version=2022-07-01"
+	instances[ingestionEndpoint].httpClient = &http.Client{Transport: &MockHTTPRoundTripper{Transport: map[string]*http.Response{
+		fmt.Sprintf(statusURL, "job-done"):    {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"jobId": "job-done", "jobStatus": "completed", "jobResult": "succeeded"}`))},
+		fmt.Sprintf(statusURL, "job-running"): {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"jobId": "job-running", "jobStatus": "running", "jobResult": "pending"}`))},
+	}}}
+
+	if err := CheckJobCompleted(""); err != nil {
+		t.Errorf("CheckJobCompleted() without job error = %v", err)
+	}
+	if err := CheckJobCompleted("job-done"); err != nil {
+		t.Errorf("CheckJobCompleted() of completed job error = %v", err)
+	}
+	if err := CheckJobCompleted("job-running"); err == nil || err.Error() != "job job-running still running with status running, retry once it completed" {
+		t.Errorf("CheckJobCompleted() of running job error = %v, want still running", err)
+	}
+}
@@ -0,0 +1,267 @@
+package azure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandMeteringConfig represents the configuration for the metering command
+type commandMeteringConfig struct {
+	FlagSet      *flag.FlagSet
+	MeteringOpts struct {
+		Offer          *string
+		ResourceID     *string
+		Plan           *string
+		Dimension      *string
+		Quantity       *float64
+		Time           *string
+		File           *string
+		DryRun         *bool
+		Start          *string
+		End            *string
+		OfferID        *string
+		SubscriptionID *string
+		ReconStatus    *string
+	}
+}
+
+// Execute executes the metering command
+func (c commandMeteringConfig) Execute() string {
+	action, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	switch action {
+	case commands.Post:
+		return c.post()
+	case commands.Get:
+		return c.get()
+	}
+	return fmt.Sprintf("[unfold] provide a valid action %s|%s", commands.Post, commands.Get)
+}
+
+// post validates the usage events against the plans of the offer and posts them, a single event
+// from the flags or a batch from the CSV file
+func (c commandMeteringConfig) post() string {
+	if *c.MeteringOpts.Offer == "" {
+		return "[unfold] offer cannot be empty"
+	}
+	if _, ok := config.Offers[*c.MeteringOpts.Offer]; !ok {
+		return fmt.Sprintf("[unfold] offer %s is not configured", *c.MeteringOpts.Offer)
+	}
+
+	events, err := c.usageEvents()
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if len(events) == 0 {
+		return "[unfold] no usage events to post"
+	}
+	if err := ValidateUsageEvents(*c.MeteringOpts.Offer, events); err != nil {
+		return fmt.Sprintf("[unfold] invalid usage events\n%s", helpers.RedValue(err.Error()))
+	}
+
+	batch := *c.MeteringOpts.File != ""
+	if *c.MeteringOpts.DryRun {
+		return fmt.Sprintf("[unfold] dry run, %d usage event(s) not posted\n%s", len(events), formatUsagePayloads(events, batch))
+	}
+
+	if !batch {
+		res, err := PostUsageEvent(events[0])
+		if err != nil {
+			return fmt.Sprintf("[unfold] failed to post the usage event %s", helpers.RedValue(err.Error()))
+		}
+		b, _ := json.MarshalIndent(res, "", " ")
+		return fmt.Sprintf("[unfold] usage event %s \n%s", res.Status, string(b))
+	}
+
+	results, err := PostUsageEvents(events)
+	accepted := 0
+	for _, res := range results {
+		if res.Status == "Accepted" {
+			accepted++
+		}
+	}
+	out := fmt.Sprintf("[unfold] posted %d of %d usage event(s), %s accepted, %s rejected", len(results), len(events),
+		helpers.GreenValue(fmt.Sprint(accepted)), helpers.RedValue(fmt.Sprint(len(results)-accepted)))
+	if len(results) > 0 {
+		out += "\n" + formatUsageEventResults(results)
+	}
+	if err != nil {
+		out += "\n" + helpers.RedValue(err.Error())
+	}
+	return out
+}
+
+// usageEvents returns the usage events of the CSV file, stdin for -, or the single event of the flags
+func (c commandMeteringConfig) usageEvents() ([]UsageEvent, error) {
+	if *c.MeteringOpts.File == "" {
+		start, err := parseEffectiveStartTime(*c.MeteringOpts.Time)
+		if err != nil {
+			return nil, err
+		}
+		return []UsageEvent{{
+			ResourceID:         *c.MeteringOpts.ResourceID,
+			PlanID:             *c.MeteringOpts.Plan,
+			Dimension:          *c.MeteringOpts.Dimension,
+			Quantity:           *c.MeteringOpts.Quantity,
+			EffectiveStartTime: start,
+		}}, nil
+	}
+
+	in := stdin
+	if *c.MeteringOpts.File != "-" {
+		f, err := os.Open(*c.MeteringOpts.File)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	events, err := readUsageEvents(in)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read usage events %s", err.Error())
+	}
+	return events, nil
+}
+
+// get returns the usage events recorded by the metering service
+func (c commandMeteringConfig) get() string {
+	query := UsageQuery{
+		OfferID:        *c.MeteringOpts.OfferID,
+		PlanID:         *c.MeteringOpts.Plan,
+		Dimension:      *c.MeteringOpts.Dimension,
+		SubscriptionID: *c.MeteringOpts.SubscriptionID,
+		ReconStatus:    *c.MeteringOpts.ReconStatus,
+	}
+	var err error
+	if query.Start, err = parseUsageDate(*c.MeteringOpts.Start); err != nil || query.Start.IsZero() {
+		return "[unfold] provide a valid start date, e.g. 2026-01-31"
+	}
+	if query.End, err = parseUsageDate(*c.MeteringOpts.End); err != nil {
+		return "[unfold] provide a valid end date, e.g. 2026-01-31"
+	}
+
+	usage, err := GetUsageEvents(query)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if len(usage) == 0 {
+		return "[unfold] no usage events found"
+	}
+	return fmt.Sprintf("[unfold] %d usage record(s)\n%s", len(usage), formatUsageAggregates(usage))
+}
+
+// parseUsageDate parses a date or an RFC3339 time, empty returns the zero time
+func parseUsageDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// formatUsagePayloads renders the exact request bodies that would be posted
+func formatUsagePayloads(events []UsageEvent, batch bool) string {
+	if !batch {
+		b, _ := json.MarshalIndent(events[0], "", " ")
+		return fmt.Sprintf("POST %s\n%s", usageEventPath, string(b))
+	}
+
+	batches := UsageEventBatches(events)
+	payloads := make([]string, 0, len(batches))
+	for i, body := range batches {
+		b, _ := json.MarshalIndent(body, "", " ")
+		payloads = append(payloads, fmt.Sprintf("POST %s (batch %d/%d)\n%s", batchUsageEventPath, i+1, len(batches), string(b)))
+	}
+	return strings.Join(payloads, "\n")
+}
+
+// formatUsageEventResults renders the outcome of the posted usage events as a table
+func formatUsageEventResults(results []UsageEventResult) string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tPLAN\tDIMENSION\tQUANTITY\tSTART\tSTATUS\tDETAIL") //nolint:errcheck
+	for _, r := range results {
+		status, detail := helpers.GreenValue(r.Status), r.UsageEventID
+		if r.Status != "Accepted" {
+			status = helpers.RedValue(r.Status)
+			if r.Error != nil {
+				detail = strings.Join(strings.Fields(r.Error.Message), " ")
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%g\t%s\t%s\t%s\n", r.ResourceID, r.PlanID, r.Dimension, r.Quantity, //nolint:errcheck
+			r.EffectiveStartTime.UTC().Format(time.RFC3339), status, firstNonEmpty(detail, "-"))
+	}
+	w.Flush() //nolint:errcheck
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatUsageAggregates renders the recorded usage as a table
+func formatUsageAggregates(usage []UsageAggregate) string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tRESOURCE\tPLAN\tDIMENSION\tSUBMITTED\tPROCESSED\tCOUNT\tRECON STATUS") //nolint:errcheck
+	for _, u := range usage {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%d\t%s\n", u.UsageDate.UTC().Format(time.DateOnly), u.UsageResourceID, //nolint:errcheck
+			u.PlanID, u.Dimension, u.SubmittedQuantity, u.ProcessedQuantity, u.SubmittedCount, u.ReconStatus)
+	}
+	w.Flush() //nolint:errcheck
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// GetFlagSet returns the flag set for the metering command
+func (c commandMeteringConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandMeteringConfig fetches the command metering config
+func fetchCommandMeteringConfig() commandMeteringConfig {
+	flagSet := flag.NewFlagSet(commands.Metering, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindRetryFlags(flagSet)
+	return commandMeteringConfig{
+		MeteringOpts: struct {
+			Offer          *string
+			ResourceID     *string
+			Plan           *string
+			Dimension      *string
+			Quantity       *float64
+			Time           *string
+			File           *string
+			DryRun         *bool
+			Start          *string
+			End            *string
+			OfferID        *string
+			SubscriptionID *string
+			ReconStatus    *string
+		}{
+			Offer:          flagSet.String("o", "", "provide a valid azure offer name, used to validate the dimensions"),
+			ResourceID:     flagSet.String("resource", "", "SaaS subscription id or managed app resource id of the usage event"),
+			Plan:           flagSet.String("plan", "", "plan id of the usage event"),
+			Dimension:      flagSet.String("dimension", "", "custom meter dimension of the usage event"),
+			Quantity:       flagSet.Float64("quantity", 0, "consumed quantity of the usage event"),
+			Time:           flagSet.String("time", "", "effective start time of the usage event in RFC3339, defaults to the current hour"),
+			File:           flagSet.String("f", "", "CSV file of usage events resourceId,planId,dimension,quantity,effectiveStartTime, - for stdin"),
+			DryRun:         flagSet.Bool("dry-run", false, "validate and print the usage event payloads without posting them"),
+			Start:          flagSet.String("start", "", "start date of the usage events to get"),
+			End:            flagSet.String("end", "", "end date of the usage events to get"),
+			OfferID:        flagSet.String("offer-id", "", "only get the usage events of the offer id"),
+			SubscriptionID: flagSet.String("sid", "", "only get the usage events of the azure subscription"),
+			ReconStatus:    flagSet.String("recon", "", "only get the usage events with the reconciliation status"),
+		},
+		FlagSet: flagSet,
+	}
+}
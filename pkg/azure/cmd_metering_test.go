@@ -0,0 +1,239 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// meteringResourceTree is the resource tree of offer-2 with the custom meters of its plans
+const meteringResourceTree = `{"resources": [
+	{"$schema": "https://schema.mp.microsoft.com/schema/plan/2022-03-01-preview2", "id": "plan/87654321-4321-4321-4321-210987654321/aaaa", "identity": {"externalId": "gold"}},
+	{"$schema": "https://schema.mp.microsoft.com/schema/plan/2022-03-01-preview2", "id": "plan/87654321-4321-4321-4321-210987654321/bbbb", "identity": {"externalId": "silver"}},
+	{"$schema": "https://schema.mp.microsoft.com/schema/price-and-availability-offer/2022-03-01-preview3", "customMeters": {
+		"meter1": {"uniqueID": "api_calls", "displayName": "API calls", "unitOfMeasure": "1000 calls"},
+		"meter2": {"uniqueID": "storage_gb", "displayName": "Storage", "unitOfMeasure": "GB"}}},
+	{"$schema": "https://schema.mp.microsoft.com/schema/price-and-availability-plan/2022-03-01-preview3", "plan": "plan/87654321-4321-4321-4321-210987654321/aaaa", "customMeters": {
+		"meter1": {"enabled": true}, "meter2": {"enabled": false}}}
+]}`
+
+// stubMeteringServer serves the metering endpoints, counting the batch requests
+func stubMeteringServer(t *testing.T, batches *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != saasAPIVersion {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == usageEventPath:
+			var event UsageEvent
+			json.NewDecoder(r.Body).Decode(&event) //nolint:errcheck
+			if event.ResourceID == "sub-duplicate" {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"code": "Conflict", "message": "This usage event already exist."}`)
+				return
+			}
+			json.NewEncoder(w).Encode(UsageEventResult{UsageEventID: "evt-1", Status: "Accepted", ResourceID: event.ResourceID, //nolint:errcheck
+				Quantity: event.Quantity, Dimension: event.Dimension, EffectiveStartTime: event.EffectiveStartTime, PlanID: event.PlanID})
+		case r.Method == http.MethodPost && r.URL.Path == batchUsageEventPath:
+			atomic.AddInt32(batches, 1)
+			var batch UsageEventBatch
+			json.NewDecoder(r.Body).Decode(&batch) //nolint:errcheck
+			res := usageEventBatchResult{Count: len(batch.Request)}
+			for i, event := range batch.Request {
+				result := UsageEventResult{UsageEventID: fmt.Sprintf("evt-%d", i+1), Status: "Accepted", ResourceID: event.ResourceID,
+					Quantity: event.Quantity, Dimension: event.Dimension, EffectiveStartTime: event.EffectiveStartTime, PlanID: event.PlanID}
+				if event.Quantity > 100 {
+					result.UsageEventID, result.Status = "", "InvalidQuantity"
+					result.Error = &MeteringError{Code: "BadArgument", Message: "The quantity should be\n lower than 100."}
+				}
+				res.Result = append(res.Result, result)
+			}
+			json.NewEncoder(w).Encode(res) //nolint:errcheck
+		case r.Method == http.MethodGet && r.URL.Path == usageEventsPath:
+			if r.URL.Query().Get("usageStartDate") != "2026-01-01T00:00:00Z" || r.URL.Query().Get("dimension") != "api_calls" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[{"usageDate": "2026-01-02T00:00:00Z", "usageResourceId": "sub-1", "dimension": "api_calls", "planId": "gold",
+				"offerId": "offer-2", "reconStatus": "Accepted", "submittedQuantity": 12.5, "processedQuantity": 12.5, "submittedCount": 3}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_commandMeteringConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	var batches int32
+	useStubFulfillmentServer(t, stubMeteringServer(t, &batches))
+	treeURL := "https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321"
+
+	dir := t.TempDir()
+	writeCSV := func(name string, lines ...string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	validCSV := writeCSV("valid.csv",
+		"planId,resourceId,dimension,quantity,effectiveStartTime",
+		"gold,sub-1,api_calls,10,2026-01-01T10:00:00Z",
+		"silver,sub-2,storage_gb,250,2026-01-01T10:00:00Z",
+	)
+	invalidCSV := writeCSV("invalid.csv",
+		"resourceId,planId,dimension,quantity,effectiveStartTime",
+		"sub-1,gold,storage_gb,10,2026-01-01T10:00:00Z",
+		"sub-2,platinum,api_calls,10,2026-01-01T10:00:00Z",
+		",silver,api_calls,0,2099-01-01T10:00:00Z",
+	)
+	lines := []string{"resourceId,planId,dimension,quantity,effectiveStartTime"}
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("sub-%d,gold,api_calls,1,2026-01-01T10:00:00Z", i))
+	}
+	largeCSV := writeCSV("large.csv", lines...)
+
+	tests := []struct {
+		name        string
+		args        []string
+		want        string
+		wantBatches int32
+	}{
+		{
+			name: "post single usage event",
+			args: []string{"post", "-o", "offer-2", "-resource", "sub-1", "-plan", "gold", "-dimension", "api_calls", "-quantity", "2.5", "-time", "2026-01-01T10:00:00Z"},
+			want: "usage event Accepted \n{\n \"usageEventId\": \"evt-1\",\n \"status\": \"Accepted\"",
+		},
+		{
+			name: "post duplicate usage event",
+			args: []string{"post", "-o", "offer-2", "-resource", "sub-duplicate", "-plan", "gold", "-dimension", "api_calls", "-quantity", "1", "-time", "2026-01-01T10:00:00Z"},
+			want: "marketplace returned 409 for POST /api/usageEvent",
+		},
+		{
+			name: "dry run of single usage event",
+			args: []string{"post", "-o", "offer-2", "-resource", "sub-1", "-plan", "gold", "-dimension", "api_calls", "-quantity", "1", "-time", "2026-01-01T10:00:00Z", "--dry-run"},
+			want: "dry run, 1 usage event(s) not posted\nPOST /api/usageEvent\n{\n \"resourceId\": \"sub-1\",\n \"quantity\": 1,\n \"dimension\": \"api_calls\",\n \"effectiveStartTime\": \"2026-01-01T10:00:00Z\",\n \"planId\": \"gold\"\n}",
+		},
+		{
+			name: "dimension disabled on the plan",
+			args: []string{"post", "-o", "offer-2", "-resource", "sub-1", "-plan", "gold", "-dimension", "storage_gb", "-quantity", "1"},
+			want: `usage event 1: invalid dimension "storage_gb" for plan gold, use api_calls`,
+		},
+		{
+			name:        "post batch from csv",
+			args:        []string{"post", "-o", "offer-2", "-f", validCSV},
+			want:        "posted 2 of 2 usage event(s), " + helpers.GreenValue("1") + " accepted, " + helpers.RedValue("1") + " rejected",
+			wantBatches: 1,
+		},
+		{
+			name:        "batch rejection detail",
+			args:        []string{"post", "-o", "offer-2", "-f", validCSV},
+			want:        "sub-2     silver  storage_gb  250       2026-01-01T10:00:00Z  " + helpers.RedValue("InvalidQuantity") + "  The quantity should be lower than 100.",
+			wantBatches: 1,
+		},
+		{
+			name:        "post large batch in chunks",
+			args:        []string{"post", "-o", "offer-2", "-f", largeCSV},
+			want:        "posted 30 of 30 usage event(s), " + helpers.GreenValue("30") + " accepted",
+			wantBatches: 2,
+		},
+		{
+			name: "dry run of large batch",
+			args: []string{"post", "-o", "offer-2", "-f", largeCSV, "--dry-run"},
+			want: "POST /api/batchUsageEvent (batch 2/2)\n{\n \"request\": [\n  {\n   \"resourceId\": \"sub-25\"",
+		},
+		{
+			name: "invalid usage events of csv",
+			args: []string{"post", "-o", "offer-2", "-f", invalidCSV, "--dry-run"},
+			want: `usage event 1: invalid dimension "storage_gb" for plan gold, use api_calls` +
+				"\nusage event 2: plan platinum not found in the offer offer-2" +
+				"\nusage event 3: resource id cannot be empty" +
+				"\nusage event 3: quantity must be positive" +
+				"\nusage event 3: effective start time 2099-01-01T10:00:00Z is in the future",
+		},
+		{
+			name: "csv file not found",
+			args: []string{"post", "-o", "offer-2", "-f", filepath.Join(dir, "missing.csv")},
+			want: "no such file or directory",
+		},
+		{
+			name: "post without offer",
+			args: []string{"post", "-resource", "sub-1"},
+			want: "offer cannot be empty",
+		},
+		{
+			name: "post with unknown offer",
+			args: []string{"post", "-o", "offer-3"},
+			want: "offer offer-3 is not configured",
+		},
+		{
+			name: "get usage events",
+			args: []string{"get", "-start", "2026-01-01", "-dimension", "api_calls"},
+			want: "1 usage record(s)\nDATE        RESOURCE  PLAN  DIMENSION  SUBMITTED  PROCESSED  COUNT  RECON STATUS\n2026-01-02  sub-1     gold  api_calls  12.5       12.5       3      Accepted",
+		},
+		{
+			name: "get without usage events",
+			args: []string{"get", "-start", "2026-01-01T00:00:00Z", "-dimension", "storage_gb"},
+			want: "no usage events found",
+		},
+		{
+			name: "get without start date",
+			args: []string{"get"},
+			want: "provide a valid start date",
+		},
+		{
+			name: "invalid action",
+			args: []string{"delete"},
+			want: "provide a valid action post|get",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&batches, 0)
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: &sequenceRoundTripper{bodies: map[string][]string{
+				treeURL: {meteringResourceTree, meteringResourceTree, meteringResourceTree},
+			}}}
+			c := NewCommandModule().CommandMeteringConfig
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandMeteringConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			if got := atomic.LoadInt32(&batches); got != tt.wantBatches {
+				t.Errorf("commandMeteringConfig.Execute() posted %d batches, want %d", got, tt.wantBatches)
+			}
+		})
+	}
+}
+
+func Test_readUsageEvents(t *testing.T) {
+	events, err := readUsageEvents(strings.NewReader("resourceId,planId,dimension,quantity\nsub-1,gold,api_calls,1.5\n"))
+	if err != nil {
+		t.Fatalf("readUsageEvents() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Quantity != 1.5 || events[0].EffectiveStartTime != time.Now().UTC().Truncate(time.Hour) {
+		t.Errorf("readUsageEvents() = %+v, want one event in the current hour", events)
+	}
+
+	for input, want := range map[string]string{
+		"resourceId,planId,quantity\n":                                                                "missing the column dimension",
+		"resourceId,planId,dimension,quantity\nsub-1,gold,api_calls,many\n":                           "line 2: invalid quantity",
+		"resourceId,planId,dimension,quantity,effectiveStartTime\nsub-1,gold,api_calls,1,yesterday\n": "line 2: invalid effective start time",
+	} {
+		if _, err := readUsageEvents(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("readUsageEvents(%q) error = %v, want %v", input, err, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package azure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandOfferConfig represents the configuration for the offer command
+type commandOfferConfig struct {
+	FlagSet   *flag.FlagSet
+	OfferOpts struct {
+		Offer   *string
+		Target  *string
+		Wait    *bool
+		Timeout *time.Duration
+	}
+}
+
+// Execute executes the offer command
+func (c commandOfferConfig) Execute() string {
+	action, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.OfferOpts.Offer == "" {
+		return "[unfold] offer cannot be empty"
+	}
+	if _, ok := config.Offers[*c.OfferOpts.Offer]; !ok {
+		return fmt.Sprintf("[unfold] offer %s is not configured", *c.OfferOpts.Offer)
+	}
+
+	switch action {
+	case commands.Status:
+		submissions, err := GetSubmissions(config.Offers[*c.OfferOpts.Offer].ProductDurableID)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		return fmt.Sprintf("[unfold] %s", formatSubmissions(*c.OfferOpts.Offer, submissions))
+	case commands.Publish:
+		return c.publish()
+	}
+	return fmt.Sprintf("[unfold] provide a valid action %s|%s", commands.Status, commands.Publish)
+}
+
+// publish posts the submission of the offer and optionally waits for the job to complete
+func (c commandOfferConfig) publish() string {
+	azureJob, err := PublishOffer(*c.OfferOpts.Offer, *c.OfferOpts.Target)
+	if err != nil {
+		return fmt.Sprintf("[unfold] failed to publish the offer %s", helpers.RedValue(err.Error()))
+	}
+
+	if !*c.OfferOpts.Wait {
+		b, _ := json.MarshalIndent(azureJob, "", " ")
+		return fmt.Sprintf("[unfold] publish response \n%s", string(b))
+	}
+
+	azureJob, err = WaitForJob(azureJob.JobID, *c.OfferOpts.Timeout)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	return fmt.Sprintf("[unfold] %s", formatJobStatus(azureJob))
+}
+
+// GetFlagSet returns the flag set for the offer command
+func (c commandOfferConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandOfferConfig fetches the command offer config
+func fetchCommandOfferConfig() commandOfferConfig {
+	flagSet := flag.NewFlagSet(commands.Offer, flag.ContinueOnError)
+	bindRetryFlags(flagSet)
+	return commandOfferConfig{
+		OfferOpts: struct {
+			Offer   *string
+			Target  *string
+			Wait    *bool
+			Timeout *time.Duration
+		}{
+			Offer:   flagSet.String("o", "", "provide a valid azure offer name"),
+			Target:  flagSet.String("target", PreviewTarget, "publish target preview|live"),
+			Wait:    flagSet.Bool("wait", false, "wait for the publish job to complete"),
+			Timeout: flagSet.Duration("timeout", 30*time.Minute, "maximum time to wait for the publish job"),
+		},
+		FlagSet: flagSet,
+	}
+}
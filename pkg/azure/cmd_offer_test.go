@@ -0,0 +1,166 @@
+package azure
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// sequenceRoundTripper answers each request with the next body queued for its url
+type sequenceRoundTripper struct {
+	bodies map[string][]string
+}
+
+func (s *sequenceRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	if len(s.bodies[url]) == 0 {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(`{"error": "not found"}`))}, nil
+	}
+	body := s.bodies[url][0]
+	s.bodies[url] = s.bodies[url][1:]
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+}
+
+func Test_commandOfferConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	jobPollInterval = time.Millisecond
+	defer func() { jobPollInterval = 10 * time.Second }()
+
+	submissionsURL := "https://graph.microsoft.com/rp/product-ingestion/submission/87654321-4321-4321-4321-210987654321?$version=2022-03-01-preview2"
+	configureURL := "https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2"
+	statusURL := "https://graph.microsoft.com/rp/product-ingestion/configure/12345678-1234-1234-1234-123456789def/status?$version=2022-07-01"
+	pendingJob := `{"jobId": "12345678-1234-1234-1234-123456789def", "jobStatus": "running", "jobResult": "pending", "errors": []}`
+	completedJob := `{"jobId": "12345678-1234-1234-1234-123456789def", "jobStatus": "completed", "jobResult": "succeeded", "errors": []}`
+
+	tests := []struct {
+		name   string
+		args   []string
+		bodies map[string][]string
+		err    error
+		want   string
+	}{
+		{
+			name: "status in sync",
+			args: []string{"status", "-o", "offer-2"},
+			bodies: map[string][]string{
+				submissionsURL: {`{"value": [
+					{"id": "submission/87654321-4321-4321-4321-210987654321/1", "target": {"targetType": "preview"}, "lifecycleState": "generallyAvailable", "status": "completed", "result": "succeeded", "created": "2026-01-01T10:00:00Z"},
+					{"id": "submission/87654321-4321-4321-4321-210987654321/2", "target": {"targetType": "live"}, "lifecycleState": "generallyAvailable", "status": "completed", "result": "succeeded", "created": "2026-01-02T10:00:00Z"}
+				]}`},
+			},
+			want: "submissions of offer-2: " + helpers.GreenValue("preview and live are in sync") + "\nTARGET   STATUS     RESULT     LIFECYCLE           CREATED               ID\npreview  completed  succeeded  generallyAvailable  2026-01-01T10:00:00Z  submission/87654321-4321-4321-4321-210987654321/1",
+		},
+		{
+			name: "status with unpublished preview",
+			args: []string{"status", "-o", "offer-2"},
+			bodies: map[string][]string{
+				submissionsURL: {`{"value": [
+					{"id": "submission/87654321-4321-4321-4321-210987654321/2", "target": {"targetType": "live"}, "status": "completed", "created": "2026-01-02T10:00:00Z"},
+					{"id": "submission/87654321-4321-4321-4321-210987654321/3", "target": {"targetType": "preview"}, "status": "completed", "created": "2026-01-03T10:00:00Z"}
+				]}`},
+			},
+			want: helpers.RedValue("preview has changes not yet published live"),
+		},
+		{
+			name: "status in progress",
+			args: []string{"status", "-o", "offer-2"},
+			bodies: map[string][]string{
+				submissionsURL: {`{"value": [{"id": "submission/87654321-4321-4321-4321-210987654321/3", "target": {"targetType": "preview"}, "status": "running"}]}`},
+			},
+			want: helpers.RedValue("submission in progress"),
+		},
+		{
+			name: "status never published live",
+			args: []string{"status", "-o", "offer-2"},
+			bodies: map[string][]string{
+				submissionsURL: {`{"value": [{"id": "submission/87654321-4321-4321-4321-210987654321/3", "target": {"targetType": "preview"}, "status": "completed"}]}`},
+			},
+			want: helpers.RedValue("not published live"),
+		},
+		{
+			name: "status without submissions",
+			args: []string{"status", "-o", "offer-2"},
+			bodies: map[string][]string{
+				submissionsURL: {`{"value": []}`},
+			},
+			want: "no submission found for the offer offer-2",
+		},
+		{
+			name: "status request failure",
+			args: []string{"status", "-o", "offer-2"},
+			want: "marketplace returned 404 for GetSubmissions",
+		},
+		{
+			name: "status http call error",
+			args: []string{"status", "-o", "offer-2"},
+			err:  errors.New("http call error"),
+			want: "http call error",
+		},
+		{
+			name: "publish to live",
+			args: []string{"publish", "-o", "offer-2", "--target", "live"},
+			bodies: map[string][]string{
+				configureURL: {pendingJob},
+			},
+			want: "publish response \n{\n \"jobId\": \"12345678-1234-1234-1234-123456789def\"",
+		},
+		{
+			name: "publish and wait for the job",
+			args: []string{"publish", "-o", "offer-2", "-wait"},
+			bodies: map[string][]string{
+				configureURL: {pendingJob},
+				statusURL:    {pendingJob, completedJob},
+			},
+			want: "job status report\njob: 12345678-1234-1234-1234-123456789def\nstatus: completed",
+		},
+		{
+			name: "publish and wait until timeout",
+			args: []string{"publish", "-o", "offer-2", "-wait", "-timeout", "0s"},
+			bodies: map[string][]string{
+				configureURL: {pendingJob},
+				statusURL:    {pendingJob},
+			},
+			want: "job 12345678-1234-1234-1234-123456789def is still running after 0s",
+		},
+		{
+			name: "publish to invalid target",
+			args: []string{"publish", "-o", "offer-2", "--target", "draft"},
+			want: "invalid target draft, use preview|live",
+		},
+		{
+			name: "offer is empty",
+			args: []string{"status"},
+			want: "offer cannot be empty",
+		},
+		{
+			name: "offer is not configured",
+			args: []string{"status", "-o", "offer-3"},
+			want: "offer offer-3 is not configured",
+		},
+		{
+			name: "invalid action",
+			args: []string{"list", "-o", "offer-2"},
+			want: "provide a valid action status|publish",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandOfferConfig
+			c.GetFlagSet().Parse(tt.args)
+			var transport http.RoundTripper = &sequenceRoundTripper{bodies: tt.bodies}
+			if tt.err != nil {
+				transport = &MockHTTPRoundTripper{Error: tt.err}
+			}
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: transport}
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandOfferConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package azure
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// commandPreviewConfig represents the configuration for the preview command
+type commandPreviewConfig struct {
+	FlagSet     *flag.FlagSet
+	PreviewOpts struct {
+		SubscriptionID *string
+		TenantID       *string
+		Label          *string
+		Offer          *string
+		PlanOut        *string
+	}
+}
+
+// Execute executes the preview command
+func (c commandPreviewConfig) Execute() string {
+	action, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.PreviewOpts.Offer == "" {
+		return "[unfold] offer cannot be empty"
+	}
+	if _, ok := config.Offers[*c.PreviewOpts.Offer]; !ok {
+		return fmt.Sprintf("[unfold] offer %s is not configured", *c.PreviewOpts.Offer)
+	}
+
+	switch action {
+	case commands.List:
+		return c.list()
+	case commands.Add, commands.Remove:
+		resource, resourceID := "sub", *c.PreviewOpts.SubscriptionID
+		if resourceID == "" {
+			resource, resourceID = "tenant", *c.PreviewOpts.TenantID
+		}
+		if resourceID == "" {
+			return "[unfold] id cannot be empty"
+		}
+		if *c.PreviewOpts.PlanOut != "" {
+			return c.planOut(resourceID, resource, action)
+		}
+		return fmt.Sprintf("[unfold] %v", MakePreviewAudienceRequest(*c.PreviewOpts.Offer, resourceID, resource, *c.PreviewOpts.Label, action))
+	}
+	return fmt.Sprintf("[unfold] provide a valid action %s|%s|%s", commands.Add, commands.Remove, commands.List)
+}
+
+// planOut writes the operation adding or removing the audience to the plan file instead of executing it
+func (c commandPreviewConfig) planOut(id, audType, mode string) string {
+	op, err := PlanPreviewAudience(*c.PreviewOpts.Offer, id, audType, *c.PreviewOpts.Label, mode)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	summary, err := plan.Save(*c.PreviewOpts.PlanOut, *op)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to write the plan %s", helpers.RedValue(err.Error()))
+	}
+	return fmt.Sprintf("[unfold] %s", summary)
+}
+
+// list returns the preview audience of the offer
+func (c commandPreviewConfig) list() string {
+	audiences, err := GetPreviewAudienceListForOffer(config.Offers[*c.PreviewOpts.Offer].ProductDurableID)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if len(audiences) == 0 {
+		return fmt.Sprintf("[unfold] preview audience of %s is empty", *c.PreviewOpts.Offer)
+	}
+
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tID\tLABEL") //nolint:errcheck
+	for _, audience := range audiences {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", audience.Type, audience.ID, audience.Label) //nolint:errcheck
+	}
+	w.Flush() //nolint:errcheck
+
+	return fmt.Sprintf("[unfold] %d audience(s) in preview audience of %s\n%s", len(audiences), *c.PreviewOpts.Offer, strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// GetFlagSet returns the flag set for the preview command
+func (c commandPreviewConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandPreviewConfig fetches the command preview config
+func fetchCommandPreviewConfig() commandPreviewConfig {
+	flagSet := flag.NewFlagSet(commands.Preview, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindRetryFlags(flagSet)
+	return commandPreviewConfig{
+		PreviewOpts: struct {
+			SubscriptionID *string
+			TenantID       *string
+			Label          *string
+			Offer          *string
+			PlanOut        *string
+		}{
+			SubscriptionID: flagSet.String("sid", "", "provide a valid azure subscription id"),
+			TenantID:       flagSet.String("tid", "", "provide a valid azure tenant id"),
+			Label:          flagSet.String("label", "", "label of the audience shown in Partner Center"),
+			Offer:          flagSet.String("o", "", "provide a valid azure offer name"),
+			PlanOut:        flagSet.String("plan-out", "", "write the resolved add or remove operation to the plan file instead of executing it"),
+		},
+		FlagSet: flagSet,
+	}
+}
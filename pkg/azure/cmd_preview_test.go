@@ -0,0 +1,253 @@
+package azure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+func Test_commandPreviewConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+
+	treeURL := "https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321"
+	configureURL := "https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2"
+	tree := `{"resources": [{"$schema": "https://schema.mp.microsoft.com/schema/preview-audience/2022-03-01-preview2", "previewAudiences": [{"type": "subscription", "id": "12345678-1234-1234-1234-123456789abc", "label": "qa"}]}]}`
+	job := `{"jobId": "12345678-1234-1234-1234-123456789def", "jobStatus": "notStarted", "jobResult": "pending", "errors": []}`
+
+	tests := []struct {
+		name          string
+		args          []string
+		transport     map[string]*http.Response
+		httpCallError error
+		want          string
+	}{
+		{
+			name: "list preview audience",
+			args: []string{"list", "-o", "offer-2"},
+			transport: map[string]*http.Response{
+				treeURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+			},
+			want: "1 audience(s) in preview audience of offer-2\nTYPE          ID                                    LABEL\nsubscription  12345678-1234-1234-1234-123456789abc  qa",
+		},
+		{
+			name: "list empty preview audience",
+			args: []string{"list", "-o", "offer-2"},
+			transport: map[string]*http.Response{
+				treeURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"resources": []}`))},
+			},
+			want: "preview audience of offer-2 is empty",
+		},
+		{
+			name: "add subscription to preview audience",
+			args: []string{"add", "-o", "offer-2", "-sid", "12345678-1234-1234-1234-123456789abd"},
+			transport: map[string]*http.Response{
+				treeURL:      {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+				configureURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(job))},
+			},
+			want: `"azureJobID": "12345678-1234-1234-1234-123456789def"`,
+		},
+		{
+			name: "add tenant to preview audience",
+			args: []string{"add", "-o", "offer-2", "-tid", "0b37927b-359e-4a60-8aac-67f88409ac5a", "-label", "contoso"},
+			transport: map[string]*http.Response{
+				treeURL:      {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+				configureURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(job))},
+			},
+			want: `"syncAudienceType": "tenant"`,
+		},
+		{
+			name: "add subscription already in preview audience",
+			args: []string{"add", "-o", "offer-2", "-sid", "12345678-1234-1234-1234-123456789ABC"},
+			transport: map[string]*http.Response{
+				treeURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+			},
+			want: "is already in the preview audience with type subscription",
+		},
+		{
+			name: "remove subscription from preview audience",
+			args: []string{"remove", "-o", "offer-2", "-sid", "12345678-1234-1234-1234-123456789abc"},
+			transport: map[string]*http.Response{
+				treeURL:      {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+				configureURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(job))},
+			},
+			want: "configure response",
+		},
+		{
+			name: "remove subscription not in preview audience",
+			args: []string{"remove", "-o", "offer-2", "-sid", "12345678-1234-1234-1234-123456789abd"},
+			transport: map[string]*http.Response{
+				treeURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+			},
+			want: "12345678-1234-1234-1234-123456789abd is not in the preview audience",
+		},
+		{
+			name: "configure request failure",
+			args: []string{"add", "-o", "offer-2", "-sid", "12345678-1234-1234-1234-123456789abd"},
+			transport: map[string]*http.Response{
+				treeURL:      {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(tree))},
+				configureURL: {StatusCode: http.StatusBadRequest, Body: io.NopCloser(bytes.NewBufferString(`{"error": "bad request"}`))},
+			},
+			want: "marketplace returned 400 for configurePrivateAudienceAPI",
+		},
+		{
+			name:          "http call error",
+			args:          []string{"list", "-o", "offer-2"},
+			httpCallError: errors.New("http call error"),
+			want:          "http call error",
+		},
+		{
+			name: "id is empty",
+			args: []string{"add", "-o", "offer-2"},
+			want: "id cannot be empty",
+		},
+		{
+			name: "offer is empty",
+			args: []string{"list"},
+			want: "offer cannot be empty",
+		},
+		{
+			name: "offer is not configured",
+			args: []string{"list", "-o", "offer-3"},
+			want: "offer offer-3 is not configured",
+		},
+		{
+			name: "invalid action",
+			args: []string{"show", "-o", "offer-2"},
+			want: "provide a valid action add|remove|list",
+		},
+		{
+			name: "invalid flag after action",
+			args: []string{"add", "-x"},
+			want: "flag provided but not defined: -x",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandPreviewConfig
+			c.GetFlagSet().Parse(tt.args)
+			instances[ingestionEndpoint].httpClient = &http.Client{
+				Transport: &MockHTTPRoundTripper{
+					Transport: tt.transport,
+					Error:     tt.httpCallError,
+				},
+			}
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandPreviewConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MakePreviewAudienceRequest_staleCache(t *testing.T) {
+	prepareTestEnvironment()
+	t.Setenv("UNFOLD_NO_CACHE", "")
+	t.Setenv("UNFOLD_CACHE_DIR", t.TempDir())
+
+	productID := "87654321-4321-4321-4321-210987654321"
+	stale := Resources{Resources: []TreeResource{{Schema: previewAudienceSchema, PreviewAudiences: []PreviewAudience{{Type: "subscription", ID: "12345678-1234-1234-1234-123456789abc"}}}}}
+	cache.Set(resourceTreeCacheKey(productID), stale, resourceTreeCacheTTL)
+
+	fresh := `{"resources": [{"$schema": "https://schema.mp.microsoft.com/schema/preview-audience/2022-03-01-preview2", "previewAudiences": [{"type": "subscription", "id": "12345678-1234-1234-1234-123456789abc"}, {"type": "tenant", "id": "0b37927b-359e-4a60-8aac-67f88409ac5a"}]}]}`
+	transport := &recordingRoundTripper{MockHTTPRoundTripper: MockHTTPRoundTripper{Transport: map[string]*http.Response{
+		"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/" + productID:     {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(fresh))},
+		"https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2": {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"jobId": "12345678-1234-1234-1234-123456789def", "jobStatus": "notStarted"}`))},
+	}}}
+	instances[ingestionEndpoint].httpClient = &http.Client{Transport: transport}
+
+	MakePreviewAudienceRequest("offer-2", "12345678-1234-1234-1234-123456789abd", "subscription", "", "add")
+	if len(transport.bodies) != 1 {
+		t.Fatalf("MakePreviewAudienceRequest() sent %d request bodies, want 1", len(transport.bodies))
+	}
+	if !strings.Contains(transport.bodies[0], "0b37927b-359e-4a60-8aac-67f88409ac5a") {
+		t.Errorf("MakePreviewAudienceRequest() dropped the audience missing from the stale cache, body = %v", transport.bodies[0])
+	}
+}
+
+func Test_commandPreviewConfig_PlanOut(t *testing.T) {
+	prepareTestEnvironment()
+	treeURL := "https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321"
+	configureURL := "https://graph.microsoft.com/rp/product-ingestion/configure?$version=2022-03-01-preview2"
+	tree := func(ids ...string) *http.Response {
+		audiences := []string{}
+		for _, id := range ids {
+			audiences = append(audiences, fmt.Sprintf(`{"type": "tenant", "id": "%s"}`, id))
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(
+			`{"resources": [{"$schema": "https://schema.mp.microsoft.com/schema/preview-audience/2022-03-01-preview2", "previewAudiences": [` + strings.Join(audiences, ",") + `]}]}`))}
+	}
+	tenant, other := "0b37927b-359e-4a60-8aac-67f88409ac5a", "12345678-1234-1234-1234-123456789abc"
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	c := NewCommandModule().CommandPreviewConfig
+	c.GetFlagSet().Parse([]string{"add", "-o", "offer-2", "-tid", tenant, "-label", "contoso", "--plan-out", path})
+	// the configure endpoint is not mocked, the plan must not be executed
+	instances[ingestionEndpoint].httpClient = &http.Client{Transport: &MockHTTPRoundTripper{}}
+	if got := c.Execute(); !strings.Contains(got, "plan with 1 operation(s) written to "+path) {
+		t.Fatalf("commandPreviewConfig.Execute() = %v, want the plan summary", got)
+	}
+
+	p, err := plan.Read(path)
+	if err != nil {
+		t.Fatalf("plan.Read() error = %v", err)
+	}
+	want := plan.Operation{Kind: plan.AzurePreviewAudience, Mode: plan.AddMode, Offer: "offer-2", ProductDurableID: "87654321-4321-4321-4321-210987654321",
+		AudienceType: "tenant", AudienceID: tenant, Label: "contoso"}
+	if !reflect.DeepEqual(p.Operations, []plan.Operation{want}) {
+		t.Fatalf("plan = %+v, want %+v", p.Operations, want)
+	}
+
+	tests := []struct {
+		name    string
+		tree    *http.Response
+		wantErr string
+	}{
+		{
+			name: "audience added along the current preview audience",
+			tree: tree(other),
+		},
+		{
+			name:    "audience added since the plan was written",
+			tree:    tree(other, tenant),
+			wantErr: tenant + " is already in the preview audience with type tenant",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UNFOLD_STATE_DIR", t.TempDir())
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: &MockHTTPRoundTripper{Transport: map[string]*http.Response{treeURL: tt.tree}}}
+			err := ValidatePlannedPreviewAudience(p.Operations[0])
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ValidatePlannedPreviewAudience() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidatePlannedPreviewAudience() error = %v", err)
+			}
+
+			transport := &recordingRoundTripper{MockHTTPRoundTripper: MockHTTPRoundTripper{Transport: map[string]*http.Response{
+				treeURL:      tree(other),
+				configureURL: {StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(`{"jobId": "job-preview", "jobStatus": "notStarted"}`))},
+			}}}
+			instances[ingestionEndpoint].httpClient = &http.Client{Transport: transport}
+			res, err := ApplyPlannedPreviewAudience(p.Operations[0])
+			if err != nil || res.AzureJobID != "job-preview" || res.TenantID != tenant {
+				t.Fatalf("ApplyPlannedPreviewAudience() = %+v, %v", res, err)
+			}
+			body := transport.bodies[len(transport.bodies)-1]
+			if !strings.Contains(body, `"id":"`+other+`"`) || !strings.Contains(body, `{"type":"tenant","id":"`+tenant+`","label":"contoso"}`) {
+				t.Errorf("configure request %s does not keep the current audience and add the planned one", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package azure
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandSaaSConfig represents the configuration for the saas command
+type commandSaaSConfig struct {
+	FlagSet  *flag.FlagSet
+	SaaSOpts struct {
+		Plan        *string
+		Quantity    *int
+		OperationID *string
+		Status      *string
+	}
+}
+
+// Execute executes the saas command
+func (c commandSaaSConfig) Execute() string {
+	action, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if action == commands.List {
+		subscriptions, err := ListSaaSSubscriptions()
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		if len(subscriptions) == 0 {
+			return "[unfold] no SaaS subscription found"
+		}
+		return fmt.Sprintf("[unfold] %d SaaS subscription(s)\n%s", len(subscriptions), formatSaaSSubscriptions(subscriptions))
+	}
+
+	// the remaining actions take the token or the subscription id as argument
+	arg, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	switch action {
+	case commands.Resolve:
+		if arg == "" {
+			return "[unfold] marketplace token cannot be empty"
+		}
+		res, err := ResolveSaaSSubscription(arg)
+		return saasResult("resolved subscription", res, err)
+	case commands.Get, commands.Activate, commands.Operations, commands.UpdateOperation:
+		if arg == "" {
+			return "[unfold] subscription id cannot be empty"
+		}
+	default:
+		return fmt.Sprintf("[unfold] provide a valid action %s|%s|%s|%s|%s|%s", commands.Resolve, commands.List,
+			commands.Get, commands.Activate, commands.Operations, commands.UpdateOperation)
+	}
+
+	switch action {
+	case commands.Get:
+		res, err := GetSaaSSubscription(arg)
+		return saasResult("subscription", res, err)
+	case commands.Activate:
+		if err := ActivateSaaSSubscription(arg, *c.SaaSOpts.Plan, *c.SaaSOpts.Quantity); err != nil {
+			return fmt.Sprintf("[unfold] failed to activate the subscription %s", helpers.RedValue(err.Error()))
+		}
+		return fmt.Sprintf("[unfold] subscription %s activated on plan %s", arg, helpers.GreenValue(*c.SaaSOpts.Plan))
+	case commands.Operations:
+		operations, err := ListSaaSOperations(arg)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		if len(operations) == 0 {
+			return fmt.Sprintf("[unfold] no pending operation for the subscription %s", arg)
+		}
+		return fmt.Sprintf("[unfold] %d pending operation(s) of %s\n%s", len(operations), arg, formatSaaSOperations(operations))
+	}
+
+	if err := UpdateSaaSOperation(arg, *c.SaaSOpts.OperationID, *c.SaaSOpts.Status); err != nil {
+		return fmt.Sprintf("[unfold] failed to update the operation %s", helpers.RedValue(err.Error()))
+	}
+	return fmt.Sprintf("[unfold] operation %s of %s updated to %s", *c.SaaSOpts.OperationID, arg, helpers.GreenValue(*c.SaaSOpts.Status))
+}
+
+// saasResult renders the result of a fulfillment call as JSON
+func saasResult(title string, res any, err error) string {
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	b, _ := json.MarshalIndent(res, "", " ")
+	return fmt.Sprintf("[unfold] %s \n%s", title, string(b))
+}
+
+// formatSaaSSubscriptions renders the SaaS subscriptions as a table
+func formatSaaSSubscriptions(subscriptions []SaaSSubscription) string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tOFFER\tPLAN\tQUANTITY\tSTATUS\tBENEFICIARY") //nolint:errcheck
+	for _, s := range subscriptions {
+		quantity := "-"
+		if s.Quantity > 0 {
+			quantity = fmt.Sprint(s.Quantity)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.Name, s.OfferID, s.PlanID, quantity, //nolint:errcheck
+			s.SaasSubscriptionStatus, firstNonEmpty(s.Beneficiary.EmailID, s.Beneficiary.TenantID, "-"))
+	}
+	w.Flush() //nolint:errcheck
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// formatSaaSOperations renders the operations of a SaaS subscription as a table
+func formatSaaSOperations(operations []SaaSOperation) string {
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tACTION\tPLAN\tQUANTITY\tSTATUS\tTIMESTAMP") //nolint:errcheck
+	for _, o := range operations {
+		quantity := "-"
+		if o.Quantity > 0 {
+			quantity = fmt.Sprint(o.Quantity)
+		}
+		timestamp := "-"
+		if !o.TimeStamp.IsZero() {
+			timestamp = o.TimeStamp.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", o.ID, o.Action, o.PlanID, quantity, o.Status, timestamp) //nolint:errcheck
+	}
+	w.Flush() //nolint:errcheck
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// GetFlagSet returns the flag set for the saas command
+func (c commandSaaSConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandSaaSConfig fetches the command saas config
+func fetchCommandSaaSConfig() commandSaaSConfig {
+	flagSet := flag.NewFlagSet(commands.SaaS, flag.ContinueOnError)
+	bindRetryFlags(flagSet)
+	return commandSaaSConfig{
+		SaaSOpts: struct {
+			Plan        *string
+			Quantity    *int
+			OperationID *string
+			Status      *string
+		}{
+			Plan:        flagSet.String("plan", "", "plan id to activate the subscription on"),
+			Quantity:    flagSet.Int("quantity", 0, "number of seats of per user plans"),
+			OperationID: flagSet.String("op", "", "provide an operation id of the subscription"),
+			Status:      flagSet.String("status", SaaSOperationSuccess, "outcome of the operation Success|Failure"),
+		},
+		FlagSet: flagSet,
+	}
+}
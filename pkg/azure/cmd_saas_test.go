@@ -0,0 +1,226 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// stubFulfillmentServer serves the SaaS fulfillment endpoints used by the saas command
+func stubFulfillmentServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != saasAPIVersion {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":"BadRequest","message":"invalid api-version"}}`)
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/api/saas/subscriptions")
+		switch {
+		case r.Method == http.MethodPost && path == "/resolve":
+			if r.Header.Get("x-ms-marketplace-token") != "purchase-token" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"code":"BadArgument","message":"token is invalid"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"id": "sub-1", "subscriptionName": "Contoso", "offerId": "offer-1", "planId": "gold", "quantity": 5,
+				"subscription": {"id": "sub-1", "saasSubscriptionStatus": "PendingFulfillmentStart"}}`)
+		case r.Method == http.MethodGet && path == "" && r.URL.Query().Get("continuationToken") == "":
+			fmt.Fprintf(w, `{"subscriptions": [{"id": "sub-1", "name": "Contoso", "offerId": "offer-1", "planId": "gold", "quantity": 5,
+				"saasSubscriptionStatus": "Subscribed", "beneficiary": {"emailId": "admin@contoso.com"}}],
+				"@nextLink": "http://%s/api/saas/subscriptions?continuationToken=next&api-version=%s"}`, r.Host, saasAPIVersion)
+		case r.Method == http.MethodGet && path == "":
+			fmt.Fprint(w, `{"subscriptions": [{"id": "sub-2", "name": "Fabrikam", "offerId": "offer-1", "planId": "silver",
+				"saasSubscriptionStatus": "PendingFulfillmentStart", "beneficiary": {"tenantId": "tenant-2"}}]}`)
+		case r.Method == http.MethodGet && path == "/sub-1":
+			fmt.Fprint(w, `{"id": "sub-1", "name": "Contoso", "planId": "gold", "saasSubscriptionStatus": "Subscribed"}`)
+		case r.Method == http.MethodPost && path == "/sub-1/activate":
+			var body saasActivation
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.PlanID != "gold" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"code":"BadArgument","message":"plan mismatch"}}`)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && path == "/sub-1/operations":
+			fmt.Fprint(w, `{"operations": [{"id": "op-1", "subscriptionId": "sub-1", "planId": "platinum", "action": "ChangePlan",
+				"timeStamp": "2026-01-01T10:00:00Z", "status": "InProgress"}]}`)
+		case r.Method == http.MethodGet && path == "/sub-2/operations":
+			fmt.Fprint(w, `{"operations": []}`)
+		case r.Method == http.MethodPatch && path == "/sub-1/operations/op-1":
+			b, _ := io.ReadAll(r.Body)
+			if string(b) != `{"status":"Success"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"EntityNotFound","message":"subscription not found"}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func useStubFulfillmentServer(t *testing.T, server *httptest.Server) {
+	instance := saasInstance
+	t.Cleanup(func() { saasInstance = instance })
+	saasInstance = &AZService{BaseURL: server.URL, httpClient: server.Client()}
+}
+
+func Test_commandSaaSConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	useStubFulfillmentServer(t, stubFulfillmentServer(t))
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "resolve purchase token",
+			args: []string{"resolve", "purchase-token"},
+			want: "resolved subscription \n{\n \"id\": \"sub-1\",\n \"subscriptionName\": \"Contoso\"",
+		},
+		{
+			name: "resolve invalid token",
+			args: []string{"resolve", "expired-token"},
+			want: "marketplace returned 400 for POST /api/saas/subscriptions/resolve",
+		},
+		{
+			name: "resolve without token",
+			args: []string{"resolve"},
+			want: "marketplace token cannot be empty",
+		},
+		{
+			name: "list follows the next link",
+			args: []string{"list"},
+			want: "2 SaaS subscription(s)\nID     NAME      OFFER    PLAN    QUANTITY  STATUS                   BENEFICIARY\nsub-1  Contoso   offer-1  gold    5         Subscribed               admin@contoso.com\nsub-2  Fabrikam  offer-1  silver  -         PendingFulfillmentStart  tenant-2",
+		},
+		{
+			name: "get subscription",
+			args: []string{"get", "sub-1"},
+			want: "subscription \n{\n \"id\": \"sub-1\",\n \"name\": \"Contoso\"",
+		},
+		{
+			name: "get unknown subscription",
+			args: []string{"get", "sub-3"},
+			want: "marketplace returned 404 for GET /api/saas/subscriptions/sub-3",
+		},
+		{
+			name: "get without subscription id",
+			args: []string{"get"},
+			want: "subscription id cannot be empty",
+		},
+		{
+			name: "activate subscription",
+			args: []string{"activate", "sub-1", "-plan", "gold", "-quantity", "5"},
+			want: "subscription sub-1 activated on plan " + helpers.GreenValue("gold"),
+		},
+		{
+			name: "activate on another plan",
+			args: []string{"activate", "sub-1", "-plan", "silver"},
+			want: "failed to activate the subscription",
+		},
+		{
+			name: "activate without plan",
+			args: []string{"activate", "sub-1"},
+			want: "plan cannot be empty",
+		},
+		{
+			name: "operations of subscription",
+			args: []string{"operations", "sub-1"},
+			want: "1 pending operation(s) of sub-1\nID    ACTION      PLAN      QUANTITY  STATUS      TIMESTAMP\nop-1  ChangePlan  platinum  -         InProgress  2026-01-01T10:00:00Z",
+		},
+		{
+			name: "no pending operations",
+			args: []string{"operations", "sub-2"},
+			want: "no pending operation for the subscription sub-2",
+		},
+		{
+			name: "update operation",
+			args: []string{"update-operation", "sub-1", "-op", "op-1"},
+			want: "operation op-1 of sub-1 updated to " + helpers.GreenValue("Success"),
+		},
+		{
+			name: "update operation with invalid status",
+			args: []string{"update-operation", "sub-1", "-op", "op-1", "-status", "Done"},
+			want: "invalid status Done, use Success|Failure",
+		},
+		{
+			name: "update operation without operation id",
+			args: []string{"update-operation", "sub-1"},
+			want: "operation id cannot be empty",
+		},
+		{
+			name: "invalid action",
+			args: []string{"delete", "sub-1"},
+			want: "provide a valid action resolve|list|get|activate|operations|update-operation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandSaaSConfig
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandSaaSConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAZConfig_saasConfig(t *testing.T) {
+	for _, key := range []string{"AZURE_SAAS_CLIENT_ID", "AZURE_SAAS_CLIENT_SECRET", "AZURE_SAAS_TENANT_ID", "AZURE_SAAS_AUTH_METHOD"} {
+		t.Setenv(key, "")
+	}
+	main := AZConfig{ClientID: "main-app", TenantID: "main-tenant", TokenURL: "https://login.example/token", Auth: AuthConfig{Method: SecretAuthMethod}}
+
+	if _, err := main.saasConfig(); err == nil || !strings.Contains(err.Error(), "AZURE_SAAS_CLIENT_ID is required") {
+		t.Errorf("saasConfig() error = %v, want missing client id", err)
+	}
+
+	t.Setenv("AZURE_SAAS_CLIENT_ID", "saas-app")
+	t.Setenv("AZURE_SAAS_CLIENT_SECRET", "saas-secret")
+	saas, err := main.saasConfig()
+	if err != nil {
+		t.Fatalf("saasConfig() error = %v", err)
+	}
+	if saas.ClientID != "saas-app" || saas.ClientSecret != "saas-secret" || saas.TenantID != "main-tenant" || saas.TokenURL != "" {
+		t.Errorf("saasConfig() = %+v, want the saas app in the main tenant", saas)
+	}
+	if main.ClientID != "main-app" {
+		t.Errorf("saasConfig() modified the main config %+v", main)
+	}
+
+	t.Setenv("AZURE_SAAS_CLIENT_ID", "")
+	t.Setenv("AZURE_SAAS_AUTH_METHOD", ManagedIdentityAuthMethod)
+	if _, err := main.saasConfig(); err != nil {
+		t.Errorf("saasConfig() error = %v, want managed identity without client id", err)
+	}
+}
+
+func TestListSaaSSubscriptions_foreignNextLink(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent to the foreign next link %s", r.URL)
+	}))
+	t.Cleanup(foreign.Close)
+
+	for _, nextLink := range []string{foreign.URL + "/api/saas/subscriptions", "https://marketplaceapi.attacker.example/api/saas/subscriptions"} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"subscriptions": [], "@nextLink": %q}`, nextLink)
+		}))
+		t.Cleanup(server.Close)
+		useStubFulfillmentServer(t, server)
+
+		if _, err := ListSaaSSubscriptions(); err == nil || !strings.Contains(err.Error(), "does not match the marketplace endpoint") {
+			t.Errorf("ListSaaSSubscriptions() error = %v, want next link rejected", err)
+		}
+	}
+}
@@ -4,21 +4,25 @@ import (
 	"flag"
 	"fmt"
 
+	"github.com/aryannr97/unfold/pkg/cache"
 	"github.com/aryannr97/unfold/pkg/commands"
 )
 
 // commandSearchConfig represents the configuration for the search command
 type commandSearchConfig struct {
 	AudienceOpts struct {
-		ID    *string
-		Offer *string
+		ID        *string
+		Offer     *string
+		AllOffers *bool
 	}
 	FlagSet *flag.FlagSet
 }
 
 // Execute executes the search command
 func (c commandSearchConfig) Execute() string {
-	if *c.AudienceOpts.ID != "" {
+	if *c.AudienceOpts.ID != "" && *c.AudienceOpts.AllOffers {
+		return fmt.Sprintf("[unfold] %s", formatSearchMatrix(*c.AudienceOpts.ID, SearchAllOffers(*c.AudienceOpts.ID)))
+	} else if *c.AudienceOpts.ID != "" {
 		return fmt.Sprintf("[unfold] %s", Search(*c.AudienceOpts.ID, config.Offers[*c.AudienceOpts.Offer].ProductDurableID))
 	}
 	return "[unfold] id cannot be empty"
@@ -32,13 +36,17 @@ func (c commandSearchConfig) GetFlagSet() *flag.FlagSet {
 // fetchCommandSearchConfig fetches the command search config
 func fetchCommandSearchConfig() commandSearchConfig {
 	flagSet := flag.NewFlagSet(commands.Search, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindRetryFlags(flagSet)
 	return commandSearchConfig{
 		AudienceOpts: struct {
-			ID    *string
-			Offer *string
+			ID        *string
+			Offer     *string
+			AllOffers *bool
 		}{
-			ID:    flagSet.String("id", "", "used to search resource in private audience of offer"),
-			Offer: flagSet.String("o", "", "provide a valid azure offer name"),
+			ID:        flagSet.String("id", "", "used to search resource in private audience of offer"),
+			Offer:     flagSet.String("o", "", "provide a valid azure offer name"),
+			AllOffers: flagSet.Bool("all-offers", false, "search the private audiences of every configured offer"),
 		},
 		FlagSet: flagSet,
 	}
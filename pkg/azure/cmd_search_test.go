@@ -72,6 +72,34 @@ func Test_commandSearchConfig_Execute(t *testing.T) {
 			httpCallError: nil,
 			want:          "offer cannot be empty",
 		},
+		{
+			name: "search by id across all offers",
+			args: []string{"-id", "0b37927b-359e-4a60-8aac-67f88409ac5a", "--all-offers"},
+			transport: map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/12345678-1234-1234-1234-123456789abc": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resources": [{"$schema": "https://schema.mp.microsoft.com/schema/plan/2022-03-01-preview2", "id": "plan/12345678-1234-1234-1234-123456789abc/aaaa", "identity": {"externalId": "gold"}}, {"$schema": "https://schema.mp.microsoft.com/schema/price-and-availability-plan/2022-03-01-preview2", "plan": "plan/12345678-1234-1234-1234-123456789abc/aaaa", "privateAudiences": [{"id": "0b37927b-359e-4a60-8aac-67f88409ac5a", "type": "tenant"}]}]}`)),
+				},
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resources": [{"plan": "plan/87654321-4321-4321-4321-210987654321/bbbb", "privateAudiences": [{"id": "12345678-1234-1234-1234-123456789abd", "type": "subscription"}]}]}`)),
+				},
+			},
+			httpCallError: nil,
+			want:          fmt.Sprintf("offer-1     gold  %s\ntest-offer  -     %s", helpers.GreenValue("tenant"), helpers.RedValue(`marketplace returned 404 with response { "error": "not found" }`)),
+		},
+		{
+			name: "search by id across all offers not found",
+			args: []string{"-id", "0b37927b-359e-4a60-8aac-67f88409ac5b", "-all-offers"},
+			transport: map[string]*http.Response{
+				"https://graph.microsoft.com/rp/product-ingestion/resource-tree/product/87654321-4321-4321-4321-210987654321": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"resources": [{"plan": "plan/87654321-4321-4321-4321-210987654321/bbbb", "privateAudiences": [{"id": "12345678-1234-1234-1234-123456789abd", "type": "subscription"}]}]}`)),
+				},
+			},
+			httpCallError: nil,
+			want:          fmt.Sprintf("given id %s in private audience of 3 offer(s)", helpers.RedValue("not found")),
+		},
 		{
 			name:          "id is empty",
 			args:          []string{"-o", "offer-1"},
@@ -84,7 +112,7 @@ func Test_commandSearchConfig_Execute(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			c := NewCommandModule().CommandSearchConfig
 			c.GetFlagSet().Parse(tt.args)
-			instances[graphResourceIndex].httpClient = &http.Client{
+			instances[ingestionEndpoint].httpClient = &http.Client{
 				Transport: &MockHTTPRoundTripper{
 					Transport: tt.transport,
 					Error:     tt.httpCallError,
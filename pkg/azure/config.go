@@ -7,38 +7,42 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"golang.org/x/oauth2"
-	oauth2cc "golang.org/x/oauth2/clientcredentials"
 	"gopkg.in/yaml.v2"
 )
 
 const (
-	// Resource indices refers to the fixed order of different
-	// microsoft/azure domains added under azure.resources in services.yml.
-	// Order of the resources is critical and should not be changed.
-	// Note: Declare new constant variables after the resource indices.
-	managementResourceIndex = iota
-	graphResourceIndex      = iota
-
 	// ProviderShortName refers to the shortname field of the provider table for Azure
 	ProviderShortName = "MSAZ"
 	// AddMode is the name for mode operation add
 	AddMode = "add"
 	// RemoveMode is the name for mode operation remove
 	RemoveMode = "remove"
+
+	// anonClientTimeout is the timeout of unauthenticated calls
+	anonClientTimeout = 30 * time.Second
+
+	// plansCacheTTL is the duration for which the plans of an offer are cached on disk
+	plansCacheTTL = time.Hour
+	// resourceTreeCacheTTL is the duration for which the resource tree of an offer is cached on disk
+	resourceTreeCacheTTL = 5 * time.Minute
 )
 
 // AZConfig contains azure service credentials
 type AZConfig struct {
 	ClientID       string                 `json:"clientID" yaml:"clientID"`
 	ClientSecret   string                 `json:"clientSecret" yaml:"clientSecret"`
+	TenantID       string                 `json:"tenantID" yaml:"tenantID"`
 	TokenURL       string                 `json:"tokenURL" yaml:"tokenURL"`
-	Resources      []string               `json:"resources" yaml:"resources"`
+	Cloud          string                 `json:"cloud" yaml:"cloud"`
+	Endpoints      Endpoints              `json:"endpoints" yaml:"endpoints"`
 	Publisher      string                 `json:"publisher" yaml:"publisher"`
 	TestOfferName  string                 `json:"testOfferName" yaml:"testOfferName"`
 	IdentityCAFile string                 `json:"identityCAFile" yaml:"identityCAFile"`
 	Offers         map[string]OfferConfig `json:"offers" yaml:"offers"`
+	Auth           AuthConfig             `json:"auth" yaml:"auth"`
 }
 
 // OfferConfig represents the offer config offer_name and product_durable_id
@@ -54,41 +58,41 @@ type AZService struct {
 	Publisher       string
 	IdentityCACerts *x509.CertPool
 	httpClient      *http.Client
+	// anonClient makes unauthenticated calls, e.g. to discover the tenant of a subscription
+	anonClient *http.Client
 }
 
 // config is package var to store azure service credentials from yaml
 var config = AZConfig{
-	ClientID:     os.Getenv("AZURE_CLIENT_ID"),
-	ClientSecret: os.Getenv("AZURE_CLIENT_SECRET"),
-	TokenURL:     fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/token", os.Getenv("AZURE_TENANT_ID")),
-	Resources: []string{
-		"https://management.azure.com",
-		"https://graph.microsoft.com",
-	},
+	ClientID:       os.Getenv("AZURE_CLIENT_ID"),
+	ClientSecret:   os.Getenv("AZURE_CLIENT_SECRET"),
+	TenantID:       os.Getenv("AZURE_TENANT_ID"),
+	TokenURL:       os.Getenv("AZURE_TOKEN_URL"),
+	Cloud:          PublicCloud,
+	Endpoints:      clouds[PublicCloud],
 	Publisher:      os.Getenv("AZURE_OFFERS_PUBLISHER"),
 	IdentityCAFile: os.Getenv("AZURE_CERT_FILE"),
 }
 
-// instances contains the AZService instances to call different APIs of Azure
-var instances = map[int]*AZService{
-	managementResourceIndex: nil,
-	graphResourceIndex:      nil,
+// instances contains the AZService instances to call different APIs of Azure, keyed by endpoint name
+var instances = map[string]*AZService{
+	managementEndpoint: nil,
+	ingestionEndpoint:  nil,
 }
 
-// NewService creates a new service from the AZConfig
-func (c *AZConfig) NewService(resourceIndex int) (*AZService, error) {
-	if resourceIndex >= len(c.Resources) {
-		return nil, fmt.Errorf("resourceIndex %v is exceeding available number of resources %v", resourceIndex, len(c.Resources))
+// NewService creates a new service for the named endpoint from the AZConfig
+func (c *AZConfig) NewService(name string) (*AZService, error) {
+	baseURL, err := c.endpoint(name)
+	if err != nil {
+		return nil, err
 	}
 
-	oauthConf := &oauth2cc.Config{
-		ClientID:     c.ClientID,
-		ClientSecret: c.ClientSecret,
-		TokenURL:     c.TokenURL,
-		EndpointParams: map[string][]string{
-			"resource": {c.Resources[resourceIndex]},
-		},
-		AuthStyle: oauth2.AuthStyleInParams,
+	// the oauth2 client and the token requests use the retrying transport
+	retryClient := &http.Client{Transport: newRetryTransport(nil)}
+	ctx := context.WithValue(context.TODO(), oauth2.HTTPClient, retryClient)
+	ts, err := c.tokenSource(ctx, tokenResource(name, baseURL))
+	if err != nil {
+		return nil, fmt.Errorf("unable to configure Azure %s authentication: %w", c.Auth.Method, err)
 	}
 
 	certs, err := loadCACerts(config.IdentityCAFile)
@@ -97,9 +101,10 @@ func (c *AZConfig) NewService(resourceIndex int) (*AZService, error) {
 	}
 
 	return &AZService{
-		BaseURL:         c.Resources[resourceIndex],
+		BaseURL:         baseURL,
 		Publisher:       c.Publisher,
-		httpClient:      oauthConf.Client(context.TODO()),
+		httpClient:      oauth2.NewClient(ctx, ts),
+		anonClient:      &http.Client{Timeout: anonClientTimeout, Transport: newRetryTransport(nil)},
 		IdentityCACerts: certs,
 	}, nil
 }
@@ -107,6 +112,10 @@ func (c *AZConfig) NewService(resourceIndex int) (*AZService, error) {
 // StartService starts the Azure service
 func StartService() error {
 	config.IdentityCAFile = os.Getenv("AZURE_CERT_FILE")
+	config.loadAuthConfig()
+	if err := config.loadCloudConfig(); err != nil {
+		return err
+	}
 
 	var err error
 	offers, err := os.ReadFile(os.Getenv("AZURE_OFFERS_FILE"))
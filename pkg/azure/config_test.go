@@ -2,6 +2,7 @@ package azure
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -51,15 +52,67 @@ func TestStartService(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "start service error resource index out of bounds",
+			name: "start service error invalid auth method",
 			modifyConfig: func() {
 				prepareConfig()
-				// add a new resource index in config, as iota cannot be modified
-				instances[len(config.Resources)+1] = nil
+				os.Setenv("AZURE_AUTH_METHOD", "password")
+			},
+			wantErr: true,
+		},
+		{
+			name: "start service with certificate auth method and missing certificate",
+			modifyConfig: func() {
+				prepareConfig()
+				os.Setenv("AZURE_AUTH_METHOD", CertificateAuthMethod)
+			},
+			wantErr: true,
+		},
+		{
+			name: "start service error unknown endpoint",
+			modifyConfig: func() {
+				prepareConfig()
+				instances["unknown"] = nil
+			},
+			wantErr: true,
+		},
+		{
+			name: "start service with usgov cloud",
+			modifyConfig: func() {
+				prepareConfig()
+				os.Setenv("AZURE_CLOUD", USGovCloud)
+			},
+			wantErr: false,
+		},
+		{
+			name: "start service with custom cloud",
+			modifyConfig: func() {
+				prepareConfig()
+				os.Setenv("AZURE_CLOUD", CustomCloud)
+				os.Setenv("AZURE_AUTHORITY_HOST", "http://localhost:8080")
+				os.Setenv("AZURE_MANAGEMENT_ENDPOINT", "http://localhost:8081")
+				os.Setenv("AZURE_INGESTION_ENDPOINT", "http://localhost:8082")
+			},
+			wantErr: false,
+		},
+		{
+			name: "start service error custom cloud without endpoints",
+			modifyConfig: func() {
+				prepareConfig()
+				os.Setenv("AZURE_CLOUD", CustomCloud)
+			},
+			wantErr: true,
+		},
+		{
+			name: "start service error invalid cloud",
+			modifyConfig: func() {
+				prepareConfig()
+				os.Setenv("AZURE_CLOUD", "moon")
 			},
 			wantErr: true,
 		},
 	}
+	// restore the public cloud and its endpoints so that later tests can start the service
+	defer prepareConfig()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.modifyConfig != nil {
@@ -75,9 +128,105 @@ func TestStartService(t *testing.T) {
 func prepareConfig() {
 	os.Setenv("AZURE_OFFERS_FILE", "testdata/offers_test.yml")
 	os.Setenv("AZURE_CERT_FILE", "testdata/test_ca_cert.txt")
+	os.Setenv("AZURE_AUTH_METHOD", "")
 	config.IdentityCAFile = os.Getenv("AZURE_CERT_FILE")
-	instances = map[int]*AZService{
-		managementResourceIndex: nil,
-		graphResourceIndex:      nil,
+	for _, key := range []string{"AZURE_CLOUD", "AZURE_AUTHORITY_HOST", "AZURE_MANAGEMENT_ENDPOINT", "AZURE_INGESTION_ENDPOINT", "AZURE_MARKETPLACE_ENDPOINT"} {
+		os.Unsetenv(key)
+	}
+	instances = map[string]*AZService{
+		managementEndpoint: nil,
+		ingestionEndpoint:  nil,
+	}
+}
+
+func TestAZConfig_loadCloudConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Endpoints
+		wantErr bool
+	}{
+		{
+			name: "public cloud by default",
+			want: clouds[PublicCloud],
+		},
+		{
+			name: "china cloud",
+			env:  map[string]string{"AZURE_CLOUD": "China"},
+			want: clouds[ChinaCloud],
+		},
+		{
+			name: "usgov cloud with ingestion override",
+			env:  map[string]string{"AZURE_CLOUD": USGovCloud, "AZURE_INGESTION_ENDPOINT": "https://ingestion.example.us/"},
+			want: Endpoints{
+				Authority:  "https://login.microsoftonline.us",
+				Management: "https://management.usgovcloudapi.net",
+				Ingestion:  "https://ingestion.example.us",
+			},
+		},
+		{
+			name: "custom cloud",
+			env: map[string]string{
+				"AZURE_CLOUD":               CustomCloud,
+				"AZURE_AUTHORITY_HOST":      "http://localhost:8080",
+				"AZURE_MANAGEMENT_ENDPOINT": "http://localhost:8081",
+				"AZURE_INGESTION_ENDPOINT":  "http://localhost:8082",
+			},
+			want: Endpoints{Authority: "http://localhost:8080", Management: "http://localhost:8081", Ingestion: "http://localhost:8082"},
+		},
+		{
+			name: "custom cloud with marketplace",
+			env: map[string]string{
+				"AZURE_CLOUD":                CustomCloud,
+				"AZURE_AUTHORITY_HOST":       "http://localhost:8080",
+				"AZURE_MANAGEMENT_ENDPOINT":  "http://localhost:8081",
+				"AZURE_INGESTION_ENDPOINT":   "http://localhost:8082",
+				"AZURE_MARKETPLACE_ENDPOINT": "http://localhost:8083/",
+			},
+			want: Endpoints{Authority: "http://localhost:8080", Management: "http://localhost:8081", Ingestion: "http://localhost:8082", Marketplace: "http://localhost:8083"},
+		},
+		{
+			name:    "custom cloud with missing endpoint",
+			env:     map[string]string{"AZURE_CLOUD": CustomCloud, "AZURE_AUTHORITY_HOST": "http://localhost:8080"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cloud",
+			env:     map[string]string{"AZURE_CLOUD": "moon"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"AZURE_CLOUD", "AZURE_AUTHORITY_HOST", "AZURE_MANAGEMENT_ENDPOINT", "AZURE_INGESTION_ENDPOINT", "AZURE_MARKETPLACE_ENDPOINT"} {
+				t.Setenv(key, tt.env[key])
+			}
+			c := &AZConfig{}
+			err := c.loadCloudConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadCloudConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && c.Endpoints != tt.want {
+				t.Errorf("loadCloudConfig() = %+v, want %+v", c.Endpoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestAZConfig_endpoint(t *testing.T) {
+	public := &AZConfig{Cloud: PublicCloud, Endpoints: clouds[PublicCloud]}
+	if got, err := public.endpoint(marketplaceEndpoint); err != nil || got != "https://marketplaceapi.microsoft.com" {
+		t.Errorf("endpoint(marketplace) = %v, %v, want the public marketplace endpoint", got, err)
+	}
+	if got := tokenResource(marketplaceEndpoint, "https://marketplaceapi.microsoft.com"); got != marketplaceResource {
+		t.Errorf("tokenResource(marketplace) = %v, want %v", got, marketplaceResource)
+	}
+	if got := tokenResource(ingestionEndpoint, "https://graph.microsoft.com"); got != "https://graph.microsoft.com" {
+		t.Errorf("tokenResource(ingestion) = %v, want the base url", got)
+	}
+
+	usgov := &AZConfig{Cloud: USGovCloud, Endpoints: clouds[USGovCloud]}
+	if _, err := usgov.endpoint(marketplaceEndpoint); err == nil || !strings.Contains(err.Error(), "not available in the usgov cloud") {
+		t.Errorf("endpoint(marketplace) error = %v, want not available", err)
 	}
 }
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/cache"
 	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
 )
 
 // MSGraphEnableAccount represents the enable account request body
@@ -44,62 +48,214 @@ type LoggerObj struct {
 	SyncAudienceType string `json:"syncAudienceType"`
 	AzureJobID       string `json:"azureJobID"`
 	AzureJobResult   string `json:"azureJobResult"`
+	// UnchangedPlans are the plans whose private audience already was as requested
+	UnchangedPlans []string `json:"unchangedPlans,omitempty"`
 }
 
 // MakeConfigurationRequest decides type of audience to be used for syncing and make request to Azure
 func MakeConfigurationRequest(image, id, audType, mode string) string {
-	loggerObj := LoggerObj{}
+	loggerObj, err := ConfigurePrivateAudience(image, id, audType, mode)
+	if err != nil {
+		return err.Error()
+	}
+
+	b, _ := json.MarshalIndent(loggerObj, "", " ")
+
+	return fmt.Sprintf("configure response \n%v", string(b))
+}
+
+// ConfigurePrivateAudience adds (mode add) or removes (mode remove) the tenant or subscription
+// to the private audience of all plans of the offer and returns the summary of the submitted job
+func ConfigurePrivateAudience(image, id, audType, mode string) (*LoggerObj, error) {
+	op, err := PlanConfiguration(image, id, audType, mode)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyPlannedConfiguration(*op)
+}
 
-	audienceList := []MSProperty{}
+// PlanConfiguration resolves the product and plans of the offer into the operation
+// adding or removing the tenant or subscription to their private audience
+func PlanConfiguration(image, id, audType, mode string) (*plan.Operation, error) {
+	offer, ok := config.Offers[image]
+	if !ok {
+		return nil, fmt.Errorf("offer %s is not configured", image)
+	}
+
+	audienceType := "subscription"
 	if strings.EqualFold(audType, "tenant") {
-		loggerObj.TenantID = id
-		loggerObj.SyncAudienceType = "tenant"
-		audienceList = append(audienceList, MSProperty{
-			Type: "tenant",
-			ID:   id,
-		})
-	} else {
-		loggerObj.SubscriptionID = id
-		loggerObj.SyncAudienceType = "subscription"
-		audienceList = append(audienceList, MSProperty{
-			Type: "subscription",
-			ID:   id,
-		})
+		audienceType = "tenant"
 	}
 
 	// fetch all plans for offer/image
-	plans, httpErr := getPlans(config.Offers[image].ProductDurableID)
-	if httpErr != nil {
-		return httpErr.Error()
+	plans, err := getPlans(offer.ProductDurableID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan.Operation{
+		Kind:             plan.AzurePrivateAudience,
+		Mode:             mode,
+		Offer:            image,
+		ProductDurableID: offer.ProductDurableID,
+		PlanIDs:          plans,
+		AudienceType:     audienceType,
+		AudienceID:       id,
+	}, nil
+}
+
+// ValidatePlannedConfiguration checks the offer still resolves to the product and plans of the operation
+func ValidatePlannedConfiguration(op plan.Operation) error {
+	offer, ok := config.Offers[op.Offer]
+	if !ok {
+		return fmt.Errorf("offer %s is not configured", op.Offer)
+	}
+	if offer.ProductDurableID != op.ProductDurableID {
+		return fmt.Errorf("offer %s now resolves to product %s instead of %s", op.Offer, offer.ProductDurableID, op.ProductDurableID)
+	}
+
+	// the plans may have changed since the plan was written, bypass the cache
+	cache.Delete(plansCacheKey(op.ProductDurableID))
+	plans, err := getPlans(op.ProductDurableID)
+	if err != nil {
+		return err
+	}
+	current, planned := slices.Sorted(slices.Values(plans)), slices.Sorted(slices.Values(op.PlanIDs))
+	if !slices.Equal(current, planned) {
+		return fmt.Errorf("the plans of offer %s changed from %s to %s", op.Offer, strings.Join(planned, ","), strings.Join(current, ","))
+	}
+	return nil
+}
+
+// PlanUndo checks the private audience of the plans changed by the executed operation is still as
+// the operation left it and returns the operation reverting it on those plans. An operation that
+// did not change any plan, e.g. adding an audience already present, has nothing to undo.
+func PlanUndo(op plan.Operation) (*plan.Operation, error) {
+	changed := slices.DeleteFunc(slices.Clone(op.PlanIDs), func(planID string) bool { return slices.Contains(op.Unchanged, planID) })
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("the operation did not change the private audience of any plan of %s, nothing to undo", op.Offer)
+	}
+
+	if err := ValidatePlannedConfiguration(op); err != nil {
+		return nil, err
+	}
+
+	// the private audiences may have changed since the resource tree was cached
+	cache.Delete(resourceTreeCacheKey(op.ProductDurableID))
+	present, err := privateAudiencePlans(op.ProductDurableID, op.AudienceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, planID := range changed {
+		switch {
+		case op.Mode == plan.AddMode && !present[planID]:
+			return nil, fmt.Errorf("%s is no longer in the private audience of %s", op.AudienceID, planID)
+		case op.Mode == plan.RemoveMode && present[planID]:
+			return nil, fmt.Errorf("%s is in the private audience of %s again", op.AudienceID, planID)
+		}
 	}
 
-	reqBody := prepareRequestBody(image, plans, audienceList, mode)
+	undo := op
+	undo.PlanIDs, undo.Unchanged = changed, nil
+	undo.Mode = plan.RemoveMode
+	if op.Mode == plan.RemoveMode {
+		undo.Mode = plan.AddMode
+	}
+	return &undo, nil
+}
+
+// privateAudiencePlans returns the plans of the product whose private audience contains the audience
+func privateAudiencePlans(productID, audienceID string) (map[string]bool, error) {
+	res, err := getResourceTree(productID)
+	if err != nil {
+		return nil, err
+	}
+	present := map[string]bool{}
+	for _, obj := range res.Resources {
+		if slices.ContainsFunc(obj.PrivateAudiences, func(a PrivateAudience) bool { return strings.EqualFold(a.ID, audienceID) }) {
+			present[obj.Plan] = true
+		}
+	}
+	return present, nil
+}
+
+// recordUnchangedPlans sets the plans of the operation whose private audience already is as requested, read through the
+// on-disk cache of the resource tree. They are only used by undo, so the plans are left unknown when the tree cannot be read.
+func recordUnchangedPlans(op *plan.Operation) {
+	op.Unchanged = nil
+	present, err := privateAudiencePlans(op.ProductDurableID, op.AudienceID)
+	if err != nil {
+		return
+	}
+	for _, planID := range op.PlanIDs {
+		if present[planID] == (op.Mode == plan.AddMode) {
+			op.Unchanged = append(op.Unchanged, planID)
+		}
+	}
+}
+
+// ApplyPlannedConfiguration submits the private audience change of the operation, exactly for its product and plans.
+// The plans already in the requested state are recorded with the operation in the audit log.
+func ApplyPlannedConfiguration(op plan.Operation) (*LoggerObj, error) {
+	recordUnchangedPlans(&op)
+
+	loggerObj := LoggerObj{SyncAudienceType: op.AudienceType}
+	if op.AudienceType == "tenant" {
+		loggerObj.TenantID = op.AudienceID
+	} else {
+		loggerObj.SubscriptionID = op.AudienceID
+	}
+	audienceList := []MSProperty{{Type: op.AudienceType, ID: op.AudienceID}}
+
+	reqBody := prepareRequestBody(op.ProductDurableID, op.PlanIDs, audienceList, op.Mode)
 
 	// make request to Azure
 	azureJob, err := configurePrivateAudienceAPI(reqBody)
+	entry := audit.Entry{
+		Action:    "azure private-audience " + op.Mode,
+		Targets:   append([]string{op.AudienceID, op.Offer, op.ProductDurableID}, op.PlanIDs...),
+		Operation: &op,
+	}
 	if err != nil {
-		return err.Error()
+		audit.Record(entry, err)
+		return nil, err
 	}
+	entry.AzureJobID = azureJob.JobID
+	audit.Record(entry, nil)
+
+	// The private audiences of the offer have changed, drop the cached resource tree
+	cache.Delete(resourceTreeCacheKey(op.ProductDurableID))
+
+	recordJob(JobRecord{
+		JobID:        azureJob.JobID,
+		Offer:        op.Offer,
+		ProductID:    op.ProductDurableID,
+		Plans:        op.PlanIDs,
+		AudienceIDs:  []string{op.AudienceID},
+		AudienceType: op.AudienceType,
+		Mode:         op.Mode,
+		JobStatus:    azureJob.JobStatus,
+		JobResult:    azureJob.JobResult,
+	})
 
 	loggerObj.AzureJobID = azureJob.JobID
 	loggerObj.AzureJobResult = azureJob.JobResult
+	loggerObj.UnchangedPlans = op.Unchanged
 
-	b, _ := json.MarshalIndent(loggerObj, "", " ")
-
-	return fmt.Sprintf("configure response \n%v", string(b))
+	return &loggerObj, nil
 }
 
 // prepareRequestBody returns requestBody to be used for syncing private audience
-func prepareRequestBody(image string, plans []string, audienceList []MSProperty, mode string) MSGraphEnableAccount {
+func prepareRequestBody(productID string, plans []string, audienceList []MSProperty, mode string) MSGraphEnableAccount {
 	body := MSGraphEnableAccount{
-		Schema:    "https://schema.mp.microsoft.com/schema/configure/2022-03-01-preview2",
+		Schema:    configureSchema,
 		Resources: []MSResource{},
 	}
 
 	for _, planID := range plans {
 		resource := MSResource{
 			Schema:  "https://schema.mp.microsoft.com/schema/price-and-availability-update-private-audiences/2022-03-01-preview2",
-			Product: "product/" + config.Offers[image].ProductDurableID,
+			Product: "product/" + productID,
 			Plan:    planID,
 			PrivateAudiences: MSPrivateAudience{
 				Add:    audienceList,
@@ -120,17 +276,18 @@ func prepareRequestBody(image string, plans []string, audienceList []MSProperty,
 	return body
 }
 
-// configurePrivateAudienceAPI makes an actual API call to Azure for syncing private audience
-func configurePrivateAudienceAPI(reqBody MSGraphEnableAccount) (*MSEnableAccountsRes, error) {
+// configurePrivateAudienceAPI makes an actual API call to Azure for syncing private audience,
+// or any other configure request of the product ingestion API such as the preview audience.
+func configurePrivateAudienceAPI(reqBody any) (*MSEnableAccountsRes, error) {
 	b, _ := json.Marshal(reqBody)
 
 	reqURL := "/rp/product-ingestion/configure?$version=2022-03-01-preview2"
 
 	body := bytes.NewBuffer(b)
 
-	url := instances[graphResourceIndex].BaseURL + reqURL
+	url := instances[ingestionEndpoint].BaseURL + reqURL
 
-	resp, err := instances[graphResourceIndex].httpClient.Post(url, "application/json", body)
+	resp, err := instances[ingestionEndpoint].httpClient.Post(url, "application/json", body)
 	if err != nil {
 		return nil, err
 	}
@@ -165,10 +322,15 @@ type Resource struct {
 
 // getPlans return unique planIDs associated with product durable id of an offer/image.
 func getPlans(productID string) ([]string, error) {
+	var ids []string
+	if cache.Get(plansCacheKey(productID), &ids) {
+		return ids, nil
+	}
+
 	reqURL := fmt.Sprintf("/rp/product-ingestion/plan?product=product/%s&$version=2022-03-01-preview2", productID)
-	url := instances[graphResourceIndex].BaseURL + reqURL
+	url := instances[ingestionEndpoint].BaseURL + reqURL
 
-	resp, err := instances[graphResourceIndex].httpClient.Get(url)
+	resp, err := instances[ingestionEndpoint].httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -178,7 +340,6 @@ func getPlans(productID string) ([]string, error) {
 	switch resp.StatusCode {
 	case http.StatusOK:
 		var res Plans
-		var ids []string
 		err := json.NewDecoder(resp.Body).Decode(&res)
 		if err != nil {
 			return nil, fmt.Errorf("json decode %s", err.Error())
@@ -188,9 +349,15 @@ func getPlans(productID string) ([]string, error) {
 			ids = append(ids, plan.ID)
 		}
 
+		cache.Set(plansCacheKey(productID), ids, plansCacheTTL)
 		return ids, nil
 	default:
 		b, _ := json.Marshal(resp.Body)
 		return nil, fmt.Errorf("marketplace returned %v for getPlans with response %v", resp.StatusCode, string(b))
 	}
 }
+
+// plansCacheKey returns the on-disk cache key for the plans of the product
+func plansCacheKey(productID string) string {
+	return "azure/plans/" + productID
+}
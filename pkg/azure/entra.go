@@ -0,0 +1,361 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// graphVersion is the Microsoft Graph API version of the directory calls
+	graphVersion = "/v1.0"
+
+	// entraGroupCacheTTL is the duration for which the object id of a group name is cached on disk
+	entraGroupCacheTTL = time.Hour
+
+	// DirectMembership is a member added to the group itself
+	DirectMembership = "direct"
+	// TransitiveMembership is a member of a nested group of the group
+	TransitiveMembership = "transitive"
+)
+
+var (
+	// objectIDRegex matches a directory object id
+	objectIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// ErrEntraGroupNotFound is returned when no Entra group matches the name or object id
+	ErrEntraGroupNotFound = errors.New("entra group not found")
+	// errGraphNotFound is returned by graphRequest when the directory object does not exist
+	errGraphNotFound = errors.New("graph object not found")
+)
+
+// EntraGroup represents a Microsoft Entra ID group
+type EntraGroup struct {
+	ID              string   `json:"id"`
+	DisplayName     string   `json:"displayName"`
+	Description     string   `json:"description,omitempty"`
+	Mail            string   `json:"mail,omitempty"`
+	MailNickname    string   `json:"mailNickname,omitempty"`
+	MailEnabled     bool     `json:"mailEnabled"`
+	SecurityEnabled bool     `json:"securityEnabled"`
+	GroupTypes      []string `json:"groupTypes"`
+	MembershipRule  string   `json:"membershipRule,omitempty"`
+}
+
+// EntraMembership represents the membership of a user in an Entra group
+type EntraMembership struct {
+	GroupID string
+	UserID  string
+	// UserPrincipalName is only set on the listed memberships
+	UserPrincipalName string
+	// Type is direct or transitive, empty when the user is not a member
+	Type string
+}
+
+// entraGroups represents the groups returned by a filtered Graph query
+type entraGroups struct {
+	Value []EntraGroup `json:"value"`
+}
+
+// entraUser represents a user listed in a group
+type entraUser struct {
+	ID                string `json:"id"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+// entraUsers represents a page of the users returned by a Graph query
+type entraUsers struct {
+	Value    []entraUser `json:"value"`
+	NextLink string      `json:"@odata.nextLink"`
+}
+
+// entraDirectoryObjects represents the directory objects returned by a filtered Graph query
+type entraDirectoryObjects struct {
+	Value []struct {
+		ID string `json:"id"`
+	} `json:"value"`
+}
+
+// graphRequest calls the Microsoft Graph directory API and decodes the response into out when given.
+// Partner Center product ingestion is served by Graph, so the ingestion service makes the directory calls.
+// The path is relative to the Graph version unless it is an absolute next link, which must
+// point to the ingestion endpoint as the authorization token is sent along.
+func graphRequest(method, path string, body any, header http.Header, out any) error {
+	baseURL := instances[ingestionEndpoint].BaseURL
+	reqURL := baseURL + graphVersion + path
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		base, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+			return fmt.Errorf("next link host %s://%s does not match the graph endpoint %s", u.Scheme, u.Host, baseURL)
+		}
+		reqURL = path
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, reqURL, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := instances[ingestionEndpoint].httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errGraphNotFound
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("graph returned %v for %s %s with response %v", resp.StatusCode, method, req.URL.Path, helpers.GetErrorResponseBody(b))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("json decode %s", err.Error())
+	}
+	return nil
+}
+
+// GetEntraGroup returns the group identified by its object id, mail or display name
+func GetEntraGroup(group string) (*EntraGroup, error) {
+	if group == "" {
+		return nil, errors.New("group cannot be empty")
+	}
+
+	id := group
+	if !objectIDRegex.MatchString(group) {
+		var err error
+		if id, err = resolveEntraGroupID(group); err != nil {
+			return nil, err
+		}
+	}
+
+	var res EntraGroup
+	if err := graphRequest(http.MethodGet, "/groups/"+id, nil, nil, &res); err != nil {
+		if errors.Is(err, errGraphNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrEntraGroupNotFound, group)
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// resolveEntraGroupID returns the object id of the group with the mail or display name, from the on-disk cache if still valid
+func resolveEntraGroupID(name string) (string, error) {
+	key := entraGroupCacheKey(name)
+	var id string
+	if cache.Get(key, &id) {
+		return id, nil
+	}
+
+	field := "displayName"
+	if strings.Contains(name, "@") {
+		field = "mail"
+	}
+	query := url.Values{}
+	query.Set("$filter", fmt.Sprintf("%s eq '%s'", field, strings.ReplaceAll(name, "'", "''")))
+	query.Set("$select", "id,displayName")
+
+	var res entraGroups
+	if err := graphRequest(http.MethodGet, "/groups?"+query.Encode(), nil, nil, &res); err != nil {
+		return "", err
+	}
+
+	switch len(res.Value) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrEntraGroupNotFound, name)
+	case 1:
+		cache.Set(key, res.Value[0].ID, entraGroupCacheTTL)
+		return res.Value[0].ID, nil
+	}
+	ids := make([]string, 0, len(res.Value))
+	for _, g := range res.Value {
+		ids = append(ids, g.ID)
+	}
+	return "", fmt.Errorf("%d groups are named %s, use the object id %s", len(ids), name, strings.Join(ids, "|"))
+}
+
+// entraGroupCacheKey returns the on-disk cache key for the object id of the group name
+func entraGroupCacheKey(name string) string {
+	return "azure/entra-group/" + strings.ToLower(name)
+}
+
+// resolveEntraUserID returns the object id of the user identified by its object id or user principal name
+func resolveEntraUserID(user string) (string, error) {
+	if user == "" {
+		return "", errors.New("user cannot be empty")
+	}
+	if objectIDRegex.MatchString(user) {
+		return user, nil
+	}
+
+	var res struct {
+		ID string `json:"id"`
+	}
+	if err := graphRequest(http.MethodGet, fmt.Sprintf("/users/%s?$select=id", url.PathEscape(user)), nil, nil, &res); err != nil {
+		if errors.Is(err, errGraphNotFound) {
+			return "", fmt.Errorf("user %s not found", user)
+		}
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// CheckEntraGroupMembership reports whether the user is a direct or transitive member of the group
+func CheckEntraGroupMembership(group, user string) (*EntraMembership, error) {
+	g, err := GetEntraGroup(group)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := resolveEntraUserID(user)
+	if err != nil {
+		return nil, err
+	}
+	membership := &EntraMembership{GroupID: g.ID, UserID: userID}
+
+	// filtering the members on the id is an advanced query requiring eventual consistency
+	header := http.Header{}
+	header.Set("ConsistencyLevel", "eventual")
+	query := url.Values{}
+	query.Set("$filter", fmt.Sprintf("id eq '%s'", userID))
+	query.Set("$count", "true")
+	query.Set("$select", "id")
+
+	var direct entraDirectoryObjects
+	if err := graphRequest(http.MethodGet, fmt.Sprintf("/groups/%s/members?%s", g.ID, query.Encode()), nil, header, &direct); err != nil {
+		return nil, err
+	}
+	if len(direct.Value) > 0 {
+		membership.Type = DirectMembership
+		return membership, nil
+	}
+
+	var transitive struct {
+		Value []string `json:"value"`
+	}
+	body := map[string][]string{"groupIds": {g.ID}}
+	if err := graphRequest(http.MethodPost, fmt.Sprintf("/directoryObjects/%s/checkMemberGroups", userID), body, nil, &transitive); err != nil {
+		return nil, err
+	}
+	for _, id := range transitive.Value {
+		if strings.EqualFold(id, g.ID) {
+			membership.Type = TransitiveMembership
+		}
+	}
+	return membership, nil
+}
+
+// ListEntraGroupMembers returns the users of the group, its direct members followed by the members of its nested groups
+func ListEntraGroupMembers(group string) ([]EntraMembership, error) {
+	g, err := GetEntraGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	var memberships []EntraMembership
+	listed := map[string]bool{}
+	for _, collection := range []struct{ name, typ string }{
+		{"members", DirectMembership},
+		{"transitiveMembers", TransitiveMembership},
+	} {
+		users, err := listEntraUsers(fmt.Sprintf("/groups/%s/%s/microsoft.graph.user", g.ID, collection.name))
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			// the transitive members include the direct ones
+			if listed[u.ID] {
+				continue
+			}
+			listed[u.ID] = true
+			memberships = append(memberships, EntraMembership{GroupID: g.ID, UserID: u.ID, UserPrincipalName: u.UserPrincipalName, Type: collection.typ})
+		}
+	}
+	return memberships, nil
+}
+
+// listEntraUsers returns the users of the collection at the path, following the next links
+func listEntraUsers(path string) ([]entraUser, error) {
+	query := url.Values{}
+	query.Set("$select", "id,userPrincipalName")
+	query.Set("$top", "999")
+
+	var users []entraUser
+	for next := path + "?" + query.Encode(); next != ""; {
+		var page entraUsers
+		if err := graphRequest(http.MethodGet, next, nil, nil, &page); err != nil {
+			return nil, err
+		}
+		users = append(users, page.Value...)
+		next = page.NextLink
+	}
+	return users, nil
+}
+
+// AddMemberToEntraGroup adds the user as direct member of the group
+func AddMemberToEntraGroup(group, user string) error {
+	g, err := GetEntraGroup(group)
+	if err != nil {
+		return err
+	}
+	userID, err := resolveEntraUserID(user)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{"@odata.id": fmt.Sprintf("%s%s/directoryObjects/%s", instances[ingestionEndpoint].BaseURL, graphVersion, userID)}
+	err = graphRequest(http.MethodPost, fmt.Sprintf("/groups/%s/members/$ref", g.ID), body, nil, nil)
+	audit.Record(audit.Entry{Action: "azure entra-group add", Targets: []string{g.ID, g.DisplayName, user, userID}}, err)
+	return err
+}
+
+// RemoveMemberFromEntraGroup removes the direct membership of the user from the group
+func RemoveMemberFromEntraGroup(group, user string) error {
+	g, err := GetEntraGroup(group)
+	if err != nil {
+		return err
+	}
+	userID, err := resolveEntraUserID(user)
+	if err != nil {
+		return err
+	}
+
+	err = graphRequest(http.MethodDelete, fmt.Sprintf("/groups/%s/members/%s/$ref", g.ID, userID), nil, nil, nil)
+	if errors.Is(err, errGraphNotFound) {
+		err = fmt.Errorf("%s is not a direct member of the group %s", user, g.DisplayName)
+	}
+	audit.Record(audit.Entry{Action: "azure entra-group remove", Targets: []string{g.ID, g.DisplayName, user, userID}}, err)
+	return err
+}
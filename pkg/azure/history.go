@@ -0,0 +1,98 @@
+package azure
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/state"
+)
+
+const (
+	// jobHistoryName is the name of the state file holding the submitted jobs
+	jobHistoryName = "azure-jobs"
+	// maxJobRecords bounds the job history, the oldest jobs are dropped first
+	maxJobRecords = 1000
+)
+
+// JobRecord represents a job submitted to the product ingestion API
+type JobRecord struct {
+	JobID        string    `json:"jobId"`
+	Offer        string    `json:"offer"`
+	ProductID    string    `json:"productId"`
+	Plans        []string  `json:"plans,omitempty"`
+	AudienceIDs  []string  `json:"audienceIds,omitempty"`
+	AudienceType string    `json:"audienceType,omitempty"`
+	Mode         string    `json:"mode"`
+	SubmittedAt  time.Time `json:"submittedAt"`
+	User         string    `json:"user"`
+	JobStatus    string    `json:"jobStatus"`
+	JobResult    string    `json:"jobResult"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Running reports whether the job has not completed yet
+func (r JobRecord) Running() bool {
+	return r.JobStatus != jobStatusCompleted
+}
+
+// recordJob appends the submitted job to the local job history. The history is best effort,
+// failures to persist the job must not fail the already submitted request.
+func recordJob(record JobRecord) {
+	record.SubmittedAt = time.Now().UTC()
+	record.UpdatedAt = record.SubmittedAt
+	record.User = helpers.CurrentUser()
+
+	records := []JobRecord{}
+	state.Update(jobHistoryName, &records, func() error { //nolint:errcheck
+		records = append(records, record)
+		if len(records) > maxJobRecords {
+			records = records[len(records)-maxJobRecords:]
+		}
+		return nil
+	})
+}
+
+// ListJobs returns the jobs of the local job history, the most recent first
+func ListJobs() ([]JobRecord, error) {
+	records := []JobRecord{}
+	if err := state.Load(jobHistoryName, &records); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].SubmittedAt.After(records[j].SubmittedAt)
+	})
+	return records, nil
+}
+
+// RefreshJobs re-queries the status of the given jobs, or of all running jobs when no id is given,
+// updates the local job history and returns the refreshed records along with the failed lookups.
+func RefreshJobs(jobIDs ...string) ([]JobRecord, map[string]error, error) {
+	records := []JobRecord{}
+	refreshed := []JobRecord{}
+	failures := map[string]error{}
+
+	wanted := map[string]bool{}
+	for _, id := range jobIDs {
+		wanted[id] = true
+	}
+
+	err := state.Update(jobHistoryName, &records, func() error {
+		for i, record := range records {
+			if len(wanted) > 0 && !wanted[record.JobID] || len(wanted) == 0 && !record.Running() {
+				continue
+			}
+			res, err := fetchJobStatus(record.JobID)
+			if err != nil {
+				failures[record.JobID] = err
+				continue
+			}
+			records[i].JobStatus = res.JobStatus
+			records[i].JobResult = res.JobResult
+			records[i].UpdatedAt = time.Now().UTC()
+			refreshed = append(refreshed, records[i])
+		}
+		return nil
+	})
+	return refreshed, failures, err
+}
@@ -5,6 +5,27 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// jobStatusCompleted is the status of a job which is no longer running
+	jobStatusCompleted = "completed"
+)
+
+var (
+	// jobPollInterval is the interval between job status requests while waiting for a job
+	jobPollInterval = 10 * time.Second
+
+	// planResourceRegex matches the plan id of plan/<product-id>/<plan-id> resource ids
+	planResourceRegex = regexp.MustCompile(`(?:^|/)plan/[^/]+/([^/]+)`)
+	// guidRegex matches the subscription and tenant ids mentioned by job errors
+	guidRegex = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
 )
 
 // MSEnableAccountsRes represents the enable accounts response
@@ -12,45 +33,194 @@ type MSEnableAccountsRes struct {
 	JobID     string        `json:"jobId"`
 	JobStatus string        `json:"jobStatus"`
 	JobResult string        `json:"jobResult"`
+	JobStart  *time.Time    `json:"jobStart,omitempty"`
+	JobEnd    *time.Time    `json:"jobEnd,omitempty"`
 	Errors    []MSErrorResp `json:"errors,omitempty"`
+
+	// raw is the original payload of the job status response
+	raw []byte
 }
 
 // MSErrorResp represents the error response
 type MSErrorResp struct {
-	Code    interface{}              `json:"code"`
-	Message string                   `json:"message"`
-	Details []map[string]interface{} `json:"details"`
+	Code       interface{}              `json:"code"`
+	Message    string                   `json:"message"`
+	ResourceID string                   `json:"resourceId,omitempty"`
+	Details    []map[string]interface{} `json:"details"`
+}
+
+// GetAzureJobStatus calls the MS service to get the status of the Job,
+// rendered as a report or as the original payload when raw is set.
+func GetAzureJobStatus(jobID string, raw bool) string {
+	res, err := fetchJobStatus(jobID)
+	if err != nil {
+		return err.Error()
+	}
+	if raw {
+		return fmt.Sprintf("job status response \n%s", string(res.raw))
+	}
+	return formatJobStatus(res)
+}
+
+// WaitForJob polls the status of the job until it is completed or the timeout expires
+func WaitForJob(jobID string, timeout time.Duration) (*MSEnableAccountsRes, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		res, err := fetchJobStatus(jobID)
+		if err != nil {
+			return nil, err
+		}
+		if res.JobStatus == jobStatusCompleted {
+			return res, nil
+		}
+		if time.Now().Add(jobPollInterval).After(deadline) {
+			return res, fmt.Errorf("job %s is still %s after %s", jobID, res.JobStatus, timeout)
+		}
+		time.Sleep(jobPollInterval)
+	}
 }
 
-// GetAzureJobStatus calls the MS service to get the status of the Job
-func GetAzureJobStatus(jobID string) string {
+// CheckJobCompleted returns an error while the job is still running, a change undone before its job
+// completed would be reverted by a job racing with it on the same plans
+func CheckJobCompleted(jobID string) error {
+	if jobID == "" {
+		return nil
+	}
+	res, err := fetchJobStatus(jobID)
+	if err != nil {
+		return err
+	}
+	if res.JobStatus != jobStatusCompleted {
+		return fmt.Errorf("job %s still running with status %s, retry once it completed", jobID, res.JobStatus)
+	}
+	return nil
+}
+
+// fetchJobStatus calls the MS service to get the status of the Job
+func fetchJobStatus(jobID string) (*MSEnableAccountsRes, error) {
 	reqURL := fmt.Sprintf("/rp/product-ingestion/configure/%s/status?$version=2022-07-01", jobID)
-	url := instances[graphResourceIndex].BaseURL + reqURL
+	url := instances[ingestionEndpoint].BaseURL + reqURL
 
-	resp, err := instances[graphResourceIndex].httpClient.Get(url)
+	resp, err := instances[ingestionEndpoint].httpClient.Get(url)
 	if err != nil {
-		return err.Error()
+		return nil, err
 	}
 
 	defer resp.Body.Close()
 	switch resp.StatusCode {
 	case http.StatusOK:
 		var res MSEnableAccountsRes
-		err := json.NewDecoder(resp.Body).Decode(&res)
+		b, _ := io.ReadAll(resp.Body)
+		err := json.Unmarshal(b, &res)
 		if err != nil {
-			return fmt.Sprintf("json decode %v", err.Error())
-		}
-		errB, _ := json.MarshalIndent(res.Errors, "", " ")
-		if res.JobResult == "failed" {
-			return fmt.Sprintf("job status error object %v", string(errB))
+			return nil, fmt.Errorf("json decode %v", err.Error())
 		}
-		resMB, _ := json.MarshalIndent(res, "", " ")
-		return fmt.Sprintf("job status response \n%s", string(resMB))
+		res.raw = b
+		return &res, nil
 	default:
 		b, _ := io.ReadAll(resp.Body)
 		errRes := map[string]any{}
 		_ = json.Unmarshal(b, &errRes)
 		mb, _ := json.MarshalIndent(errRes, "", " ")
-		return fmt.Sprintf("marketplace returned %v with response \n%v", resp.StatusCode, string(mb))
+		return nil, fmt.Errorf("marketplace returned %v with response \n%v", resp.StatusCode, string(mb))
+	}
+}
+
+// formatJobStatus renders the job status as a readable report with the errors
+// mapped to the plan/resource and audience they concern.
+func formatJobStatus(res *MSEnableAccountsRes) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "job status report\njob: %s\nstatus: %s\nresult: %s", res.JobID, res.JobStatus, jobResultValue(res.JobResult))
+	if res.JobStart != nil {
+		fmt.Fprintf(sb, "\nstarted: %s", res.JobStart.UTC().Format(time.RFC3339))
+	}
+	if res.JobEnd != nil {
+		fmt.Fprintf(sb, "\nended: %s", res.JobEnd.UTC().Format(time.RFC3339))
+	}
+	if res.JobStart != nil && res.JobEnd != nil {
+		fmt.Fprintf(sb, "\nduration: %s", res.JobEnd.Sub(*res.JobStart).Round(time.Second))
+	}
+
+	if len(res.Errors) > 0 {
+		fmt.Fprintf(sb, "\nerrors (%d):", len(res.Errors))
+	}
+	for i, e := range res.Errors {
+		fmt.Fprintf(sb, "\n%d. [%v] %s", i+1, e.Code, helpers.RedValue(e.Message))
+		resource, plan, audiences := e.target()
+		if resource != "" {
+			fmt.Fprintf(sb, "\n   resource: %s", resource)
+		}
+		if plan != "" {
+			fmt.Fprintf(sb, "\n   plan: %s", plan)
+		}
+		if len(audiences) > 0 {
+			fmt.Fprintf(sb, "\n   audience: %s", strings.Join(audiences, ","))
+		}
+		for _, detail := range e.Details {
+			fmt.Fprintf(sb, "\n   detail: %s", flattenDetail(detail))
+		}
+	}
+	return sb.String()
+}
+
+// jobResultValue highlights the result of a job
+func jobResultValue(result string) string {
+	switch result {
+	case "failed":
+		return helpers.RedValue(result)
+	case "succeeded", "success":
+		return helpers.GreenValue(result)
+	}
+	return result
+}
+
+// target returns the resource, plan and audience ids the error concerns. The resource comes from the
+// resourceId of the error or its details, audiences are the ids mentioned in the messages.
+func (e MSErrorResp) target() (resource, plan string, audiences []string) {
+	resource = e.ResourceID
+	texts := []string{e.Message}
+	for _, detail := range e.Details {
+		if id, ok := detail["resourceId"].(string); ok && resource == "" {
+			resource = id
+		}
+		if msg, ok := detail["message"].(string); ok {
+			texts = append(texts, msg)
+		}
+	}
+
+	if res := planResourceRegex.FindStringSubmatch(resource); len(res) > 1 {
+		plan = res[1]
+	}
+
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, id := range guidRegex.FindAllString(text, -1) {
+			if seen[id] || strings.Contains(resource, id) {
+				continue
+			}
+			seen[id] = true
+			audiences = append(audiences, id)
+		}
+	}
+	return resource, plan, audiences
+}
+
+// flattenDetail renders an error detail as sorted key=value pairs
+func flattenDetail(detail map[string]interface{}) string {
+	keys := make([]string, 0, len(detail))
+	for k := range detail {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := detail[k]
+		if _, ok := v.(string); !ok {
+			b, _ := json.Marshal(v)
+			v = string(b)
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, v))
 	}
+	return strings.Join(pairs, ", ")
 }
@@ -0,0 +1,322 @@
+package azure
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+)
+
+const (
+	// usageEventPath is the path of the metering API posting a single usage event
+	usageEventPath = "/api/usageEvent"
+	// batchUsageEventPath is the path of the metering API posting a batch of usage events
+	batchUsageEventPath = "/api/batchUsageEvent"
+	// usageEventsPath is the path of the metering API returning the aggregated usage events
+	usageEventsPath = "/api/usageEvents"
+
+	// maxUsageEventBatch is the maximum number of usage events of a batch request
+	maxUsageEventBatch = 25
+
+	// priceAndAvailabilityOfferSchema identifies the resource holding the custom meters of an offer
+	priceAndAvailabilityOfferSchema = "/price-and-availability-offer/"
+	// priceAndAvailabilityPlanSchema identifies the resource holding the custom meters enabled on a plan
+	priceAndAvailabilityPlanSchema = "/price-and-availability-plan/"
+)
+
+// UsageEvent represents a usage event of a custom meter
+type UsageEvent struct {
+	ResourceID         string    `json:"resourceId"`
+	Quantity           float64   `json:"quantity"`
+	Dimension          string    `json:"dimension"`
+	EffectiveStartTime time.Time `json:"effectiveStartTime"`
+	PlanID             string    `json:"planId"`
+}
+
+// UsageEventResult represents the outcome of a posted usage event
+type UsageEventResult struct {
+	UsageEventID       string         `json:"usageEventId,omitempty"`
+	Status             string         `json:"status"`
+	MessageTime        *time.Time     `json:"messageTime,omitempty"`
+	ResourceID         string         `json:"resourceId"`
+	Quantity           float64        `json:"quantity"`
+	Dimension          string         `json:"dimension"`
+	EffectiveStartTime time.Time      `json:"effectiveStartTime"`
+	PlanID             string         `json:"planId"`
+	Error              *MeteringError `json:"error,omitempty"`
+}
+
+// MeteringError represents the error of a usage event rejected in a batch
+type MeteringError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UsageEventBatch represents the batch usage event request body
+type UsageEventBatch struct {
+	Request []UsageEvent `json:"request"`
+}
+
+// usageEventBatchResult represents the outcome of a batch of usage events
+type usageEventBatchResult struct {
+	Count  int                `json:"count"`
+	Result []UsageEventResult `json:"result"`
+}
+
+// UsageQuery contains the filters of the aggregated usage events
+type UsageQuery struct {
+	Start          time.Time
+	End            time.Time
+	OfferID        string
+	PlanID         string
+	Dimension      string
+	SubscriptionID string
+	ReconStatus    string
+}
+
+// UsageAggregate represents the usage of a dimension aggregated per day as recorded by the metering service
+type UsageAggregate struct {
+	UsageDate           time.Time `json:"usageDate"`
+	UsageResourceID     string    `json:"usageResourceId"`
+	Dimension           string    `json:"dimension"`
+	PlanID              string    `json:"planId"`
+	OfferID             string    `json:"offerId"`
+	AzureSubscriptionID string    `json:"azureSubscriptionId"`
+	ReconStatus         string    `json:"reconStatus"`
+	SubmittedQuantity   float64   `json:"submittedQuantity"`
+	ProcessedQuantity   float64   `json:"processedQuantity"`
+	SubmittedCount      int       `json:"submittedCount"`
+}
+
+// PostUsageEvent posts a single usage event
+func PostUsageEvent(event UsageEvent) (*UsageEventResult, error) {
+	var res UsageEventResult
+	err := marketplaceRequest(http.MethodPost, usageEventPath, event, nil, &res)
+	audit.Record(audit.Entry{Action: "azure metering post", Targets: []string{event.ResourceID, event.PlanID, event.Dimension}}, err)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// PostUsageEvents posts the usage events in batches, the results keep the order of the events
+func PostUsageEvents(events []UsageEvent) ([]UsageEventResult, error) {
+	results := []UsageEventResult{}
+	for _, batch := range UsageEventBatches(events) {
+		var res usageEventBatchResult
+		err := marketplaceRequest(http.MethodPost, batchUsageEventPath, batch, nil, &res)
+		audit.Record(audit.Entry{Action: "azure metering post-batch", Targets: usageEventTargets(batch.Request)}, err)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res.Result...)
+	}
+	return results, nil
+}
+
+// usageEventTargets returns the distinct resources of the usage events
+func usageEventTargets(events []UsageEvent) []string {
+	targets := []string{}
+	for _, e := range events {
+		if !slices.Contains(targets, e.ResourceID) {
+			targets = append(targets, e.ResourceID)
+		}
+	}
+	return targets
+}
+
+// UsageEventBatches splits the usage events into the bodies of the batch requests
+func UsageEventBatches(events []UsageEvent) []UsageEventBatch {
+	batches := []UsageEventBatch{}
+	for start := 0; start < len(events); start += maxUsageEventBatch {
+		end := min(start+maxUsageEventBatch, len(events))
+		batches = append(batches, UsageEventBatch{Request: events[start:end]})
+	}
+	return batches
+}
+
+// GetUsageEvents returns the usage events recorded by the metering service aggregated per day
+func GetUsageEvents(query UsageQuery) ([]UsageAggregate, error) {
+	if query.Start.IsZero() {
+		return nil, errors.New("usage start date cannot be empty")
+	}
+
+	params := url.Values{}
+	params.Set("usageStartDate", query.Start.UTC().Format(time.RFC3339))
+	if !query.End.IsZero() {
+		params.Set("usageEndDate", query.End.UTC().Format(time.RFC3339))
+	}
+	for key, value := range map[string]string{
+		"offerId":             query.OfferID,
+		"planId":              query.PlanID,
+		"dimension":           query.Dimension,
+		"azureSubscriptionId": query.SubscriptionID,
+		"reconStatus":         query.ReconStatus,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+
+	res := []UsageAggregate{}
+	if err := marketplaceRequest(http.MethodGet, usageEventsPath+"?"+params.Encode(), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// planDimensions returns the dimensions of the custom meters enabled on the plan of the offer,
+// the plan is identified by its id shown in Partner Center as used by the usage events.
+func planDimensions(offer, planID string) ([]string, error) {
+	res, err := getResourceTree(config.Offers[offer].ProductDurableID)
+	if err != nil {
+		return nil, err
+	}
+
+	planResource := ""
+	for _, obj := range res.Resources {
+		if strings.HasPrefix(obj.ID, "plan/") && obj.Identity.ExternalID == planID {
+			planResource = obj.ID
+		}
+	}
+	if planResource == "" {
+		return nil, fmt.Errorf("plan %s not found in the offer %s", planID, offer)
+	}
+
+	offerMeters, planMeters := map[string]CustomMeter{}, map[string]CustomMeter{}
+	for _, obj := range res.Resources {
+		switch {
+		case strings.Contains(obj.Schema, priceAndAvailabilityOfferSchema):
+			offerMeters = obj.CustomMeters
+		case strings.Contains(obj.Schema, priceAndAvailabilityPlanSchema) && obj.Plan == planResource:
+			planMeters = obj.CustomMeters
+		}
+	}
+
+	dimensions := []string{}
+	for id, meter := range offerMeters {
+		// plans without meter settings inherit every meter of the offer
+		if planMeter, ok := planMeters[id]; len(planMeters) > 0 && (!ok || planMeter.Enabled != nil && !*planMeter.Enabled) {
+			continue
+		}
+		dimensions = append(dimensions, firstNonEmpty(meter.UniqueID, id))
+	}
+	if len(dimensions) == 0 {
+		return nil, fmt.Errorf("plan %s of the offer %s has no custom meters", planID, offer)
+	}
+	sort.Strings(dimensions)
+	return dimensions, nil
+}
+
+// ValidateUsageEvents checks the usage events against the custom meters of their plans in the offer
+func ValidateUsageEvents(offer string, events []UsageEvent) error {
+	dimensions := map[string][]string{}
+	errs := []error{}
+	for i, event := range events {
+		prefix := fmt.Sprintf("usage event %d", i+1)
+		if event.ResourceID == "" {
+			errs = append(errs, fmt.Errorf("%s: resource id cannot be empty", prefix))
+		}
+		if event.Quantity <= 0 {
+			errs = append(errs, fmt.Errorf("%s: quantity must be positive", prefix))
+		}
+		if event.EffectiveStartTime.After(time.Now()) {
+			errs = append(errs, fmt.Errorf("%s: effective start time %s is in the future", prefix, event.EffectiveStartTime.Format(time.RFC3339)))
+		}
+		if event.PlanID == "" {
+			errs = append(errs, fmt.Errorf("%s: plan cannot be empty", prefix))
+			continue
+		}
+
+		if _, ok := dimensions[event.PlanID]; !ok {
+			planDims, err := planDimensions(offer, event.PlanID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", prefix, err))
+				dimensions[event.PlanID] = nil
+				continue
+			}
+			dimensions[event.PlanID] = planDims
+		}
+		if planDims := dimensions[event.PlanID]; planDims != nil && !slices.Contains(planDims, event.Dimension) {
+			errs = append(errs, fmt.Errorf("%s: invalid dimension %q for plan %s, use %s", prefix, event.Dimension, event.PlanID, strings.Join(planDims, "|")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readUsageEvents reads the usage events of a CSV file with the header
+// resourceId,planId,dimension,quantity,effectiveStartTime in any order.
+// An empty effective start time defaults to the start of the current hour.
+func readUsageEvents(r io.Reader) ([]UsageEvent, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"resourceId", "planId", "dimension", "quantity"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the column %s", name)
+		}
+	}
+	value := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	events := []UsageEvent{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		quantity, err := strconv.ParseFloat(value(record, "quantity"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quantity %q", line, value(record, "quantity"))
+		}
+		start, err := parseEffectiveStartTime(value(record, "effectiveStartTime"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, UsageEvent{
+			ResourceID:         value(record, "resourceId"),
+			PlanID:             value(record, "planId"),
+			Dimension:          value(record, "dimension"),
+			Quantity:           quantity,
+			EffectiveStartTime: start,
+		})
+	}
+}
+
+// parseEffectiveStartTime parses an RFC3339 time, empty defaults to the start of the current hour
+func parseEffectiveStartTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC().Truncate(time.Hour), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid effective start time %q, use RFC3339", value)
+	}
+	return t.UTC(), nil
+}
@@ -5,6 +5,12 @@ type CommandModule struct {
 	CommandGetConfig       commandGetConfig
 	CommandConfigureConfig commandConfigureConfig
 	CommandSearchConfig    commandSearchConfig
+	CommandPreviewConfig   commandPreviewConfig
+	CommandOfferConfig     commandOfferConfig
+	CommandJobsConfig      commandJobsConfig
+	CommandSaaSConfig      commandSaaSConfig
+	CommandMeteringConfig  commandMeteringConfig
+	CommandGroupConfig     commandGroupConfig
 }
 
 // NewCommandModule returns the command module
@@ -13,5 +19,11 @@ func NewCommandModule() *CommandModule {
 		CommandGetConfig:       fetchCommandGetConfig(),
 		CommandConfigureConfig: fetchCommandConfigureConfig(),
 		CommandSearchConfig:    fetchCommandSearchConfig(),
+		CommandPreviewConfig:   fetchCommandPreviewConfig(),
+		CommandOfferConfig:     fetchCommandOfferConfig(),
+		CommandJobsConfig:      fetchCommandJobsConfig(),
+		CommandSaaSConfig:      fetchCommandSaaSConfig(),
+		CommandMeteringConfig:  fetchCommandMeteringConfig(),
+		CommandGroupConfig:     fetchCommandGroupConfig(),
 	}
 }
@@ -3,11 +3,16 @@ package azure
 import (
 	"log"
 	"os"
+	"path/filepath"
 )
 
 func prepareTestEnvironment() {
+	os.Setenv("UNFOLD_NO_CACHE", "true")
+	os.Setenv("UNFOLD_STATE_DIR", filepath.Join(os.TempDir(), "unfold-azure-test-state"))
 	os.Setenv("AZURE_OFFERS_FILE", "testdata/offers_test.yml")
 	os.Setenv("AZURE_CERT_FILE", "testdata/test_ca_cert.txt")
+	os.Setenv("AZURE_AUTH_METHOD", "")
+	os.Setenv("AZURE_CLOUD", "")
 	config.IdentityCAFile = os.Getenv("AZURE_CERT_FILE")
 	err := StartService()
 	if err != nil {
@@ -0,0 +1,201 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+const (
+	// previewAudienceSchema is the schema of the preview audience resource of a product
+	previewAudienceSchema = "https://schema.mp.microsoft.com/schema/preview-audience/2022-03-01-preview2"
+	// configureSchema is the schema of the product ingestion configure request
+	configureSchema = "https://schema.mp.microsoft.com/schema/configure/2022-03-01-preview2"
+)
+
+// PreviewAudience represents an audience of the preview audience (hide key) of an offer
+type PreviewAudience struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+// MSPreviewAudienceResource represents the preview audience resource request body,
+// the preview audience is replaced as a whole with the given list.
+type MSPreviewAudienceResource struct {
+	Schema           string            `json:"$schema"`
+	Product          string            `json:"product"`
+	PreviewAudiences []PreviewAudience `json:"previewAudiences"`
+}
+
+// MSConfigurePreviewAudience represents the configure request body for the preview audience
+type MSConfigurePreviewAudience struct {
+	Schema    string                      `json:"$schema"`
+	Resources []MSPreviewAudienceResource `json:"resources"`
+}
+
+// GetPreviewAudienceListForOffer returns the preview audience of the offer from its resource tree,
+// an offer without preview audience resource returns an empty list.
+func GetPreviewAudienceListForOffer(productID string) ([]PreviewAudience, error) {
+	res, err := getResourceTree(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range res.Resources {
+		if strings.Contains(obj.Schema, "/preview-audience/") || len(obj.PreviewAudiences) > 0 {
+			return obj.PreviewAudiences, nil
+		}
+	}
+	return []PreviewAudience{}, nil
+}
+
+// MakePreviewAudienceRequest adds the id to or removes it from the preview audience of the offer/image
+func MakePreviewAudienceRequest(image, id, audType, label, mode string) string {
+	op, err := PlanPreviewAudience(image, id, audType, label, mode)
+	if err != nil {
+		return err.Error()
+	}
+	loggerObj, err := ApplyPlannedPreviewAudience(*op)
+	if err != nil {
+		return err.Error()
+	}
+
+	b, _ := json.MarshalIndent(loggerObj, "", " ")
+
+	return fmt.Sprintf("configure response \n%v", string(b))
+}
+
+// PlanPreviewAudience resolves the offer into the operation adding the tenant or subscription to its preview audience,
+// or removing it
+func PlanPreviewAudience(image, id, audType, label, mode string) (*plan.Operation, error) {
+	offer, ok := config.Offers[image]
+	if !ok {
+		return nil, fmt.Errorf("offer %s is not configured", image)
+	}
+
+	audienceType := "subscription"
+	if strings.EqualFold(audType, "tenant") {
+		audienceType = "tenant"
+	}
+	return &plan.Operation{
+		Kind:             plan.AzurePreviewAudience,
+		Mode:             mode,
+		Offer:            image,
+		ProductDurableID: offer.ProductDurableID,
+		AudienceType:     audienceType,
+		AudienceID:       id,
+		Label:            label,
+	}, nil
+}
+
+// ValidatePlannedPreviewAudience checks the offer still resolves to the product of the operation
+// and the audience is still as planned, i.e. absent to add it and present to remove it
+func ValidatePlannedPreviewAudience(op plan.Operation) error {
+	offer, ok := config.Offers[op.Offer]
+	if !ok {
+		return fmt.Errorf("offer %s is not configured", op.Offer)
+	}
+	if offer.ProductDurableID != op.ProductDurableID {
+		return fmt.Errorf("offer %s now resolves to product %s instead of %s", op.Offer, offer.ProductDurableID, op.ProductDurableID)
+	}
+	_, err := plannedPreviewAudiences(op)
+	return err
+}
+
+// plannedPreviewAudiences returns the preview audience of the product once the operation is applied
+func plannedPreviewAudiences(op plan.Operation) ([]PreviewAudience, error) {
+	// the whole list is sent back as a replacement, read it from a fresh resource tree
+	// so that changes made since the tree was cached are not dropped
+	cache.Delete(resourceTreeCacheKey(op.ProductDurableID))
+	audiences, err := GetPreviewAudienceListForOffer(op.ProductDurableID)
+	if err != nil {
+		return nil, err
+	}
+	return updatePreviewAudiences(audiences, PreviewAudience{Type: op.AudienceType, ID: op.AudienceID, Label: op.Label}, op.Mode)
+}
+
+// ApplyPlannedPreviewAudience submits the preview audience change of the operation, exactly for its product
+func ApplyPlannedPreviewAudience(op plan.Operation) (*LoggerObj, error) {
+	loggerObj := LoggerObj{SyncAudienceType: op.AudienceType}
+	if op.AudienceType == "tenant" {
+		loggerObj.TenantID = op.AudienceID
+	} else {
+		loggerObj.SubscriptionID = op.AudienceID
+	}
+
+	audiences, err := plannedPreviewAudiences(op)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody := MSConfigurePreviewAudience{
+		Schema: configureSchema,
+		Resources: []MSPreviewAudienceResource{
+			{
+				Schema:           previewAudienceSchema,
+				Product:          "product/" + op.ProductDurableID,
+				PreviewAudiences: audiences,
+			},
+		},
+	}
+
+	// make request to Azure
+	azureJob, err := configurePrivateAudienceAPI(reqBody)
+	entry := audit.Entry{Action: "azure preview-audience " + op.Mode, Targets: []string{op.AudienceID, op.Offer, op.ProductDurableID}}
+	if err != nil {
+		audit.Record(entry, err)
+		return nil, err
+	}
+	entry.AzureJobID = azureJob.JobID
+	audit.Record(entry, nil)
+
+	// The preview audience of the offer has changed, drop the cached resource tree
+	cache.Delete(resourceTreeCacheKey(op.ProductDurableID))
+
+	recordJob(JobRecord{
+		JobID:        azureJob.JobID,
+		Offer:        op.Offer,
+		ProductID:    op.ProductDurableID,
+		AudienceIDs:  []string{op.AudienceID},
+		AudienceType: op.AudienceType,
+		Mode:         "preview-" + op.Mode,
+		JobStatus:    azureJob.JobStatus,
+		JobResult:    azureJob.JobResult,
+	})
+
+	loggerObj.AzureJobID = azureJob.JobID
+	loggerObj.AzureJobResult = azureJob.JobResult
+
+	return &loggerObj, nil
+}
+
+// updatePreviewAudiences returns the preview audience after adding or removing the audience
+func updatePreviewAudiences(audiences []PreviewAudience, audience PreviewAudience, mode string) ([]PreviewAudience, error) {
+	index := -1
+	for i, obj := range audiences {
+		if strings.EqualFold(obj.ID, audience.ID) {
+			index = i
+			break
+		}
+	}
+
+	switch mode {
+	case plan.AddMode:
+		if index >= 0 {
+			return nil, fmt.Errorf("%s is already in the preview audience with type %s", audience.ID, audiences[index].Type)
+		}
+		return append(audiences, audience), nil
+	case plan.RemoveMode:
+		if index < 0 {
+			return nil, fmt.Errorf("%s is not in the preview audience", audience.ID)
+		}
+		updated := append([]PreviewAudience{}, audiences[:index]...)
+		return append(updated, audiences[index+1:]...), nil
+	}
+	return nil, fmt.Errorf("invalid mode %s", mode)
+}
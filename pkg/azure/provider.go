@@ -0,0 +1,68 @@
+package azure
+
+import (
+	"github.com/aryannr97/unfold/pkg/directory"
+)
+
+// entraGroupProvider implements directory.GroupProvider on top of the Microsoft Entra ID groups,
+// reporting whether a member is a direct or transitive member of the group as its role
+type entraGroupProvider struct{}
+
+// NewEntraGroupProvider returns the Entra ID group provider, usable once the service is started
+func NewEntraGroupProvider() directory.GroupProvider {
+	return entraGroupProvider{}
+}
+
+// LookupGroup returns the group identified by its object id, mail or display name
+func (entraGroupProvider) LookupGroup(groupID string) (*directory.Group, error) {
+	g, err := GetEntraGroup(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &directory.Group{ID: groupID, Name: g.ID, DisplayName: g.DisplayName}, nil
+}
+
+// ListMembers returns the direct and transitive user members of the group, identified by their user principal name
+func (entraGroupProvider) ListMembers(groupID string) ([]directory.Member, error) {
+	memberships, err := ListEntraGroupMembers(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]directory.Member, 0, len(memberships))
+	for _, m := range memberships {
+		id := m.UserPrincipalName
+		if id == "" {
+			id = m.UserID
+		}
+		members = append(members, directory.Member{ID: id, Name: m.UserID, Roles: []string{m.Type}})
+	}
+	return members, nil
+}
+
+// CheckMember returns the direct or transitive membership of the user in the group
+func (entraGroupProvider) CheckMember(groupID, memberID string) (*directory.Member, error) {
+	m, err := CheckEntraGroupMembership(groupID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Type == "" {
+		return nil, directory.ErrMemberNotFound
+	}
+	return &directory.Member{ID: memberID, Name: m.UserID, Roles: []string{m.Type}}, nil
+}
+
+// AddMember adds the user as direct member of the group, the only membership which can be added
+func (entraGroupProvider) AddMember(groupID, memberID, _ string) error {
+	return AddMemberToEntraGroup(groupID, memberID)
+}
+
+// RemoveMember removes the direct membership of the user from the group
+func (entraGroupProvider) RemoveMember(groupID, memberID string) error {
+	return RemoveMemberFromEntraGroup(groupID, memberID)
+}
+
+// Roles returns the direct membership, transitive memberships are managed on the nested groups
+func (entraGroupProvider) Roles() []string {
+	return []string{DirectMembership}
+}
@@ -0,0 +1,178 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// defaultRetries is the default number of retries of a failed request
+	defaultRetries = 3
+	// defaultRetryMaxWait is the default upper bound of the wait between two attempts
+	defaultRetryMaxWait = 30 * time.Second
+	// retryBaseDelay is the initial backoff delay, doubled with every attempt
+	retryBaseDelay = 500 * time.Millisecond
+)
+
+// RetryConfig contains the retry settings of the Partner Center and ARM calls
+type RetryConfig struct {
+	// Retries is the number of retries after the first attempt, 0 disables retries
+	Retries int
+	// MaxWait bounds the wait between two attempts, including waits requested by Retry-After
+	MaxWait time.Duration
+}
+
+var (
+	// retryConfig is the retry configuration shared by all azure services
+	retryConfig = RetryConfig{Retries: defaultRetries, MaxWait: defaultRetryMaxWait}
+
+	// retrySleep waits for the duration or until the context is done, replaceable in tests
+	retrySleep = func(ctx context.Context, d time.Duration) error {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+)
+
+// bindRetryFlags registers the retry flags on the given flag set
+func bindRetryFlags(flagSet *flag.FlagSet) {
+	flagSet.IntVar(&retryConfig.Retries, "retries", defaultRetries, "number of retries of throttled or failed azure requests")
+	flagSet.DurationVar(&retryConfig.MaxWait, "retry-max-wait", defaultRetryMaxWait, "maximum wait between retries of azure requests")
+}
+
+// retryTransport retries throttled requests, server errors and transient network errors
+// with jittered exponential backoff, honoring the Retry-After header of the response.
+type retryTransport struct {
+	base   http.RoundTripper
+	config *RetryConfig
+}
+
+// newRetryTransport returns a retrying round tripper on top of base, http.DefaultTransport when nil
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base, config: &retryConfig}
+}
+
+// RoundTrip executes the request and retries it while the failure is retryable
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the body has to be replayed on every attempt, a RoundTripper must not modify
+	// the caller's request so the buffered body is set on a clone
+	if req.Body != nil && req.GetBody == nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close() //nolint:errcheck
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}
+		req.Body, _ = req.GetBody()
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.config.Retries || !retryable(req.Method, resp, err) {
+			return resp, err
+		}
+
+		wait := backoff(attempt, t.config.MaxWait)
+		if resp != nil {
+			if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = min(d, t.config.MaxWait)
+			}
+			io.Copy(io.Discard, resp.Body) //nolint:errcheck
+			resp.Body.Close()              //nolint:errcheck
+		}
+
+		if err := retrySleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether the failed attempt can be safely retried. Idempotent requests are
+// retried on throttling, server errors and transient network errors. Other requests, e.g. the
+// configure POST, are only retried when the server did not process them: throttled or unavailable
+// responses and connections that could not be established.
+func retryable(method string, resp *http.Response, err error) bool {
+	idempotent := method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions ||
+		method == http.MethodPut || method == http.MethodDelete
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		if !idempotent {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout() ||
+			errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// backoff returns the jittered exponential backoff of the attempt bounded by maxWait
+func backoff(attempt int, maxWait time.Duration) time.Duration {
+	d := retryBaseDelay << min(attempt, 16)
+	if d > maxWait || d <= 0 {
+		d = maxWait
+	}
+	// full jitter spreads the retries of concurrent requests
+	return time.Duration(rand.Int63n(int64(d) + 1)) //nolint:gosec // jitter does not need a secure source
+}
+
+// retryAfter parses the Retry-After header given in seconds or as HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
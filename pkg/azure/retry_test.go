@@ -0,0 +1,256 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// attempt is the outcome of a single round trip of the scripted transport
+type attempt struct {
+	status     int
+	retryAfter string
+	err        error
+}
+
+// scriptedTransport answers the round trips with the scripted attempts and records the request bodies
+type scriptedTransport struct {
+	attempts []attempt
+	bodies   []string
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+	}
+	s.bodies = append(s.bodies, body)
+
+	a := s.attempts[len(s.bodies)-1]
+	if a.err != nil {
+		return nil, a.err
+	}
+	resp := &http.Response{StatusCode: a.status, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(`{}`))}
+	if a.retryAfter != "" {
+		resp.Header.Set("Retry-After", a.retryAfter)
+	}
+	return resp, nil
+}
+
+func Test_retryTransport_RoundTrip(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}
+	resetErr := &net.OpError{Op: "read", Err: syscall.ECONNRESET}
+
+	tests := []struct {
+		name         string
+		method       string
+		config       RetryConfig
+		attempts     []attempt
+		wantStatus   int
+		wantErr      bool
+		wantAttempts int
+		wantWaits    []time.Duration
+	}{
+		{
+			name:         "get succeeds after throttling with retry-after",
+			method:       http.MethodGet,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Minute},
+			attempts:     []attempt{{status: http.StatusTooManyRequests, retryAfter: "2"}, {status: http.StatusOK}},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+			wantWaits:    []time.Duration{2 * time.Second},
+		},
+		{
+			name:         "retry-after bounded by max wait",
+			method:       http.MethodGet,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{status: http.StatusServiceUnavailable, retryAfter: "120"}, {status: http.StatusOK}},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+			wantWaits:    []time.Duration{time.Second},
+		},
+		{
+			name:         "get retried on server errors until retries exhausted",
+			method:       http.MethodGet,
+			config:       RetryConfig{Retries: 2, MaxWait: time.Second},
+			attempts:     []attempt{{status: http.StatusBadGateway}, {status: http.StatusInternalServerError}, {status: http.StatusGatewayTimeout}},
+			wantStatus:   http.StatusGatewayTimeout,
+			wantAttempts: 3,
+		},
+		{
+			name:         "get retried on connection reset",
+			method:       http.MethodGet,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{err: resetErr}, {status: http.StatusOK}},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "post retried on throttling",
+			method:       http.MethodPost,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{status: http.StatusTooManyRequests}, {status: http.StatusOK}},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "post retried when the connection could not be established",
+			method:       http.MethodPost,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{err: dialErr}, {status: http.StatusOK}},
+			wantStatus:   http.StatusOK,
+			wantAttempts: 2,
+		},
+		{
+			name:         "post not retried on server error",
+			method:       http.MethodPost,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{status: http.StatusInternalServerError}},
+			wantStatus:   http.StatusInternalServerError,
+			wantAttempts: 1,
+		},
+		{
+			name:         "post not retried on connection reset",
+			method:       http.MethodPost,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{err: resetErr}},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "client errors not retried",
+			method:       http.MethodGet,
+			config:       RetryConfig{Retries: 3, MaxWait: time.Second},
+			attempts:     []attempt{{status: http.StatusNotFound}},
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retries disabled",
+			method:       http.MethodGet,
+			config:       RetryConfig{Retries: 0, MaxWait: time.Second},
+			attempts:     []attempt{{status: http.StatusTooManyRequests}},
+			wantStatus:   http.StatusTooManyRequests,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waits := []time.Duration{}
+			original := retrySleep
+			retrySleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+			defer func() { retrySleep = original }()
+
+			base := &scriptedTransport{attempts: tt.attempts}
+			transport := &retryTransport{base: base, config: &tt.config}
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader(`{"resources": []}`)
+			}
+			req, _ := http.NewRequest(tt.method, "https://graph.microsoft.com/rp/product-ingestion/configure", body)
+			resp, err := transport.RoundTrip(req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && resp.StatusCode != tt.wantStatus {
+				t.Errorf("RoundTrip() status = %v, want %v", resp.StatusCode, tt.wantStatus)
+			}
+			if len(base.bodies) != tt.wantAttempts {
+				t.Errorf("RoundTrip() attempts = %v, want %v", len(base.bodies), tt.wantAttempts)
+			}
+			for i, b := range base.bodies {
+				if tt.method == http.MethodPost && b != `{"resources": []}` {
+					t.Errorf("RoundTrip() attempt %d body = %q, want the original body", i, b)
+				}
+			}
+			for i, want := range tt.wantWaits {
+				if waits[i] != want {
+					t.Errorf("RoundTrip() wait %d = %v, want %v", i, waits[i], want)
+				}
+			}
+			for _, wait := range waits {
+				if wait > tt.config.MaxWait {
+					t.Errorf("RoundTrip() wait = %v, exceeds %v", wait, tt.config.MaxWait)
+				}
+			}
+		})
+	}
+}
+
+func Test_retryTransport_RoundTrip_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	base := &scriptedTransport{attempts: []attempt{{status: http.StatusTooManyRequests}, {status: http.StatusOK}}}
+	transport := &retryTransport{base: base, config: &RetryConfig{Retries: 3, MaxWait: time.Second}}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://management.azure.com", nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("RoundTrip() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_retryTransport_RoundTrip_callerRequest(t *testing.T) {
+	base := &scriptedTransport{attempts: []attempt{{status: http.StatusTooManyRequests}, {status: http.StatusOK}}}
+	transport := &retryTransport{base: base, config: &RetryConfig{Retries: 1, MaxWait: time.Millisecond}}
+
+	// a body without GetBody, as http.NewRequest leaves it for arbitrary readers
+	body := io.NopCloser(struct{ io.Reader }{strings.NewReader(`{"resources": []}`)})
+	req, _ := http.NewRequest(http.MethodPost, "https://graph.microsoft.com/rp/product-ingestion/configure", body)
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	if req.GetBody != nil || req.Body != body {
+		t.Error("RoundTrip() modified the body of the caller's request")
+	}
+	if len(base.bodies) != 2 || base.bodies[1] != `{"resources": []}` {
+		t.Errorf("RoundTrip() bodies = %q, want the original body replayed", base.bodies)
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "5", want: 5 * time.Second, wantOK: true},
+		{name: "date in the past", value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOK: true},
+		{name: "empty", value: "", wantOK: false},
+		{name: "invalid", value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.value)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_bindRetryFlags(t *testing.T) {
+	defer func() { retryConfig = RetryConfig{Retries: defaultRetries, MaxWait: defaultRetryMaxWait} }()
+
+	c := NewCommandModule().CommandConfigureConfig
+	if err := c.GetFlagSet().Parse([]string{"--retries", "5", "--retry-max-wait", "1m"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if retryConfig.Retries != 5 || retryConfig.MaxWait != time.Minute {
+		t.Errorf("retryConfig = %+v, want 5 retries and 1m max wait", retryConfig)
+	}
+}
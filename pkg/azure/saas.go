@@ -0,0 +1,287 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// saasAPIVersion is the api-version of the SaaS fulfillment v2 and metering APIs
+	saasAPIVersion = "2018-08-31"
+	// saasSubscriptionsPath is the path of the SaaS subscriptions of the fulfillment API
+	saasSubscriptionsPath = "/api/saas/subscriptions"
+
+	// SaaSOperationSuccess acknowledges an operation of a SaaS subscription
+	SaaSOperationSuccess = "Success"
+	// SaaSOperationFailure rejects an operation of a SaaS subscription
+	SaaSOperationFailure = "Failure"
+)
+
+// saasInstance is the AZService calling the SaaS fulfillment and metering APIs, created on first use
+var saasInstance *AZService
+
+// SaaSUser represents the purchaser or the beneficiary of a SaaS subscription
+type SaaSUser struct {
+	EmailID  string `json:"emailId,omitempty"`
+	ObjectID string `json:"objectId,omitempty"`
+	TenantID string `json:"tenantId,omitempty"`
+	PUID     string `json:"puid,omitempty"`
+}
+
+// SaaSTerm represents the current billing term of a SaaS subscription
+type SaaSTerm struct {
+	StartDate *time.Time `json:"startDate,omitempty"`
+	EndDate   *time.Time `json:"endDate,omitempty"`
+	TermUnit  string     `json:"termUnit,omitempty"`
+}
+
+// SaaSSubscription represents a SaaS subscription of the fulfillment API
+type SaaSSubscription struct {
+	ID                     string    `json:"id"`
+	Name                   string    `json:"name"`
+	PublisherID            string    `json:"publisherId"`
+	OfferID                string    `json:"offerId"`
+	PlanID                 string    `json:"planId"`
+	Quantity               int       `json:"quantity,omitempty"`
+	Beneficiary            SaaSUser  `json:"beneficiary"`
+	Purchaser              SaaSUser  `json:"purchaser"`
+	SaasSubscriptionStatus string    `json:"saasSubscriptionStatus"`
+	Term                   SaaSTerm  `json:"term"`
+	AutoRenew              bool      `json:"autoRenew"`
+	IsTest                 bool      `json:"isTest"`
+	IsFreeTrial            bool      `json:"isFreeTrial"`
+	SandboxType            string    `json:"sandboxType,omitempty"`
+	AllowedCustomerOps     []string  `json:"allowedCustomerOperations,omitempty"`
+	SessionMode            string    `json:"sessionMode,omitempty"`
+	Created                time.Time `json:"created,omitempty"`
+}
+
+// SaaSResolvedSubscription represents the subscription resolved from a marketplace purchase token
+type SaaSResolvedSubscription struct {
+	ID               string           `json:"id"`
+	SubscriptionName string           `json:"subscriptionName"`
+	OfferID          string           `json:"offerId"`
+	PlanID           string           `json:"planId"`
+	Quantity         int              `json:"quantity,omitempty"`
+	Subscription     SaaSSubscription `json:"subscription"`
+}
+
+// SaaSOperation represents an operation of a SaaS subscription awaiting or reporting its outcome
+type SaaSOperation struct {
+	ID             string    `json:"id"`
+	ActivityID     string    `json:"activityId"`
+	SubscriptionID string    `json:"subscriptionId"`
+	OfferID        string    `json:"offerId"`
+	PublisherID    string    `json:"publisherId"`
+	PlanID         string    `json:"planId"`
+	Quantity       int       `json:"quantity,omitempty"`
+	Action         string    `json:"action"`
+	TimeStamp      time.Time `json:"timeStamp"`
+	Status         string    `json:"status"`
+}
+
+// saasSubscriptions represents a page of the SaaS subscriptions of the publisher
+type saasSubscriptions struct {
+	Subscriptions []SaaSSubscription `json:"subscriptions"`
+	NextLink      string             `json:"@nextLink"`
+}
+
+// saasOperations represents the pending operations of a SaaS subscription
+type saasOperations struct {
+	Operations []SaaSOperation `json:"operations"`
+}
+
+// saasActivation represents the activate request body
+type saasActivation struct {
+	PlanID   string `json:"planId"`
+	Quantity int    `json:"quantity,omitempty"`
+}
+
+// saasOperationUpdate represents the update operation request body
+type saasOperationUpdate struct {
+	Status string `json:"status"`
+}
+
+// saasConfig returns the configuration of the AAD app registered in the technical configuration
+// of the SaaS offers. Only the credentials differ from the main configuration, the tenant and the
+// authentication method fall back to the main ones.
+func (c *AZConfig) saasConfig() (*AZConfig, error) {
+	saas := *c
+	saas.ClientID = os.Getenv("AZURE_SAAS_CLIENT_ID")
+	saas.ClientSecret = os.Getenv("AZURE_SAAS_CLIENT_SECRET")
+	saas.TenantID = firstNonEmpty(os.Getenv("AZURE_SAAS_TENANT_ID"), c.TenantID)
+	saas.TokenURL = os.Getenv("AZURE_SAAS_TOKEN_URL")
+	saas.Auth.Method = firstNonEmpty(os.Getenv("AZURE_SAAS_AUTH_METHOD"), c.Auth.Method)
+	saas.Auth.ClientCertificateFile = os.Getenv("AZURE_SAAS_CLIENT_CERTIFICATE_FILE")
+
+	if saas.ClientID == "" && (saas.Auth.Method == SecretAuthMethod || saas.Auth.Method == CertificateAuthMethod) {
+		return nil, errors.New("AZURE_SAAS_CLIENT_ID is required to call the SaaS fulfillment API")
+	}
+	return &saas, nil
+}
+
+// saasService returns the service calling the SaaS fulfillment and metering APIs
+func saasService() (*AZService, error) {
+	if saasInstance != nil {
+		return saasInstance, nil
+	}
+
+	saas, err := config.saasConfig()
+	if err != nil {
+		return nil, err
+	}
+	saasInstance, err = saas.NewService(marketplaceEndpoint)
+	return saasInstance, err
+}
+
+// marketplaceRequest calls the SaaS fulfillment or metering API and decodes the response into out when given.
+// The path is relative to the marketplace endpoint unless it is an absolute next link, which must
+// point to the marketplace endpoint as the authorization token is sent along.
+func marketplaceRequest(method, path string, body any, header http.Header, out any) error {
+	s, err := saasService()
+	if err != nil {
+		return err
+	}
+
+	reqURL := path
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		base, err := url.Parse(s.BaseURL)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+			return fmt.Errorf("next link host %s://%s does not match the marketplace endpoint %s", u.Scheme, u.Host, s.BaseURL)
+		}
+	} else {
+		reqURL = s.BaseURL + path
+		u, err = url.Parse(reqURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		q.Set("api-version", saasAPIVersion)
+		u.RawQuery = q.Encode()
+		reqURL = u.String()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, reqURL, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("marketplace returned %v for %s %s with response %v", resp.StatusCode, method, req.URL.Path, helpers.GetErrorResponseBody(b))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("json decode %s", err.Error())
+	}
+	return nil
+}
+
+// ResolveSaaSSubscription resolves the marketplace purchase token of the landing page into its subscription
+func ResolveSaaSSubscription(token string) (*SaaSResolvedSubscription, error) {
+	header := http.Header{}
+	header.Set("x-ms-marketplace-token", token)
+
+	var res SaaSResolvedSubscription
+	if err := marketplaceRequest(http.MethodPost, saasSubscriptionsPath+"/resolve", nil, header, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ListSaaSSubscriptions returns all the SaaS subscriptions of the publisher, following the next links
+func ListSaaSSubscriptions() ([]SaaSSubscription, error) {
+	subscriptions := []SaaSSubscription{}
+	for path := saasSubscriptionsPath; ; {
+		var page saasSubscriptions
+		if err := marketplaceRequest(http.MethodGet, path, nil, nil, &page); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, page.Subscriptions...)
+		if page.NextLink == "" {
+			return subscriptions, nil
+		}
+		path = page.NextLink
+	}
+}
+
+// GetSaaSSubscription returns the SaaS subscription
+func GetSaaSSubscription(subscriptionID string) (*SaaSSubscription, error) {
+	var res SaaSSubscription
+	if err := marketplaceRequest(http.MethodGet, saasSubscriptionsPath+"/"+url.PathEscape(subscriptionID), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// ActivateSaaSSubscription activates the SaaS subscription on the plan, quantity is only used by per user plans
+func ActivateSaaSSubscription(subscriptionID, planID string, quantity int) error {
+	if planID == "" {
+		return errors.New("plan cannot be empty")
+	}
+	err := marketplaceRequest(http.MethodPost, fmt.Sprintf("%s/%s/activate", saasSubscriptionsPath, url.PathEscape(subscriptionID)),
+		saasActivation{PlanID: planID, Quantity: quantity}, nil, nil)
+	audit.Record(audit.Entry{Action: "azure saas activate", Targets: []string{subscriptionID, planID}}, err)
+	return err
+}
+
+// ListSaaSOperations returns the pending operations of the SaaS subscription
+func ListSaaSOperations(subscriptionID string) ([]SaaSOperation, error) {
+	var res saasOperations
+	if err := marketplaceRequest(http.MethodGet, fmt.Sprintf("%s/%s/operations", saasSubscriptionsPath, url.PathEscape(subscriptionID)), nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Operations, nil
+}
+
+// UpdateSaaSOperation reports the outcome of the operation of the SaaS subscription
+func UpdateSaaSOperation(subscriptionID, operationID, status string) error {
+	if operationID == "" {
+		return errors.New("operation id cannot be empty")
+	}
+	if status != SaaSOperationSuccess && status != SaaSOperationFailure {
+		return fmt.Errorf("invalid status %s, use %s|%s", status, SaaSOperationSuccess, SaaSOperationFailure)
+	}
+	err := marketplaceRequest(http.MethodPatch, fmt.Sprintf("%s/%s/operations/%s", saasSubscriptionsPath, url.PathEscape(subscriptionID), url.PathEscape(operationID)),
+		saasOperationUpdate{Status: status}, nil, nil)
+	audit.Record(audit.Entry{Action: "azure saas update-operation " + status, Targets: []string{subscriptionID, operationID}}, err)
+	return err
+}
@@ -0,0 +1,166 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// submissionSchema is the schema of the submission resource of a product
+	submissionSchema = "https://schema.mp.microsoft.com/schema/submission/2022-03-01-preview2"
+
+	// PreviewTarget publishes the offer to its preview audience
+	PreviewTarget = "preview"
+	// LiveTarget publishes the offer to the marketplace
+	LiveTarget = "live"
+)
+
+// Submission represents a submission resource of a product
+type Submission struct {
+	Schema         string           `json:"$schema"`
+	ID             string           `json:"id"`
+	Product        string           `json:"product"`
+	Target         SubmissionTarget `json:"target"`
+	LifecycleState string           `json:"lifecycleState"`
+	Status         string           `json:"status"`
+	Result         string           `json:"result"`
+	Created        time.Time        `json:"created"`
+}
+
+// SubmissionTarget represents the target environment of a submission
+type SubmissionTarget struct {
+	TargetType string `json:"targetType"`
+}
+
+// Submissions represents the submissions returned by the Partner Center API
+type Submissions struct {
+	Value []Submission `json:"value"`
+}
+
+// MSSubmissionResource represents the submission resource request body
+type MSSubmissionResource struct {
+	Schema  string           `json:"$schema"`
+	Product string           `json:"product"`
+	Target  SubmissionTarget `json:"target"`
+}
+
+// MSConfigureSubmission represents the configure request body publishing a submission
+type MSConfigureSubmission struct {
+	Schema    string                 `json:"$schema"`
+	Resources []MSSubmissionResource `json:"resources"`
+}
+
+// GetSubmissions makes a GET request to the Partner Center API to retrieve the submissions of the product
+func GetSubmissions(productID string) ([]Submission, error) {
+	reqURL := fmt.Sprintf("/rp/product-ingestion/submission/%s?$version=2022-03-01-preview2", productID)
+	url := instances[ingestionEndpoint].BaseURL + reqURL
+
+	resp, err := instances[ingestionEndpoint].httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var res Submissions
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			return nil, fmt.Errorf("json decode %s", err.Error())
+		}
+		return res.Value, nil
+	default:
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("marketplace returned %v for GetSubmissions with response %v", resp.StatusCode, helpers.GetErrorResponseBody(b))
+	}
+}
+
+// PublishOffer posts a configure request publishing the current state of the offer/image to the target
+func PublishOffer(image, target string) (*MSEnableAccountsRes, error) {
+	if target != PreviewTarget && target != LiveTarget {
+		return nil, fmt.Errorf("invalid target %s, use %s|%s", target, PreviewTarget, LiveTarget)
+	}
+
+	reqBody := MSConfigureSubmission{
+		Schema: configureSchema,
+		Resources: []MSSubmissionResource{
+			{
+				Schema:  submissionSchema,
+				Product: "product/" + config.Offers[image].ProductDurableID,
+				Target:  SubmissionTarget{TargetType: target},
+			},
+		},
+	}
+
+	azureJob, err := configurePrivateAudienceAPI(reqBody)
+	entry := audit.Entry{Action: "azure offer publish-" + target, Targets: []string{image, config.Offers[image].ProductDurableID}}
+	if err != nil {
+		audit.Record(entry, err)
+		return nil, err
+	}
+	entry.AzureJobID = azureJob.JobID
+	audit.Record(entry, nil)
+
+	recordJob(JobRecord{
+		JobID:     azureJob.JobID,
+		Offer:     image,
+		ProductID: config.Offers[image].ProductDurableID,
+		Mode:      "publish-" + target,
+		JobStatus: azureJob.JobStatus,
+		JobResult: azureJob.JobResult,
+	})
+	return azureJob, nil
+}
+
+// formatSubmissions renders the submissions of an offer along with whether preview and live are in sync
+func formatSubmissions(offer string, submissions []Submission) string {
+	if len(submissions) == 0 {
+		return fmt.Sprintf("no submission found for the offer %s", offer)
+	}
+
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tSTATUS\tRESULT\tLIFECYCLE\tCREATED\tID") //nolint:errcheck
+
+	latest := map[string]Submission{}
+	inProgress := false
+	for _, s := range submissions {
+		created := "-"
+		if !s.Created.IsZero() {
+			created = s.Created.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Target.TargetType, s.Status, s.Result, s.LifecycleState, created, s.ID) //nolint:errcheck
+
+		if s.Status != "" && s.Status != jobStatusCompleted {
+			inProgress = true
+		}
+		if prev, ok := latest[s.Target.TargetType]; !ok || s.Created.After(prev.Created) {
+			latest[s.Target.TargetType] = s
+		}
+	}
+	w.Flush() //nolint:errcheck
+
+	var state string
+	preview, hasPreview := latest[PreviewTarget]
+	live, hasLive := latest[LiveTarget]
+	switch {
+	case inProgress:
+		state = helpers.RedValue("submission in progress")
+	case !hasLive:
+		state = helpers.RedValue("not published live")
+	case hasPreview && preview.Created.After(live.Created):
+		state = helpers.RedValue("preview has changes not yet published live")
+	default:
+		state = helpers.GreenValue("preview and live are in sync")
+	}
+
+	return fmt.Sprintf("submissions of %s: %s\n%s", offer, state, strings.TrimSuffix(sb.String(), "\n"))
+}
@@ -2,11 +2,44 @@ package azure
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 )
 
+var (
+	// ErrSubscriptionNotFound is returned when the subscription does not exist
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrSubscriptionDisabled is returned when the subscription exists but is disabled or deleted
+	ErrSubscriptionDisabled = errors.New("subscription disabled")
+	// ErrTenantNotFound is returned when no tenant exists for the domain or tenant id
+	ErrTenantNotFound = errors.New("tenant not found")
+
+	// challengeParamRegex matches the key="value" parameters of a WWW-Authenticate challenge
+	challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+// SubscriptionError is returned when azure reports the subscription itself as unusable
+type SubscriptionError struct {
+	SubscriptionID string
+	Code           string
+	Message        string
+	Err            error
+}
+
+// Error returns the error message
+func (e *SubscriptionError) Error() string {
+	return fmt.Sprintf("%s %s (%s): %s", e.Err, e.SubscriptionID, e.Code, e.Message)
+}
+
+// Unwrap returns ErrSubscriptionNotFound or ErrSubscriptionDisabled
+func (e *SubscriptionError) Unwrap() error {
+	return e.Err
+}
+
 // tenantFinder uses regex to find tenant ids from azure api response
 type TenantFinder struct {
 	uniTenantRegex   *regexp.Regexp
@@ -15,7 +48,10 @@ type TenantFinder struct {
 
 // NewTenantFinder returns a new tenant finder
 func NewTenantFinder() *TenantFinder {
-	return &TenantFinder{}
+	a := &TenantFinder{}
+	// load predefined regex
+	a.fillDefaults()
+	return a
 }
 
 // fillDefaults populates default regular expressions
@@ -26,9 +62,6 @@ func (a *TenantFinder) fillDefaults() {
 
 // RetrieveTenantIDs uses regex to find tenant ids from azure api response
 func (a *TenantFinder) retrieveTenantIDsFromErrMsg(msg string) []string {
-	// load predefined regex
-	a.fillDefaults()
-
 	tids := []string{}
 
 	// try to find single tenant
@@ -55,29 +88,143 @@ func (a *TenantFinder) retrieveTenantIDsFromErrMsg(msg string) []string {
 	return tids
 }
 
+// retrieveTenantIDFromChallenge extracts the tenant id from the authorization_uri parameter
+// of a WWW-Authenticate challenge, e.g. Bearer authorization_uri="https://login.windows.net/<tenant-id>"
+func retrieveTenantIDFromChallenge(challenge string) string {
+	for _, param := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		if !strings.EqualFold(param[1], "authorization_uri") {
+			continue
+		}
+		u, err := url.Parse(param[2])
+		if err != nil {
+			return ""
+		}
+		tid, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+		return tid
+	}
+	return ""
+}
+
 // GetTenantBySubscriptionID calls azure API to get tenantID specific to subscription id.
-// It works on the recommended logic from Microsoft to apply regex on error response of the API.
+// It makes an unauthenticated call and reads the tenant from the authorization_uri of the
+// WWW-Authenticate challenge, as documented by Microsoft. If the challenge does not reveal the tenant,
+// it falls back to applying regex on the error message of an authenticated call.
 func (a *TenantFinder) GetTenantBySubscriptionID(id string) ([]string, error) {
-	resBody := ErrResponse{}
 	reqURL := fmt.Sprintf("/subscriptions/%s?api-version=2022-12-01", id)
+	url := instances[managementEndpoint].BaseURL + reqURL
+
+	resp, err := instances[managementEndpoint].anonClient.Get(url)
+	if err != nil {
+		return []string{}, err
+	}
 
-	url := instances[managementResourceIndex].BaseURL + reqURL
-	resp, _ := instances[managementResourceIndex].httpClient.Get(url)
+	defer resp.Body.Close()
 
-	// Precautionary check for server timeouts or outages
-	if resp != nil && resp.Body != nil {
-		// Decode the response body to the standard error format
-		err := json.NewDecoder(resp.Body).Decode(&resBody)
-		if err != nil {
-			return []string{}, fmt.Errorf("json decode %w", err)
+	if resp.StatusCode == http.StatusUnauthorized {
+		if tid := retrieveTenantIDFromChallenge(resp.Header.Get("WWW-Authenticate")); tid != "" {
+			return []string{tid}, nil
+		}
+	} else {
+		resBody := ErrResponse{}
+		if json.NewDecoder(resp.Body).Decode(&resBody) == nil {
+			if err := subscriptionError(id, resBody.Error); err != nil {
+				return []string{}, err
+			}
 		}
 	}
 
+	return a.getTenantFromErrMsg(id, url)
+}
+
+// getTenantFromErrMsg makes an authenticated call for the subscription and applies regex
+// on the error message to find the tenant ids.
+func (a *TenantFinder) getTenantFromErrMsg(id, url string) ([]string, error) {
+	resBody := ErrResponse{}
+
+	resp, err := instances[managementEndpoint].httpClient.Get(url)
+	if err != nil {
+		return []string{}, err
+	}
+
 	defer resp.Body.Close()
 
+	// Decode the response body to the standard error format
+	err = json.NewDecoder(resp.Body).Decode(&resBody)
+	if err != nil {
+		return []string{}, fmt.Errorf("json decode %w", err)
+	}
+
+	if err := subscriptionError(id, resBody.Error); err != nil {
+		return []string{}, err
+	}
+
 	return a.retrieveTenantIDsFromErrMsg(resBody.Error.Message), nil
 }
 
+// TenantMetadata is the tenant information published in the openid configuration of a tenant
+type TenantMetadata struct {
+	TenantID          string `json:"-"`
+	Issuer            string `json:"issuer"`
+	TenantRegionScope string `json:"tenant_region_scope"`
+	CloudInstanceName string `json:"cloud_instance_name"`
+}
+
+// openIDErrResponse is the error response of the login endpoint
+type openIDErrResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// GetTenantMetadata resolves the tenant of a domain, e.g. contoso.com, or of a tenant id through
+// the /.well-known/openid-configuration document of the configured login endpoint.
+func (a *TenantFinder) GetTenantMetadata(domain string) (*TenantMetadata, error) {
+	reqURL := fmt.Sprintf("%s/%s/v2.0/.well-known/openid-configuration", config.loginBaseURL(), url.PathEscape(domain))
+
+	resp, err := instances[managementEndpoint].anonClient.Get(reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errBody := openIDErrResponse{}
+		json.NewDecoder(resp.Body).Decode(&errBody) //nolint:errcheck
+		if errBody.Error == "invalid_tenant" || resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w %s", ErrTenantNotFound, domain)
+		}
+		return nil, fmt.Errorf("openid configuration returned %d %s", resp.StatusCode, errBody.ErrorDescription)
+	}
+
+	metadata := &TenantMetadata{}
+	if err := json.NewDecoder(resp.Body).Decode(metadata); err != nil {
+		return nil, fmt.Errorf("json decode %w", err)
+	}
+
+	// the issuer is https://login.microsoftonline.com/<tenant-id>/v2.0
+	if u, err := url.Parse(metadata.Issuer); err == nil {
+		metadata.TenantID, _, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	}
+	if metadata.TenantID == "" {
+		return nil, fmt.Errorf("unable to read tenant id from issuer %q", metadata.Issuer)
+	}
+	return metadata, nil
+}
+
+// subscriptionError returns a SubscriptionError if the azure error reports the subscription as unusable
+func subscriptionError(id string, e Error) error {
+	var err error
+	switch e.Code {
+	case "SubscriptionNotFound", "InvalidSubscriptionId":
+		err = ErrSubscriptionNotFound
+	case "DisabledSubscription", "ReadOnlyDisabledSubscription", "SubscriptionDeleted", "SubscriptionDisabled":
+		err = ErrSubscriptionDisabled
+	default:
+		return nil
+	}
+	return &SubscriptionError{SubscriptionID: id, Code: e.Code, Message: e.Message, Err: err}
+}
+
 // ErrResponse is the standard Azure API error response
 type ErrResponse struct {
 	Error Error `json:"error"`
@@ -0,0 +1,115 @@
+package azure
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	// CSVFormat writes results as comma separated values
+	CSVFormat = "csv"
+	// JSONFormat writes results as an indented JSON array
+	JSONFormat = "json"
+)
+
+// TenantResolution is the result of resolving the tenant(s) of a subscription
+type TenantResolution struct {
+	SubscriptionID string   `json:"subscription"`
+	Tenants        []string `json:"tenants"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// ResolveTenants resolves the tenant(s) of the given subscriptions concurrently with at most
+// workers calls in flight. Subscriptions are de-duplicated and the results keep the order of
+// their first appearance.
+func (a *TenantFinder) ResolveTenants(ids []string, workers int) []TenantResolution {
+	seen := map[string]bool{}
+	results := []TenantResolution{}
+	for _, id := range ids {
+		key := strings.ToLower(id)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		results = append(results, TenantResolution{SubscriptionID: id})
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// each worker writes to its own index of results only
+			for i := range jobs {
+				tenants, err := a.GetTenantBySubscriptionID(results[i].SubscriptionID)
+				results[i].Tenants = tenants
+				if err != nil {
+					results[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+
+	for i := range results {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// readSubscriptionIDs reads subscription ids, one per line, skipping empty lines and # comments.
+// For CSV input, the first column of each line is used, allowing a header row of subscription.
+func readSubscriptionIDs(r io.Reader) ([]string, error) {
+	ids := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, _, _ := strings.Cut(line, ",")
+		id = strings.Trim(strings.TrimSpace(id), `"`)
+		if id == "" || strings.EqualFold(id, "subscription") {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, scanner.Err()
+}
+
+// writeTenantResolutions writes the results as subscription,tenant(s),error rows in the given format.
+// Multiple tenants of a subscription are separated by a semicolon in CSV.
+func writeTenantResolutions(w io.Writer, results []TenantResolution, format string) error {
+	switch format {
+	case "", CSVFormat:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"subscription", "tenants", "error"}); err != nil {
+			return err
+		}
+		for _, res := range results {
+			if err := cw.Write([]string{res.SubscriptionID, strings.Join(res.Tenants, ";"), res.Error}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(results)
+	default:
+		return fmt.Errorf("invalid format %s, must be one of %s|%s", format, CSVFormat, JSONFormat)
+	}
+}
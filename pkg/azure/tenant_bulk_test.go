@@ -0,0 +1,165 @@
+package azure
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// stubARMServer returns a local ARM stub answering subscription requests with an authentication
+// challenge for the tenant derived from the subscription, or SubscriptionNotFound for unknown ones.
+func stubARMServer(t *testing.T, calls *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		id := strings.TrimPrefix(r.URL.Path, "/subscriptions/")
+		if strings.HasPrefix(id, "unknown") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":{"code":"SubscriptionNotFound","message":"The subscription '%s' could not be found."}}`, id)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer authorization_uri="https://login.windows.net/tenant-of-%s", error="invalid_token"`, id))
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":"AuthenticationFailed","message":"Authentication failed."}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func useStubARMServer(t *testing.T, server *httptest.Server) {
+	instance := instances[managementEndpoint]
+	baseURL, httpClient, anonClient := instance.BaseURL, instance.httpClient, instance.anonClient
+	t.Cleanup(func() {
+		instance.BaseURL, instance.httpClient, instance.anonClient = baseURL, httpClient, anonClient
+	})
+	instance.BaseURL, instance.httpClient, instance.anonClient = server.URL, server.Client(), server.Client()
+}
+
+func TestTenantFinder_ResolveTenants(t *testing.T) {
+	prepareTestEnvironment()
+	var calls int32
+	useStubARMServer(t, stubARMServer(t, &calls))
+
+	ids := []string{"sub-1", "sub-2", "SUB-1", "unknown-1", "sub-3", "sub-2"}
+	results := NewTenantFinder().ResolveTenants(ids, 3)
+
+	if len(results) != 4 || calls != 4 {
+		t.Fatalf("ResolveTenants() = %d results with %d calls, want 4 de-duplicated results", len(results), calls)
+	}
+	for i, want := range []string{"sub-1", "sub-2", "unknown-1", "sub-3"} {
+		if results[i].SubscriptionID != want {
+			t.Errorf("ResolveTenants()[%d] = %v, want %v", i, results[i].SubscriptionID, want)
+		}
+	}
+	if results[0].Tenants[0] != "tenant-of-sub-1" || results[0].Error != "" {
+		t.Errorf("ResolveTenants()[0] = %+v, want tenant-of-sub-1", results[0])
+	}
+	if !strings.Contains(results[2].Error, "subscription not found") {
+		t.Errorf("ResolveTenants()[2] = %+v, want subscription not found", results[2])
+	}
+}
+
+func Test_commandGetConfig_Execute_bulk(t *testing.T) {
+	prepareTestEnvironment()
+	var calls int32
+	useStubARMServer(t, stubARMServer(t, &calls))
+
+	dir := t.TempDir()
+	subsFile := filepath.Join(dir, "subs.txt")
+	os.WriteFile(subsFile, []byte("subscription\n# comment\nsub-1\n\nunknown-1,extra\nsub-1\n"), 0o600) //nolint:errcheck
+	outFile := filepath.Join(dir, "out.json")
+
+	tests := []struct {
+		name      string
+		args      []string
+		stdin     string
+		want      []string
+		wantInOut []string
+	}{
+		{
+			name: "bulk from file as csv",
+			args: []string{"-f", subsFile, "-workers", "2"},
+			want: []string{
+				"subscription,tenants,error",
+				"sub-1,tenant-of-sub-1,",
+				"unknown-1,,subscription not found unknown-1 (SubscriptionNotFound)",
+			},
+		},
+		{
+			name: "bulk from file with the tenant mode flag",
+			args: []string{"-t", "-f", subsFile},
+			want: []string{
+				"subscription,tenants,error",
+				"sub-1,tenant-of-sub-1,",
+			},
+		},
+		{
+			name: "bulk with a subscription argument",
+			args: []string{"-t", "sub-1", "-f", subsFile},
+			want: []string{"provide either a subscription id or a file with -f"},
+		},
+		{
+			name: "tenant mode flag without subscription",
+			args: []string{"-t"},
+			want: []string{"provide a subscription id after -t"},
+		},
+		{
+			name:  "bulk from stdin as json",
+			args:  []string{"-f", "-", "-format", "json"},
+			stdin: "sub-2\n",
+			want:  []string{`"subscription": "sub-2"`, `"tenant-of-sub-2"`},
+		},
+		{
+			name:      "bulk written to output file",
+			args:      []string{"-f", subsFile, "-format", "json", "-out", outFile},
+			want:      []string{"results written to " + outFile},
+			wantInOut: []string{`"tenant-of-sub-1"`, `"error": "subscription not found`},
+		},
+		{
+			name: "bulk with invalid format",
+			args: []string{"-f", subsFile, "-format", "xml"},
+			want: []string{"invalid format xml"},
+		},
+		{
+			name: "bulk with missing file",
+			args: []string{"-f", filepath.Join(dir, "missing.txt")},
+			want: []string{"no such file or directory"},
+		},
+		{
+			name: "bulk with invalid flag",
+			args: []string{"-f", subsFile, "-x"},
+			want: []string{"flag provided but not defined"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdin = strings.NewReader(tt.stdin)
+			defer func() { stdin = os.Stdin }()
+
+			c := NewCommandModule().CommandGetConfig
+			var got string
+			if err := c.GetFlagSet().Parse(tt.args); err != nil {
+				got = err.Error()
+			} else {
+				got = c.Execute()
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandGetConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+			if len(tt.wantInOut) > 0 {
+				b, _ := os.ReadFile(outFile)
+				for _, want := range tt.wantInOut {
+					if !strings.Contains(string(b), want) {
+						t.Errorf("commandGetConfig.Execute() output file = %v, want %v", string(b), want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package azure
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_retrieveTenantIDFromChallenge(t *testing.T) {
+	tests := []struct {
+		name      string
+		challenge string
+		want      string
+	}{
+		{
+			name:      "authorization uri of public cloud",
+			challenge: `Bearer authorization_uri="https://login.windows.net/0b37927b-359e-4a60-8aac-67f88409ac5a", error="invalid_token", error_description="The authentication failed because of missing 'Authorization' header."`,
+			want:      "0b37927b-359e-4a60-8aac-67f88409ac5a",
+		},
+		{
+			name:      "authorization uri with trailing path",
+			challenge: `Bearer error="invalid_token", Authorization_URI="https://login.microsoftonline.us/0b37927b-359e-4a60-8aac-67f88409ac5a/oauth2/authorize"`,
+			want:      "0b37927b-359e-4a60-8aac-67f88409ac5a",
+		},
+		{
+			name:      "challenge without authorization uri",
+			challenge: `Bearer error="invalid_token"`,
+			want:      "",
+		},
+		{
+			name:      "empty challenge",
+			challenge: "",
+			want:      "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retrieveTenantIDFromChallenge(tt.challenge); got != tt.want {
+				t.Errorf("retrieveTenantIDFromChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_subscriptionError(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want error
+	}{
+		{name: "subscription not found", code: "SubscriptionNotFound", want: ErrSubscriptionNotFound},
+		{name: "subscription disabled", code: "ReadOnlyDisabledSubscription", want: ErrSubscriptionDisabled},
+		{name: "subscription deleted", code: "SubscriptionDeleted", want: ErrSubscriptionDisabled},
+		{name: "other error", code: "InvalidAuthenticationTokenTenant", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := subscriptionError("sub", Error{Code: tt.code})
+			if tt.want == nil && err != nil {
+				t.Errorf("subscriptionError() = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("subscriptionError() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
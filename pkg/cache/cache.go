@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/state"
+)
+
+const (
+	// fileName is the name of the cache file under the cache directory
+	fileName = "cache.json"
+)
+
+var (
+	// NoCache disables reading from and writing to the on-disk cache
+	NoCache bool
+	// Refresh ignores the cached entries and stores freshly fetched values
+	Refresh bool
+
+	// mu guards the cache file against concurrent access within the process
+	mu sync.Mutex
+	// now returns the current time, replaceable in tests
+	now = time.Now
+)
+
+// entry represents a single cached value along with its expiry
+type entry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+// Info describes a cached entry without its value
+type Info struct {
+	Key       string
+	Size      int
+	ExpiresAt time.Time
+	Expired   bool
+}
+
+// BindFlags registers the cache flags on the given flag set
+func BindFlags(flagSet *flag.FlagSet) {
+	flagSet.BoolVar(&NoCache, "no-cache", false, "do not use the on-disk cache")
+	flagSet.BoolVar(&Refresh, "refresh", false, "ignore cached values and refresh the on-disk cache")
+}
+
+// Dir returns the directory holding the cache file.
+// UNFOLD_CACHE_DIR takes precedence over the user cache directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("UNFOLD_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unfold"), nil
+}
+
+// Path returns the path of the cache file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// disabled reports whether the cache must be bypassed entirely
+func disabled() bool {
+	return NoCache || os.Getenv("UNFOLD_NO_CACHE") != ""
+}
+
+// Get decodes the cached value for the key into v.
+// It reports false when the cache is disabled, refreshed, or the entry is missing or expired.
+func Get(key string, v any) bool {
+	if disabled() || Refresh {
+		return false
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return false
+	}
+	e, ok := entries[key]
+	if !ok || !now().Before(e.ExpiresAt) {
+		return false
+	}
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Set stores v for the key for the given ttl. The cache is best effort,
+// failures to persist the value are ignored.
+func Set(key string, v any, ttl time.Duration) {
+	if disabled() {
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return
+	}
+	entries[key] = entry{Value: b, ExpiresAt: now().Add(ttl)}
+	save(entries) //nolint:errcheck
+}
+
+// Delete removes the entry for the key from the cache
+func Delete(key string) {
+	if disabled() {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return
+	}
+	if _, ok := entries[key]; ok {
+		delete(entries, key)
+		save(entries) //nolint:errcheck
+	}
+}
+
+// List returns the information of all cached entries sorted by key
+func List() ([]Info, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]Info, 0, len(entries))
+	for key, e := range entries {
+		infos = append(infos, Info{
+			Key:       key,
+			Size:      len(e.Value),
+			ExpiresAt: e.ExpiresAt,
+			Expired:   !now().Before(e.ExpiresAt),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	return infos, nil
+}
+
+// Clear removes the cached entries matching the prefix and returns the number of removed entries.
+// When expiredOnly is set, only the expired entries are removed.
+func Clear(prefix string, expiredOnly bool) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := load()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for key, e := range entries {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if expiredOnly && now().Before(e.ExpiresAt) {
+			continue
+		}
+		delete(entries, key)
+		removed++
+	}
+
+	return removed, save(entries)
+}
+
+// load reads the cache entries from the cache file, a missing file is an empty cache
+func load() (map[string]entry, error) {
+	entries := map[string]entry{}
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A corrupted cache is discarded rather than failing the command
+	if json.Unmarshal(b, &entries) != nil {
+		return map[string]entry{}, nil
+	}
+	return entries, nil
+}
+
+// save writes the cache entries atomically to the cache file
+func save(entries map[string]entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return state.WriteFileAtomic(path, b)
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func prepareTestCache(t *testing.T) {
+	t.Setenv("UNFOLD_CACHE_DIR", t.TempDir())
+	t.Setenv("UNFOLD_NO_CACHE", "")
+	NoCache, Refresh = false, false
+	now = time.Now
+}
+
+func TestGetSet(t *testing.T) {
+	type value struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		modify  func()
+		wantHit bool
+	}{
+		{
+			name:    "cache hit",
+			ttl:     time.Hour,
+			wantHit: true,
+		},
+		{
+			name:    "cache entry expired",
+			ttl:     time.Hour,
+			modify:  func() { now = func() time.Time { return time.Now().Add(2 * time.Hour) } },
+			wantHit: false,
+		},
+		{
+			name:    "cache refreshed",
+			ttl:     time.Hour,
+			modify:  func() { Refresh = true },
+			wantHit: false,
+		},
+		{
+			name:    "cache disabled by flag",
+			ttl:     time.Hour,
+			modify:  func() { NoCache = true },
+			wantHit: false,
+		},
+		{
+			name:    "cache disabled by env",
+			ttl:     time.Hour,
+			modify:  func() { os.Setenv("UNFOLD_NO_CACHE", "true") },
+			wantHit: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepareTestCache(t)
+			Set("test/key", value{Name: "test"}, tt.ttl)
+			if tt.modify != nil {
+				tt.modify()
+			}
+
+			got := value{}
+			hit := Get("test/key", &got)
+			if hit != tt.wantHit {
+				t.Errorf("Get() = %v, want %v", hit, tt.wantHit)
+			}
+			if hit && got.Name != "test" {
+				t.Errorf("Get() value = %v, want %v", got.Name, "test")
+			}
+		})
+	}
+}
+
+func TestDeleteAndClear(t *testing.T) {
+	prepareTestCache(t)
+	Set("google/group/a", "a", time.Hour)
+	Set("google/group/b", "b", -time.Hour)
+	Set("azure/plans/c", "c", time.Hour)
+
+	Delete("google/group/a")
+	var v string
+	if Get("google/group/a", &v) {
+		t.Errorf("Get() after Delete() = true, want false")
+	}
+
+	removed, err := Clear("", true)
+	if err != nil || removed != 1 {
+		t.Errorf("Clear() expired = %v, %v, want 1, nil", removed, err)
+	}
+
+	removed, err = Clear("azure/", false)
+	if err != nil || removed != 1 {
+		t.Errorf("Clear() prefix = %v, %v, want 1, nil", removed, err)
+	}
+
+	infos, err := List()
+	if err != nil || len(infos) != 0 {
+		t.Errorf("List() = %v, %v, want empty", infos, err)
+	}
+}
+
+func TestCorruptedCache(t *testing.T) {
+	prepareTestCache(t)
+	path, _ := Path()
+	os.MkdirAll(filepath.Dir(path), 0o700)          //nolint:errcheck
+	os.WriteFile(path, []byte(`{corrupted`), 0o600) //nolint:errcheck
+
+	var v string
+	if Get("any", &v) {
+		t.Errorf("Get() on corrupted cache = true, want false")
+	}
+	Set("any", "value", time.Hour)
+	if !Get("any", &v) || v != "value" {
+		t.Errorf("Get() after Set() on corrupted cache = %v, want value", v)
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("UNFOLD_CACHE_DIR", "")
+	dir, err := Dir()
+	if err != nil {
+		t.Skipf("user cache dir not available: %v", err)
+	}
+	if filepath.Base(dir) != "unfold" {
+		t.Errorf("Dir() = %v, want unfold directory", dir)
+	}
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandClearConfig represents the configuration for the clear command
+type commandClearConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		Prefix  *string
+		Expired *bool
+	}
+}
+
+// Execute executes the clear command
+func (c commandClearConfig) Execute() string {
+	removed, err := Clear(*c.Opts.Prefix, *c.Opts.Expired)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to clear the cache %s", helpers.RedValue(err.Error()))
+	}
+	return fmt.Sprintf("[unfold] removed %s cached entries", helpers.GreenValue(fmt.Sprint(removed)))
+}
+
+// GetFlagSet returns the flag set for the clear command
+func (c commandClearConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandClearConfig fetches the command clear config
+func fetchCommandClearConfig() commandClearConfig {
+	flagSet := flag.NewFlagSet(commands.Clear, flag.ContinueOnError)
+	return commandClearConfig{
+		Opts: struct {
+			Prefix  *string
+			Expired *bool
+		}{
+			Prefix:  flagSet.String("p", "", "only clear entries with keys starting with the prefix, e.g. google/ or azure/"),
+			Expired: flagSet.Bool("expired", false, "only clear expired entries"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+func Test_commandClearConfig_Execute(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "clear all entries",
+			want: "removed " + helpers.GreenValue("3") + " cached entries",
+		},
+		{
+			name: "clear entries with prefix",
+			args: []string{"-p", "google/"},
+			want: "removed " + helpers.GreenValue("2") + " cached entries",
+		},
+		{
+			name: "clear expired entries",
+			args: []string{"-expired"},
+			want: "removed " + helpers.GreenValue("1") + " cached entries",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepareTestCache(t)
+			Set("google/group/a", "a", time.Hour)
+			Set("google/group/b", "b", -time.Hour)
+			Set("azure/plans/c", "c", time.Hour)
+
+			c := NewCommandModule().CommandClearConfig
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandClearConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandShowConfig represents the configuration for the show command
+type commandShowConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		Prefix *string
+	}
+}
+
+// Execute executes the show command
+func (c commandShowConfig) Execute() string {
+	path, err := Path()
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	infos, err := List()
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to read the cache %s", helpers.RedValue(err.Error()))
+	}
+
+	sb := &strings.Builder{}
+	w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSIZE\tEXPIRES") //nolint:errcheck
+
+	count := 0
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Key, *c.Opts.Prefix) {
+			continue
+		}
+		expires := fmt.Sprintf("in %s", info.ExpiresAt.Sub(now()).Round(time.Second))
+		if info.Expired {
+			expires = "expired"
+		}
+		fmt.Fprintf(w, "%s\t%dB\t%s\n", info.Key, info.Size, expires) //nolint:errcheck
+		count++
+	}
+	w.Flush() //nolint:errcheck
+
+	if count == 0 {
+		return fmt.Sprintf("[unfold] cache at %s is empty", path)
+	}
+	return fmt.Sprintf("[unfold] %d cached entries at %s\n%s", count, path, sb.String())
+}
+
+// GetFlagSet returns the flag set for the show command
+func (c commandShowConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandShowConfig fetches the command show config
+func fetchCommandShowConfig() commandShowConfig {
+	flagSet := flag.NewFlagSet(commands.Show, flag.ContinueOnError)
+	return commandShowConfig{
+		Opts: struct {
+			Prefix *string
+		}{
+			Prefix: flagSet.String("p", "", "only show entries with keys starting with the prefix, e.g. google/ or azure/"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_commandShowConfig_Execute(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		entries map[string]time.Duration
+		want    []string
+	}{
+		{
+			name: "show empty cache",
+			want: []string{"is empty"},
+		},
+		{
+			name: "show all entries",
+			entries: map[string]time.Duration{
+				"google/group/test-group": time.Hour,
+				"azure/plans/product":     -time.Hour,
+			},
+			want: []string{"2 cached entries", "google/group/test-group", "in 1h0m0s", "azure/plans/product", "expired"},
+		},
+		{
+			name: "show entries with prefix",
+			args: []string{"-p", "google/"},
+			entries: map[string]time.Duration{
+				"google/group/test-group": time.Hour,
+				"azure/plans/product":     time.Hour,
+			},
+			want: []string{"1 cached entries", "google/group/test-group"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prepareTestCache(t)
+			fixed := time.Now()
+			now = func() time.Time { return fixed }
+			for key, ttl := range tt.entries {
+				Set(key, key, ttl)
+			}
+
+			c := NewCommandModule().CommandShowConfig
+			c.GetFlagSet().Parse(tt.args)
+			got := c.Execute()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandShowConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package cache
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandShowConfig  commandShowConfig
+	CommandClearConfig commandClearConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	return &CommandModule{
+		CommandShowConfig:  fetchCommandShowConfig(),
+		CommandClearConfig: fetchCommandClearConfig(),
+	}
+}
@@ -2,14 +2,51 @@ package commands
 
 const (
 	// Commands
-	Azure   = "azure"
-	Google  = "google"
-	JWT     = "jwt"
-	Version = "--version"
+	Azure    = "azure"
+	Google   = "google"
+	JWT      = "jwt"
+	Cache    = "cache"
+	SCIM     = "scim"
+	GitHub   = "github"
+	Workflow = "workflow"
+	Apply    = "apply"
+	Audit    = "audit"
+	Undo     = "undo"
+	Version  = "--version"
 
 	// Sub-commands
 	Get       = "get"
 	Search    = "search"
 	Configure = "configure"
 	Decode    = "decode"
+	Group     = "group"
+	Show      = "show"
+	Clear     = "clear"
+	Preview   = "preview"
+	Offer     = "offer"
+	Jobs      = "jobs"
+	SaaS      = "saas"
+	Metering  = "metering"
+	Export    = "export"
+	Run       = "run"
+	History   = "history"
+	// Value is the sub-command of the commands taking a value instead, e.g. unfold apply plan.json
+	Value = ""
+
+	// Actions
+	Create          = "create"
+	Describe        = "describe"
+	Update          = "update"
+	Delete          = "delete"
+	Add             = "add"
+	Remove          = "remove"
+	List            = "list"
+	Status          = "status"
+	Publish         = "publish"
+	Refresh         = "refresh"
+	Resolve         = "resolve"
+	Activate        = "activate"
+	Operations      = "operations"
+	UpdateOperation = "update-operation"
+	Post            = "post"
 )
@@ -0,0 +1,103 @@
+package directory
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// CommandConfigureConfig represents the configuration for the configure command of a group provider
+type CommandConfigureConfig struct {
+	Provider      GroupProvider
+	FlagSet       *flag.FlagSet
+	AddRemoveOpts struct {
+		RemoveFlag *bool
+		EmailID    *string
+		Group      *string
+		Role       *string
+		// PlanOut is only bound when the provider is a Planner
+		PlanOut *string
+	}
+}
+
+// Execute executes the configure command
+func (c CommandConfigureConfig) Execute() string {
+	if c.AddRemoveOpts.PlanOut != nil && *c.AddRemoveOpts.PlanOut != "" {
+		return c.plan()
+	}
+
+	if *c.AddRemoveOpts.RemoveFlag {
+		err := c.Provider.RemoveMember(*c.AddRemoveOpts.Group, *c.AddRemoveOpts.EmailID)
+		if err != nil {
+			return fmt.Sprintf("[unfold] unable to remove the member %s", helpers.RedValue(err.Error()))
+		}
+		return "[unfold] successfully removed the member from the group"
+	}
+
+	role, err := validRole(c.Provider, *c.AddRemoveOpts.Role)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	err = c.Provider.AddMember(*c.AddRemoveOpts.Group, *c.AddRemoveOpts.EmailID, role)
+	if err != nil {
+		return fmt.Sprintf("[unfold] failed to add member to the given group %s", helpers.RedValue(err.Error()))
+	}
+
+	return "[unfold] successfully added the member to the given group"
+}
+
+// plan writes the membership change to the plan file instead of executing it
+func (c CommandConfigureConfig) plan() string {
+	role := ""
+	if !*c.AddRemoveOpts.RemoveFlag {
+		var err error
+		if role, err = validRole(c.Provider, *c.AddRemoveOpts.Role); err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+	}
+	op, err := c.Provider.(Planner).PlanMember(*c.AddRemoveOpts.Group, *c.AddRemoveOpts.EmailID, role, *c.AddRemoveOpts.RemoveFlag)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	summary, err := plan.Save(*c.AddRemoveOpts.PlanOut, *op)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to write the plan %s", helpers.RedValue(err.Error()))
+	}
+	return fmt.Sprintf("[unfold] %s", summary)
+}
+
+// GetFlagSet returns the flag set for the configure command
+func (c CommandConfigureConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// NewCommandConfigureConfig returns the configure command of the provider, bindFlags registers the provider specific flags
+func NewCommandConfigureConfig(provider GroupProvider, bindFlags ...func(*flag.FlagSet)) CommandConfigureConfig {
+	flagSet := flag.NewFlagSet(commands.Configure, flag.ContinueOnError)
+	for _, bind := range bindFlags {
+		bind(flagSet)
+	}
+	c := CommandConfigureConfig{
+		Provider: provider,
+		AddRemoveOpts: struct {
+			RemoveFlag *bool
+			EmailID    *string
+			Group      *string
+			Role       *string
+			PlanOut    *string
+		}{
+			RemoveFlag: flagSet.Bool("r", false, "remove emailID from respective group"),
+			EmailID:    flagSet.String("id", "", "provide a valid emaildID"),
+			Group:      flagSet.String("g", "", "provide a valid group"),
+			Role:       flagSet.String("role", "", "role of the added member, defaults to the plain member role"),
+		},
+		FlagSet: flagSet,
+	}
+	if _, ok := provider.(Planner); ok {
+		c.AddRemoveOpts.PlanOut = flagSet.String("plan-out", "", "write the resolved operation to the plan file instead of executing it")
+	}
+	return c
+}
@@ -0,0 +1,148 @@
+package directory
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+func TestCommandConfigureConfig_Execute(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		err       error
+		want      string
+		wantRoles map[string][]string
+	}{
+		{
+			name:      "add member with default role",
+			args:      []string{"-g", "team@example.com", "-id", "bob@example.com"},
+			want:      "successfully added the member to the given group",
+			wantRoles: map[string][]string{"bob@example.com": {"MEMBER"}},
+		},
+		{
+			name:      "add member with role",
+			args:      []string{"-g", "team@example.com", "-id", "bob@example.com", "-role", "owner"},
+			want:      "successfully added the member to the given group",
+			wantRoles: map[string][]string{"bob@example.com": {"OWNER"}},
+		},
+		{
+			name: "add member with invalid role",
+			args: []string{"-g", "team@example.com", "-id", "bob@example.com", "-role", "admin"},
+			want: "invalid role admin, use MEMBER|MANAGER|OWNER",
+		},
+		{
+			name: "add member error",
+			args: []string{"-g", "team@example.com", "-id", "bob@example.com"},
+			err:  errors.New("http call error"),
+			want: "failed to add member to the given group",
+		},
+		{
+			name:      "remove member",
+			args:      []string{"-g", "team@example.com", "-id", "alice@example.com", "-r"},
+			want:      "successfully removed the member from the group",
+			wantRoles: map[string][]string{"alice@example.com": nil},
+		},
+		{
+			name: "remove non member",
+			args: []string{"-g", "team@example.com", "-id", "bob@example.com", "-r"},
+			want: "unable to remove the member",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeProvider()
+			provider.err = tt.err
+			c := NewCommandConfigureConfig(provider)
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("CommandConfigureConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			for id, roles := range tt.wantRoles {
+				if got := strings.Join(provider.members[id], ","); got != strings.Join(roles, ",") {
+					t.Errorf("CommandConfigureConfig.Execute() roles of %s = %v, want %v", id, got, roles)
+				}
+			}
+		})
+	}
+}
+
+// planningProvider is a fakeProvider which resolves the membership changes into plan operations
+type planningProvider struct {
+	*fakeProvider
+}
+
+func (p planningProvider) PlanMember(groupID, memberID, role string, remove bool) (*plan.Operation, error) {
+	if _, err := p.LookupGroup(groupID); err != nil {
+		return nil, err
+	}
+	mode := plan.AddMode
+	if remove {
+		mode = plan.RemoveMode
+	}
+	return &plan.Operation{Kind: "fake-membership", Mode: mode, Group: groupID, Member: memberID, Role: role}, nil
+}
+
+func TestCommandConfigureConfig_PlanOut(t *testing.T) {
+	if c := NewCommandConfigureConfig(newFakeProvider()); c.GetFlagSet().Lookup("plan-out") != nil {
+		t.Fatal("NewCommandConfigureConfig() binds plan-out for a provider which cannot plan")
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		want   string
+		wantOp *plan.Operation
+	}{
+		{
+			name:   "plan add with role",
+			args:   []string{"-g", "team@example.com", "-id", "bob@example.com", "-role", "owner"},
+			want:   "plan with 1 operation(s) written to",
+			wantOp: &plan.Operation{Kind: "fake-membership", Mode: plan.AddMode, Group: "team@example.com", Member: "bob@example.com", Role: "OWNER"},
+		},
+		{
+			name:   "plan remove",
+			args:   []string{"-g", "team@example.com", "-id", "alice@example.com", "-r"},
+			want:   "plan with 1 operation(s) written to",
+			wantOp: &plan.Operation{Kind: "fake-membership", Mode: plan.RemoveMode, Group: "team@example.com", Member: "alice@example.com"},
+		},
+		{
+			name: "plan add with invalid role",
+			args: []string{"-g", "team@example.com", "-id", "bob@example.com", "-role", "admin"},
+			want: "invalid role admin",
+		},
+		{
+			name: "plan unknown group",
+			args: []string{"-g", "other@example.com", "-id", "bob@example.com"},
+			want: "group not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeProvider()
+			path := filepath.Join(t.TempDir(), "plan.json")
+			c := NewCommandConfigureConfig(planningProvider{provider})
+			c.GetFlagSet().Parse(append(tt.args, "-plan-out", path))
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("CommandConfigureConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			if len(provider.members) != 1 || provider.members["alice@example.com"] == nil {
+				t.Errorf("CommandConfigureConfig.Execute() changed the members while planning: %v", provider.members)
+			}
+			if tt.wantOp == nil {
+				return
+			}
+			p, err := plan.Read(path)
+			if err != nil {
+				t.Fatalf("plan.Read() error = %v", err)
+			}
+			if got := p.Operations[0]; !reflect.DeepEqual(got, *tt.wantOp) {
+				t.Errorf("CommandConfigureConfig.Execute() planned %+v, want %+v", got, *tt.wantOp)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package directory
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// CommandExportConfig represents the configuration for the export command of a group provider
+type CommandExportConfig struct {
+	Provider   GroupProvider
+	FlagSet    *flag.FlagSet
+	ExportOpts struct {
+		Group  *string
+		Format *string
+		Out    *string
+	}
+}
+
+// Execute executes the export command
+func (c CommandExportConfig) Execute() string {
+	if *c.ExportOpts.Group == "" {
+		return "[unfold] group cannot be empty"
+	}
+
+	members, err := c.Provider.ListMembers(*c.ExportOpts.Group)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	sb := &strings.Builder{}
+	if err := WriteMembers(sb, members, *c.ExportOpts.Format); err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	summary := fmt.Sprintf("[unfold] exported %s member(s) of %s", helpers.GreenValue(fmt.Sprint(len(members))), *c.ExportOpts.Group)
+	if *c.ExportOpts.Out == "" {
+		return fmt.Sprintf("%s\n%s", summary, strings.TrimSuffix(sb.String(), "\n"))
+	}
+	if err := os.WriteFile(*c.ExportOpts.Out, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Sprintf("[unfold] unable to write members %s", err.Error())
+	}
+	return fmt.Sprintf("%s, members written to %s", summary, *c.ExportOpts.Out)
+}
+
+// GetFlagSet returns the flag set for the export command
+func (c CommandExportConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// NewCommandExportConfig returns the export command of the provider, bindFlags registers the provider specific flags
+func NewCommandExportConfig(provider GroupProvider, bindFlags ...func(*flag.FlagSet)) CommandExportConfig {
+	flagSet := flag.NewFlagSet(commands.Export, flag.ContinueOnError)
+	for _, bind := range bindFlags {
+		bind(flagSet)
+	}
+	return CommandExportConfig{
+		Provider: provider,
+		ExportOpts: struct {
+			Group  *string
+			Format *string
+			Out    *string
+		}{
+			Group:  flagSet.String("g", "", "provide a valid group"),
+			Format: flagSet.String("format", CSVFormat, "output format csv|json"),
+			Out:    flagSet.String("out", "", "write the members to the file instead of stdout"),
+		},
+		FlagSet: flagSet,
+	}
+}
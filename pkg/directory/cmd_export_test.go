@@ -0,0 +1,59 @@
+package directory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+func TestCommandExportConfig_Execute(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "members.json")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "export as csv",
+			args: []string{"-g", "team@example.com"},
+			want: "exported " + helpers.GreenValue("1") + " member(s) of team@example.com\nmember,roles,membership\nalice@example.com,MEMBER;OWNER,groups/team/memberships/alice@example.com",
+		},
+		{
+			name: "export to file",
+			args: []string{"-g", "team@example.com", "-format", "json", "-out", out},
+			want: "members written to " + out,
+		},
+		{
+			name: "export unknown group",
+			args: []string{"-g", "other@example.com"},
+			want: "group not found",
+		},
+		{
+			name: "export without group",
+			args: []string{},
+			want: "group cannot be empty",
+		},
+		{
+			name: "export to invalid path",
+			args: []string{"-g", "team@example.com", "-out", filepath.Join(out, "missing", "members.csv")},
+			want: "unable to write members",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandExportConfig(newFakeProvider())
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("CommandExportConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil || !strings.Contains(string(b), `"id": "alice@example.com"`) {
+		t.Errorf("exported file = %q, %v", string(b), err)
+	}
+}
@@ -0,0 +1,57 @@
+package directory
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// CommandSearchConfig represents the configuration for the search command of a group provider
+type CommandSearchConfig struct {
+	Provider GroupProvider
+	Members  struct {
+		ID    *string
+		Group *string
+	}
+	FlagSet *flag.FlagSet
+}
+
+// Execute executes the search command
+func (c CommandSearchConfig) Execute() string {
+	if *c.Members.ID != "" {
+		found, err := c.Provider.CheckMember(*c.Members.Group, *c.Members.ID)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+
+		return fmt.Sprintf("[unfold] emailID is found to be %s of the group with membership name %s", helpers.GreenValue(strings.Join(found.Roles, ",")), helpers.GreenValue(found.Name))
+	}
+	return "[unfold] something went wrong"
+}
+
+// GetFlagSet returns the flag set for the search command
+func (c CommandSearchConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// NewCommandSearchConfig returns the search command of the provider, bindFlags registers the provider specific flags
+func NewCommandSearchConfig(provider GroupProvider, bindFlags ...func(*flag.FlagSet)) CommandSearchConfig {
+	flagSet := flag.NewFlagSet(commands.Search, flag.ContinueOnError)
+	for _, bind := range bindFlags {
+		bind(flagSet)
+	}
+	return CommandSearchConfig{
+		Provider: provider,
+		Members: struct {
+			ID    *string
+			Group *string
+		}{
+			ID:    flagSet.String("id", "", "used to search email in group membership"),
+			Group: flagSet.String("g", "", "provide group id"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,56 @@
+package directory
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+func TestCommandSearchConfig_Execute(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  error
+		want string
+	}{
+		{
+			name: "member found",
+			args: []string{"-g", "team@example.com", "-id", "alice@example.com"},
+			want: "emailID is found to be " + helpers.GreenValue("MEMBER,OWNER") + " of the group with membership name " + helpers.GreenValue("groups/team/memberships/alice@example.com"),
+		},
+		{
+			name: "member not found",
+			args: []string{"-g", "team@example.com", "-id", "bob@example.com"},
+			want: "member not found",
+		},
+		{
+			name: "provider error",
+			args: []string{"-g", "team@example.com", "-id", "alice@example.com"},
+			err:  errors.New("http call error"),
+			want: "http call error",
+		},
+		{
+			name: "flags not provided",
+			args: []string{},
+			want: "something went wrong",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeProvider()
+			provider.err = tt.err
+			bound := false
+			c := NewCommandSearchConfig(provider, func(*flag.FlagSet) { bound = true })
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("CommandSearchConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			if !bound {
+				t.Errorf("NewCommandSearchConfig() did not bind the provider flags")
+			}
+		})
+	}
+}
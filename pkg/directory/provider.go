@@ -0,0 +1,102 @@
+package directory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+const (
+	// CSVFormat renders the members as CSV
+	CSVFormat = "csv"
+	// JSONFormat renders the members as JSON
+	JSONFormat = "json"
+)
+
+// ErrMemberNotFound is returned when the member is not part of the group
+var ErrMemberNotFound = errors.New("member not found")
+
+// Group represents a group of a directory backend
+type Group struct {
+	// ID is the identifier the group was looked up with, e.g. its email
+	ID string `json:"id"`
+	// Name is the resource name of the group in the backend
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// Member represents the membership of a user in a group
+type Member struct {
+	// ID is the email or user name of the member
+	ID string `json:"id"`
+	// Name is the resource name of the membership in the backend
+	Name  string   `json:"name,omitempty"`
+	Roles []string `json:"roles"`
+}
+
+// GroupProvider is a directory backend managing the memberships of its groups.
+// Groups and members are identified by the ids accepted by the backend, e.g. emails.
+type GroupProvider interface {
+	// LookupGroup returns the group identified by groupID
+	LookupGroup(groupID string) (*Group, error)
+	// ListMembers returns the members of the group
+	ListMembers(groupID string) ([]Member, error)
+	// CheckMember returns the membership of the member, ErrMemberNotFound when not a member
+	CheckMember(groupID, memberID string) (*Member, error)
+	// AddMember adds the member to the group with the role, one of Roles
+	AddMember(groupID, memberID, role string) error
+	// RemoveMember removes the member from the group
+	RemoveMember(groupID, memberID string) error
+	// Roles returns the membership roles supported by the backend, the first one being the default
+	Roles() []string
+}
+
+// Planner is implemented by the providers able to resolve a membership change into a plan operation,
+// written with --plan-out for review and executed later by unfold apply
+type Planner interface {
+	// PlanMember returns the operation adding the member with the role, or removing it when remove is set
+	PlanMember(groupID, memberID, role string, remove bool) (*plan.Operation, error)
+}
+
+// validRole returns the role, or the default role of the provider when empty, if supported by the provider
+func validRole(provider GroupProvider, role string) (string, error) {
+	roles := provider.Roles()
+	if role == "" {
+		return roles[0], nil
+	}
+	for _, r := range roles {
+		if strings.EqualFold(r, role) {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("invalid role %s, use %s", role, strings.Join(roles, "|"))
+}
+
+// WriteMembers writes the members of the group in the format
+func WriteMembers(w io.Writer, members []Member, format string) error {
+	switch format {
+	case "", CSVFormat:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"member", "roles", "membership"}); err != nil {
+			return err
+		}
+		for _, m := range members {
+			if err := cw.Write([]string{m.ID, strings.Join(m.Roles, ";"), m.Name}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case JSONFormat:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", " ")
+		return enc.Encode(members)
+	default:
+		return fmt.Errorf("invalid format %s, must be one of %s|%s", format, CSVFormat, JSONFormat)
+	}
+}
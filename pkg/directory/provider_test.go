@@ -0,0 +1,119 @@
+package directory
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeProvider is an in-memory group provider of a single group
+type fakeProvider struct {
+	group   string
+	members map[string][]string
+	err     error
+}
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{group: "team@example.com", members: map[string][]string{"alice@example.com": {"MEMBER", "OWNER"}}}
+}
+
+func (f *fakeProvider) LookupGroup(groupID string) (*Group, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if groupID != f.group {
+		return nil, errors.New("group not found")
+	}
+	return &Group{ID: groupID, Name: "groups/team"}, nil
+}
+
+func (f *fakeProvider) ListMembers(groupID string) ([]Member, error) {
+	if _, err := f.LookupGroup(groupID); err != nil {
+		return nil, err
+	}
+	members := []Member{}
+	for _, id := range []string{"alice@example.com", "bob@example.com"} {
+		if roles, ok := f.members[id]; ok {
+			members = append(members, Member{ID: id, Name: "groups/team/memberships/" + id, Roles: roles})
+		}
+	}
+	return members, nil
+}
+
+func (f *fakeProvider) CheckMember(groupID, memberID string) (*Member, error) {
+	if _, err := f.LookupGroup(groupID); err != nil {
+		return nil, err
+	}
+	roles, ok := f.members[memberID]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+	return &Member{ID: memberID, Name: "groups/team/memberships/" + memberID, Roles: roles}, nil
+}
+
+func (f *fakeProvider) AddMember(groupID, memberID, role string) error {
+	if _, err := f.LookupGroup(groupID); err != nil {
+		return err
+	}
+	f.members[memberID] = []string{role}
+	return nil
+}
+
+func (f *fakeProvider) RemoveMember(groupID, memberID string) error {
+	if _, err := f.CheckMember(groupID, memberID); err != nil {
+		return err
+	}
+	delete(f.members, memberID)
+	return nil
+}
+
+func (f *fakeProvider) Roles() []string {
+	return []string{"MEMBER", "MANAGER", "OWNER"}
+}
+
+func Test_validRole(t *testing.T) {
+	tests := []struct {
+		role    string
+		want    string
+		wantErr string
+	}{
+		{role: "", want: "MEMBER"},
+		{role: "manager", want: "MANAGER"},
+		{role: "admin", wantErr: "invalid role admin, use MEMBER|MANAGER|OWNER"},
+	}
+	for _, tt := range tests {
+		got, err := validRole(newFakeProvider(), tt.role)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validRole(%q) error = %v, want %v", tt.role, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("validRole(%q) = %v, %v, want %v", tt.role, got, err, tt.want)
+		}
+	}
+}
+
+func TestWriteMembers(t *testing.T) {
+	members := []Member{{ID: "alice@example.com", Name: "m/1", Roles: []string{"MEMBER", "OWNER"}}}
+	tests := []struct {
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{format: "", want: "member,roles,membership\nalice@example.com,MEMBER;OWNER,m/1\n"},
+		{format: JSONFormat, want: "[\n {\n  \"id\": \"alice@example.com\",\n  \"name\": \"m/1\",\n  \"roles\": [\n   \"MEMBER\",\n   \"OWNER\"\n  ]\n }\n]\n"},
+		{format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		sb := &strings.Builder{}
+		err := WriteMembers(sb, members, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("WriteMembers(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+		if !tt.wantErr && sb.String() != tt.want {
+			t.Errorf("WriteMembers(%q) = %q, want %q", tt.format, sb.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandGetConfig represents the configuration for the get command
+type commandGetConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		TeamFlag *string
+	}
+}
+
+// Execute executes the get command
+func (c commandGetConfig) Execute() string {
+	if *c.Opts.TeamFlag != "" {
+		res, err := GetTeam(*c.Opts.TeamFlag)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		return fmt.Sprintf("[unfold] retrieved team %v of %s with id %d and %d member(s)", helpers.GreenValue(res.Slug), res.Org, res.ID, res.MembersCount)
+	}
+	return "[unfold] something went wrong"
+}
+
+// GetFlagSet returns the flag set for the get command
+func (c commandGetConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandGetConfig fetches the command get config
+func fetchCommandGetConfig() commandGetConfig {
+	flagSet := flag.NewFlagSet(commands.Get, flag.ContinueOnError)
+	bindAuthFlags(flagSet)
+	return commandGetConfig{
+		Opts: struct {
+			TeamFlag *string
+		}{
+			TeamFlag: flagSet.String("g", "", "used to fetch team details for given team slug or org/slug"),
+		},
+		FlagSet: flagSet,
+	}
+}
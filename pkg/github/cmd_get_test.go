@@ -0,0 +1,157 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// stubGitHubServer serves the team partners of the organization acme, with alice as active maintainer,
+// bob as pending member and partner@example.com invited on the second page of the team invitations.
+// The mutating requests are recorded as "METHOD path body" in the returned slice.
+func stubGitHubServer(t *testing.T) (*httptest.Server, *[]string) {
+	var mu sync.Mutex
+	requests := []string{}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer test-token" || r.Header.Get("X-GitHub-Api-Version") != apiVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "Bad credentials"}`)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/api/v3")
+		if r.Method != http.MethodGet {
+			b, _ := io.ReadAll(r.Body)
+			requests = append(requests, strings.TrimSpace(fmt.Sprintf("%s %s %s", r.Method, path, b)))
+		}
+
+		team := "/orgs/acme/teams/partners"
+		switch {
+		case r.Method == http.MethodGet && path == team:
+			fmt.Fprint(w, `{"id": 42, "slug": "partners", "name": "Partners", "privacy": "closed", "members_count": 3}`)
+		case r.Method == http.MethodGet && path == team+"/memberships/alice":
+			fmt.Fprint(w, `{"role": "maintainer", "state": "active"}`)
+		case r.Method == http.MethodGet && path == team+"/memberships/bob":
+			fmt.Fprint(w, `{"role": "member", "state": "pending"}`)
+		case r.Method == http.MethodGet && path == team+"/members":
+			switch r.URL.Query().Get("role") {
+			case MaintainerRole:
+				fmt.Fprint(w, `[{"login": "alice"}]`)
+			case MemberRole:
+				if r.URL.Query().Get("page") == "" {
+					w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3%s/members?role=member&per_page=100&page=2>; rel="next"`, server.URL, team))
+					fmt.Fprint(w, `[{"login": "grace"}]`)
+					return
+				}
+				fmt.Fprint(w, `[{"login": "heidi"}]`)
+			default:
+				w.WriteHeader(http.StatusUnprocessableEntity)
+			}
+		case r.Method == http.MethodGet && path == team+"/invitations":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3%s/invitations?per_page=100&page=2>; rel="next"`, server.URL, team))
+				fmt.Fprint(w, `[{"id": 6, "email": "other@example.com", "role": "direct_member"}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id": 7, "email": "partner@example.com", "role": "direct_member"}]`)
+		case r.Method == http.MethodPut && path == team+"/memberships/dave":
+			fmt.Fprint(w, `{"role": "maintainer", "state": "active"}`)
+		case r.Method == http.MethodPut && path == team+"/memberships/erin":
+			fmt.Fprint(w, `{"role": "member", "state": "pending"}`)
+		case r.Method == http.MethodPost && path == "/orgs/acme/invitations":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 8, "email": "new@example.com", "role": "direct_member"}`)
+		case r.Method == http.MethodDelete && (path == team+"/memberships/alice" || path == "/orgs/acme/invitations/7"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func useStubGitHubServer(t *testing.T, server *httptest.Server) {
+	baseURL, httpClient := instance.BaseURL, instance.httpClient
+	t.Cleanup(func() {
+		instance.BaseURL, instance.httpClient = baseURL, httpClient
+	})
+	instance.BaseURL, instance.httpClient = server.URL+"/api/v3", server.Client()
+}
+
+func Test_commandGetConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	server, _ := stubGitHubServer(t)
+	useStubGitHubServer(t, server)
+
+	tests := []struct {
+		name  string
+		args  []string
+		token string
+		org   string
+		want  string
+	}{
+		{
+			name: "get team of the default organization",
+			args: []string{"-g", "partners"},
+			want: "retrieved team " + helpers.GreenValue("partners") + " of acme with id 42 and 3 member(s)",
+		},
+		{
+			name: "get team of an organization",
+			args: []string{"-g", "acme/partners"},
+			org:  "other",
+			want: "retrieved team " + helpers.GreenValue("partners") + " of acme",
+		},
+		{
+			name: "get unknown team",
+			args: []string{"-g", "vendors"},
+			want: "github team not found: acme/vendors",
+		},
+		{
+			name: "get team without organization",
+			args: []string{"-g", "partners"},
+			org:  "-",
+			want: "organization of the team partners cannot be empty, set GITHUB_ORG or use <org>/partners",
+		},
+		{
+			name:  "get team with invalid token",
+			args:  []string{"-g", "partners"},
+			token: "invalid",
+			want:  "github returned 401 for GET /api/v3/orgs/acme/teams/partners with response",
+		},
+		{
+			name: "get team flag not provided",
+			args: []string{},
+			want: "something went wrong",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandGetConfig
+			c.GetFlagSet().Parse(tt.args)
+			token, org := instance.token, instance.Org
+			defer func() { instance.token, instance.Org = token, org }()
+			if tt.token != "" {
+				instance.token = tt.token
+			}
+			if tt.org == "-" {
+				instance.Org = ""
+			} else if tt.org != "" {
+				instance.Org = tt.org
+			}
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandGetConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultBaseURL is the REST API URL of github.com
+const defaultBaseURL = "https://api.github.com"
+
+// GitHubConfig holds the endpoint, credentials and organization of the GitHub REST API
+type GitHubConfig struct {
+	// BaseURL is the REST API URL, e.g. https://github.example.com/api/v3 for GitHub Enterprise Server
+	BaseURL string `json:"baseURL" yaml:"baseURL"`
+	// Token is the personal access or app installation token sent with every request
+	Token string `json:"token" yaml:"token"`
+	// Org is the default organization of the teams
+	Org string `json:"org" yaml:"org"`
+}
+
+// Config contains the GitHub configuration as read from the environment
+var Config = GitHubConfig{
+	BaseURL: envBaseURL(),
+	Token:   os.Getenv("GITHUB_TOKEN"),
+	Org:     os.Getenv("GITHUB_ORG"),
+}
+
+// envBaseURL returns the base URL set with GITHUB_API_URL, github.com otherwise
+func envBaseURL() string {
+	if u := os.Getenv("GITHUB_API_URL"); u != "" {
+		return u
+	}
+	return defaultBaseURL
+}
+
+// bindAuthFlags registers the endpoint and organization flags on the given flag set, the token is only
+// read from GITHUB_TOKEN so that it does not show up in the shell history or the process list
+func bindAuthFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&Config.BaseURL, "base-url", envBaseURL(), "GitHub REST API URL, e.g. https://github.example.com/api/v3")
+	flagSet.StringVar(&Config.Org, "org", os.Getenv("GITHUB_ORG"), "organization of the team")
+}
+
+// Service holds the http client used to call the GitHub REST API
+type Service struct {
+	BaseURL    string
+	Org        string
+	token      string
+	httpClient *http.Client
+}
+
+// instance holds the Service as constructed from the GitHubConfig
+var instance *Service
+
+// NewService creates a new Service from the GitHubConfig
+func (c GitHubConfig) NewService() (*Service, error) {
+	if c.Token == "" {
+		return nil, errors.New("GITHUB_TOKEN is not set")
+	}
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	return &Service{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Org:        c.Org,
+		token:      c.Token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// StartService starts the GitHub service
+func StartService() error {
+	var err error
+	instance, err = Config.NewService()
+	return err
+}
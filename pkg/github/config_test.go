@@ -0,0 +1,61 @@
+package github
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestGitHubConfig_NewService(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      GitHubConfig
+		wantBaseURL string
+		wantErr     string
+	}{
+		{
+			name:        "github.com by default",
+			config:      GitHubConfig{Token: "token"},
+			wantBaseURL: defaultBaseURL,
+		},
+		{
+			name:        "enterprise server with trailing slash",
+			config:      GitHubConfig{BaseURL: "https://github.example.com/api/v3/", Token: "token", Org: "acme"},
+			wantBaseURL: "https://github.example.com/api/v3",
+		},
+		{
+			name:    "token not set",
+			config:  GitHubConfig{BaseURL: defaultBaseURL},
+			wantErr: "GITHUB_TOKEN is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.NewService()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GitHubConfig.NewService() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GitHubConfig.NewService() error = %v", err)
+			}
+			if got.BaseURL != tt.wantBaseURL || got.Org != tt.config.Org || got.token != tt.config.Token {
+				t.Errorf("GitHubConfig.NewService() = %+v, want base url %v", got, tt.wantBaseURL)
+			}
+		})
+	}
+}
+
+func Test_bindAuthFlags(t *testing.T) {
+	flagSet := flag.NewFlagSet("github", flag.ContinueOnError)
+	bindAuthFlags(flagSet)
+	if flagSet.Lookup("token") != nil {
+		t.Error("bindAuthFlags() registered -token, want the token read from GITHUB_TOKEN only")
+	}
+	for _, name := range []string{"base-url", "org"} {
+		if flagSet.Lookup(name) == nil {
+			t.Errorf("bindAuthFlags() did not register -%s", name)
+		}
+	}
+}
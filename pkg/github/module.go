@@ -0,0 +1,24 @@
+package github
+
+import (
+	"github.com/aryannr97/unfold/pkg/directory"
+)
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandGetConfig       commandGetConfig
+	CommandConfigureConfig directory.CommandConfigureConfig
+	CommandSearchConfig    directory.CommandSearchConfig
+	CommandExportConfig    directory.CommandExportConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	provider := NewGroupProvider()
+	return &CommandModule{
+		CommandGetConfig:       fetchCommandGetConfig(),
+		CommandConfigureConfig: directory.NewCommandConfigureConfig(provider, bindAuthFlags),
+		CommandSearchConfig:    directory.NewCommandSearchConfig(provider, bindAuthFlags),
+		CommandExportConfig:    directory.NewCommandExportConfig(provider, bindAuthFlags),
+	}
+}
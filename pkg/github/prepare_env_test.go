@@ -0,0 +1,13 @@
+package github
+
+import (
+	"log"
+)
+
+func prepareTestEnvironment() {
+	Config = GitHubConfig{BaseURL: "http://localhost/api/v3", Token: "test-token", Org: "acme"}
+	err := StartService()
+	if err != nil {
+		log.Fatalf("failed to start service: %v", err)
+	}
+}
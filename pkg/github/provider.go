@@ -0,0 +1,93 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/directory"
+)
+
+// groupProvider implements directory.GroupProvider on top of the teams of an organization.
+// The roles of a member are its team role, followed by the pending state while its invitation is not accepted.
+type groupProvider struct{}
+
+// NewGroupProvider returns the GitHub team provider, usable once the service is started
+func NewGroupProvider() directory.GroupProvider {
+	return groupProvider{}
+}
+
+// LookupGroup returns the team identified by its slug or org/slug
+func (groupProvider) LookupGroup(groupID string) (*directory.Group, error) {
+	t, err := GetTeam(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &directory.Group{ID: groupID, Name: t.Org + "/" + t.Slug, DisplayName: t.Name}, nil
+}
+
+// ListMembers returns the active members of the team and its pending invitations
+func (groupProvider) ListMembers(groupID string) ([]directory.Member, error) {
+	t, err := GetTeam(groupID)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := listTeamMembers(t)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]directory.Member, 0, len(memberships))
+	for _, m := range memberships {
+		members = append(members, teamMember(t, m))
+	}
+	return members, nil
+}
+
+// CheckMember returns the active or pending membership of the login or email in the team
+func (groupProvider) CheckMember(groupID, memberID string) (*directory.Member, error) {
+	t, err := GetTeam(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if memberID == "" {
+		return nil, errors.New("user cannot be empty")
+	}
+	m, err := teamMembership(t, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, directory.ErrMemberNotFound
+	}
+	member := teamMember(t, *m)
+	return &member, nil
+}
+
+// AddMember adds the login to the team with the role, or invites the email to the organization and the team
+func (groupProvider) AddMember(groupID, memberID, role string) error {
+	_, err := AddTeamMember(groupID, memberID, role)
+	return err
+}
+
+// RemoveMember removes the login from the team, or cancels the pending invitation of the email
+func (groupProvider) RemoveMember(groupID, memberID string) error {
+	_, err := RemoveTeamMember(groupID, memberID)
+	return err
+}
+
+// Roles returns the team roles
+func (groupProvider) Roles() []string {
+	return []string{MemberRole, MaintainerRole}
+}
+
+// teamMember returns the directory member of the membership, named after its REST resource
+func teamMember(t *Team, m TeamMembership) directory.Member {
+	if m.InvitationID != 0 {
+		return directory.Member{ID: m.User, Name: fmt.Sprintf("orgs/%s/invitations/%d", t.Org, m.InvitationID), Roles: []string{m.Role, PendingState}}
+	}
+	member := directory.Member{ID: m.User, Name: fmt.Sprintf("orgs/%s/teams/%s/memberships/%s", t.Org, t.Slug, m.User), Roles: []string{m.Role}}
+	if m.State == PendingState {
+		member.Roles = append(member.Roles, PendingState)
+	}
+	return member
+}
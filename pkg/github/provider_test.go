@@ -0,0 +1,171 @@
+package github
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// operation is a command of the module as registered in the registry
+type operation interface {
+	Execute() string
+	GetFlagSet() *flag.FlagSet
+}
+
+func Test_groupProvider_commands(t *testing.T) {
+	prepareTestEnvironment()
+
+	search := func(m *CommandModule) operation { return m.CommandSearchConfig }
+	configure := func(m *CommandModule) operation { return m.CommandConfigureConfig }
+	export := func(m *CommandModule) operation { return m.CommandExportConfig }
+
+	tests := []struct {
+		name         string
+		command      func(*CommandModule) operation
+		args         []string
+		want         string
+		wantRequests []string
+	}{
+		{
+			name:    "search active maintainer",
+			command: search,
+			args:    []string{"-g", "partners", "-id", "alice"},
+			want:    "emailID is found to be " + helpers.GreenValue(MaintainerRole) + " of the group with membership name " + helpers.GreenValue("orgs/acme/teams/partners/memberships/alice"),
+		},
+		{
+			name:    "search pending member",
+			command: search,
+			args:    []string{"-g", "acme/partners", "-id", "bob"},
+			want:    "emailID is found to be " + helpers.GreenValue(MemberRole+","+PendingState) + " of the group",
+		},
+		{
+			name:    "search invited email on a later page",
+			command: search,
+			args:    []string{"-g", "partners", "-id", "Partner@example.com"},
+			want:    "emailID is found to be " + helpers.GreenValue(MemberRole+","+PendingState) + " of the group with membership name " + helpers.GreenValue("orgs/acme/invitations/7"),
+		},
+		{
+			name:    "search non member",
+			command: search,
+			args:    []string{"-g", "partners", "-id", "carol"},
+			want:    "member not found",
+		},
+		{
+			name:    "search email not invited",
+			command: search,
+			args:    []string{"-g", "partners", "-id", "carol@example.com"},
+			want:    "member not found",
+		},
+		{
+			name:    "search unknown team",
+			command: search,
+			args:    []string{"-g", "vendors", "-id", "alice"},
+			want:    "github team not found: acme/vendors",
+		},
+		{
+			name:    "search without id",
+			command: search,
+			args:    []string{"-g", "partners"},
+			want:    "something went wrong",
+		},
+		{
+			name:         "add maintainer",
+			command:      configure,
+			args:         []string{"-g", "partners", "-id", "dave", "-role", "maintainer"},
+			want:         "successfully added the member to the given group",
+			wantRequests: []string{`PUT /orgs/acme/teams/partners/memberships/dave {"role":"maintainer"}`},
+		},
+		{
+			name:         "add member outside of the organization",
+			command:      configure,
+			args:         []string{"-g", "partners", "-id", "erin"},
+			want:         "successfully added the member to the given group",
+			wantRequests: []string{`PUT /orgs/acme/teams/partners/memberships/erin {"role":"member"}`},
+		},
+		{
+			name:         "invite email",
+			command:      configure,
+			args:         []string{"-g", "partners", "-id", "new@example.com"},
+			want:         "successfully added the member to the given group",
+			wantRequests: []string{`POST /orgs/acme/invitations {"email":"new@example.com","role":"direct_member","team_ids":[42]}`},
+		},
+		{
+			name:    "invite email as maintainer",
+			command: configure,
+			args:    []string{"-g", "partners", "-id", "new@example.com", "-role", "maintainer"},
+			want:    "an email can only be invited as member",
+		},
+		{
+			name:    "invalid role",
+			command: configure,
+			args:    []string{"-g", "partners", "-id", "dave", "-role", "owner"},
+			want:    "invalid role owner, use member|maintainer",
+		},
+		{
+			name:         "add unknown user",
+			command:      configure,
+			args:         []string{"-g", "partners", "-id", "ghost"},
+			want:         "failed to add member to the given group " + helpers.RedValue("user ghost not found"),
+			wantRequests: []string{`PUT /orgs/acme/teams/partners/memberships/ghost {"role":"member"}`},
+		},
+		{
+			name:         "remove member",
+			command:      configure,
+			args:         []string{"-r", "-g", "partners", "-id", "alice"},
+			want:         "successfully removed the member from the group",
+			wantRequests: []string{"DELETE /orgs/acme/teams/partners/memberships/alice"},
+		},
+		{
+			name:         "cancel invitation",
+			command:      configure,
+			args:         []string{"-r", "-g", "partners", "-id", "partner@example.com"},
+			want:         "successfully removed the member from the group",
+			wantRequests: []string{"DELETE /orgs/acme/invitations/7"},
+		},
+		{
+			name:    "remove non member",
+			command: configure,
+			args:    []string{"-r", "-g", "partners", "-id", "carol"},
+			want:    "unable to remove the member " + helpers.RedValue("carol is not a member of the team partners"),
+		},
+		{
+			name:    "export members and pending invitations",
+			command: export,
+			args:    []string{"-g", "partners"},
+			want: "exported " + helpers.GreenValue("5") + " member(s) of partners\n" +
+				"member,roles,membership\n" +
+				"alice,maintainer,orgs/acme/teams/partners/memberships/alice\n" +
+				"grace,member,orgs/acme/teams/partners/memberships/grace\n" +
+				"heidi,member,orgs/acme/teams/partners/memberships/heidi\n" +
+				"other@example.com,member;pending,orgs/acme/invitations/6\n" +
+				"partner@example.com,member;pending,orgs/acme/invitations/7",
+		},
+		{
+			name:    "export unknown team",
+			command: export,
+			args:    []string{"-g", "vendors"},
+			want:    "github team not found: acme/vendors",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := stubGitHubServer(t)
+			useStubGitHubServer(t, server)
+
+			c := tt.command(NewCommandModule())
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+			if tt.wantRequests == nil {
+				tt.wantRequests = []string{}
+			}
+			if !reflect.DeepEqual(*requests, tt.wantRequests) {
+				t.Errorf("requests = %v, want %v", *requests, tt.wantRequests)
+			}
+		})
+	}
+}
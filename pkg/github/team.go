@@ -0,0 +1,324 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// MemberRole is the role of the plain members of a team
+	MemberRole = "member"
+	// MaintainerRole is the role of the members managing a team
+	MaintainerRole = "maintainer"
+
+	// ActiveState is the state of a membership accepted by the user
+	ActiveState = "active"
+	// PendingState is the state of a membership waiting for the user to accept the organization invitation
+	PendingState = "pending"
+
+	// apiVersion is the GitHub REST API version the requests are made against
+	apiVersion = "2022-11-28"
+)
+
+var (
+	// ErrTeamNotFound is returned when the organization has no team with the slug
+	ErrTeamNotFound = errors.New("github team not found")
+	// errGitHubNotFound is returned by githubRequest when the resource does not exist
+	errGitHubNotFound = errors.New("github resource not found")
+
+	// nextLinkRegex matches the next page URL of a Link header
+	nextLinkRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// Team represents a GitHub team
+type Team struct {
+	ID           int64  `json:"id"`
+	Slug         string `json:"slug"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	Privacy      string `json:"privacy,omitempty"`
+	MembersCount int    `json:"members_count"`
+	// Org is the organization the team was looked up in
+	Org string `json:"-"`
+}
+
+// TeamMembership represents the membership of a user in a team
+type TeamMembership struct {
+	// User is the login or email the membership was looked up with
+	User  string `json:"-"`
+	Role  string `json:"role"`
+	State string `json:"state"`
+	// InvitationID is the id of the pending organization invitation of an email
+	InvitationID int64 `json:"-"`
+}
+
+// invitation represents a pending organization invitation of a team
+type invitation struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// githubRequest calls the GitHub REST API and decodes the response into out when given
+func githubRequest(method, path string, body, out any) error {
+	_, err := githubPage(method, path, body, out)
+	return err
+}
+
+// githubPage calls the GitHub REST API, decodes the response into out when given
+// and returns the URL of the next page of a list, empty on the last page.
+func githubPage(method, path string, body, out any) (string, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	target := path
+	if !strings.HasPrefix(path, "http") {
+		target = instance.BaseURL + path
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+instance.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := instance.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errGitHubNotFound
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("github returned %v for %s %s with response %v", resp.StatusCode, method, req.URL.Path, helpers.GetErrorResponseBody(b))
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return "", fmt.Errorf("json decode %s", err.Error())
+		}
+	}
+	if m := nextLinkRegex.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+		return m[1], nil
+	}
+	return "", nil
+}
+
+// teamRef returns the organization and slug of the team given as slug or org/slug
+func teamRef(team string) (string, string, error) {
+	if team == "" {
+		return "", "", errors.New("team cannot be empty")
+	}
+	if org, slug, ok := strings.Cut(team, "/"); ok {
+		return org, slug, nil
+	}
+	if instance.Org == "" {
+		return "", "", fmt.Errorf("organization of the team %s cannot be empty, set GITHUB_ORG or use <org>/%s", team, team)
+	}
+	return instance.Org, team, nil
+}
+
+// GetTeam returns the team identified by its slug or org/slug
+func GetTeam(team string) (*Team, error) {
+	org, slug, err := teamRef(team)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Team
+	if err := githubRequest(http.MethodGet, fmt.Sprintf("/orgs/%s/teams/%s", url.PathEscape(org), url.PathEscape(slug)), nil, &res); err != nil {
+		if errors.Is(err, errGitHubNotFound) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrTeamNotFound, org, slug)
+		}
+		return nil, err
+	}
+	res.Org = org
+	return &res, nil
+}
+
+// teamPath returns the REST path of the team
+func teamPath(t *Team) string {
+	return fmt.Sprintf("/orgs/%s/teams/%s", url.PathEscape(t.Org), url.PathEscape(t.Slug))
+}
+
+// GetTeamMembership returns the active or pending membership of the login or email in the team, nil when not a member
+func GetTeamMembership(team, user string) (*TeamMembership, error) {
+	if user == "" {
+		return nil, errors.New("user cannot be empty")
+	}
+	t, err := GetTeam(team)
+	if err != nil {
+		return nil, err
+	}
+	return teamMembership(t, user)
+}
+
+// teamMembership returns the membership of the login or email in the team, nil when not a member.
+// Emails can only have a pending invitation, the membership of a login includes its pending invitation.
+func teamMembership(t *Team, user string) (*TeamMembership, error) {
+	if strings.Contains(user, "@") {
+		invitations, err := listTeamInvitations(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range invitations {
+			if strings.EqualFold(i.Email, user) {
+				return &TeamMembership{User: user, Role: MemberRole, State: PendingState, InvitationID: i.ID}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	var res TeamMembership
+	if err := githubRequest(http.MethodGet, fmt.Sprintf("%s/memberships/%s", teamPath(t), url.PathEscape(user)), nil, &res); err != nil {
+		if errors.Is(err, errGitHubNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	res.User = user
+	return &res, nil
+}
+
+// listTeamInvitations returns the pending organization invitations of the team over all pages
+func listTeamInvitations(t *Team) ([]invitation, error) {
+	var invitations []invitation
+	next := teamPath(t) + "/invitations?per_page=100"
+	for next != "" {
+		var page []invitation
+		var err error
+		if next, err = githubPage(http.MethodGet, next, nil, &page); err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, page...)
+	}
+	return invitations, nil
+}
+
+// listTeamMembers returns the active maintainers and members of the team over all pages, followed by its pending invitations
+func listTeamMembers(t *Team) ([]TeamMembership, error) {
+	var memberships []TeamMembership
+	for _, role := range []string{MaintainerRole, MemberRole} {
+		next := fmt.Sprintf("%s/members?role=%s&per_page=100", teamPath(t), role)
+		for next != "" {
+			var page []struct {
+				Login string `json:"login"`
+			}
+			var err error
+			if next, err = githubPage(http.MethodGet, next, nil, &page); err != nil {
+				return nil, err
+			}
+			for _, u := range page {
+				memberships = append(memberships, TeamMembership{User: u.Login, Role: role, State: ActiveState})
+			}
+		}
+	}
+
+	invitations, err := listTeamInvitations(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range invitations {
+		user := i.Login
+		if user == "" {
+			user = i.Email
+		}
+		memberships = append(memberships, TeamMembership{User: user, Role: MemberRole, State: PendingState, InvitationID: i.ID})
+	}
+	return memberships, nil
+}
+
+// AddTeamMember adds the login to the team with the role, or invites the email to the organization and the team.
+// The returned membership is pending until the user accepts the organization invitation.
+func AddTeamMember(team, user, role string) (*TeamMembership, error) {
+	if user == "" {
+		return nil, errors.New("user cannot be empty")
+	}
+	if role == "" {
+		role = MemberRole
+	}
+	if role != MemberRole && role != MaintainerRole {
+		return nil, fmt.Errorf("invalid role %s, use %s|%s", role, MaintainerRole, MemberRole)
+	}
+	t, err := GetTeam(team)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(user, "@") {
+		if role == MaintainerRole {
+			return nil, fmt.Errorf("an email can only be invited as %s, add the login of %s as %s once the invitation is accepted", MemberRole, user, MaintainerRole)
+		}
+		body := map[string]any{"email": user, "role": "direct_member", "team_ids": []int64{t.ID}}
+		var res invitation
+		err := githubRequest(http.MethodPost, fmt.Sprintf("/orgs/%s/invitations", url.PathEscape(t.Org)), body, &res)
+		audit.Record(audit.Entry{Action: "github invitation add", Targets: []string{t.Org + "/" + t.Slug, user}}, err)
+		if err != nil {
+			return nil, err
+		}
+		return &TeamMembership{User: user, Role: MemberRole, State: PendingState, InvitationID: res.ID}, nil
+	}
+
+	var res TeamMembership
+	err = githubRequest(http.MethodPut, fmt.Sprintf("%s/memberships/%s", teamPath(t), url.PathEscape(user)), map[string]string{"role": role}, &res)
+	if errors.Is(err, errGitHubNotFound) {
+		err = fmt.Errorf("user %s not found", user)
+	}
+	audit.Record(audit.Entry{Action: "github membership add", Targets: []string{t.Org + "/" + t.Slug, user}}, err)
+	if err != nil {
+		return nil, err
+	}
+	res.User = user
+	return &res, nil
+}
+
+// RemoveTeamMember removes the login from the team, or cancels the pending invitation of the email
+func RemoveTeamMember(team, user string) (*TeamMembership, error) {
+	if user == "" {
+		return nil, errors.New("user cannot be empty")
+	}
+	t, err := GetTeam(team)
+	if err != nil {
+		return nil, err
+	}
+	m, err := teamMembership(t, user)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s is not a member of the team %s", user, t.Slug)
+	}
+
+	if m.InvitationID != 0 {
+		err = githubRequest(http.MethodDelete, fmt.Sprintf("/orgs/%s/invitations/%d", url.PathEscape(t.Org), m.InvitationID), nil, nil)
+		audit.Record(audit.Entry{Action: "github invitation remove", Targets: []string{t.Org + "/" + t.Slug, user}}, err)
+		return m, err
+	}
+	err = githubRequest(http.MethodDelete, fmt.Sprintf("%s/memberships/%s", teamPath(t), url.PathEscape(user)), nil, nil)
+	audit.Record(audit.Entry{Action: "github membership remove", Targets: []string{t.Org + "/" + t.Slug, user}}, err)
+	return m, err
+}
@@ -0,0 +1,103 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+func Test_commandExportConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	out := filepath.Join(t.TempDir(), "members.csv")
+	memberships := func() map[string]*http.Response {
+		return map[string]*http.Response{
+			"/v1/groups:lookup": {
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+			},
+			"/v1/groups/test-group/memberships": {
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(bytes.NewBufferString(`{"memberships": [
+					{"name": "groups/test-group/memberships/abc", "preferredMemberKey": {"id": "alice@example.com"}, "roles": [{"name": "MEMBER"}, {"name": "OWNER"}]},
+					{"name": "groups/test-group/memberships/xyz", "preferredMemberKey": {"id": "bob@example.com"}, "roles": [{"name": "MEMBER"}]}]}`)),
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		args          []string
+		transport     map[string]*http.Response
+		httpCallError error
+		want          string
+	}{
+		{
+			name:      "export members as csv",
+			args:      []string{"-g", "test-group"},
+			transport: memberships(),
+			want: "exported " + helpers.GreenValue("2") + " member(s) of test-group\nmember,roles,membership\n" +
+				"alice@example.com,MEMBER;OWNER,groups/test-group/memberships/abc\nbob@example.com,MEMBER,groups/test-group/memberships/xyz",
+		},
+		{
+			name:      "export members as json",
+			args:      []string{"-g", "test-group", "-format", "json"},
+			transport: memberships(),
+			want:      "[\n {\n  \"id\": \"alice@example.com\",\n  \"name\": \"groups/test-group/memberships/abc\",\n  \"roles\": [\n   \"MEMBER\",\n   \"OWNER\"\n  ]\n }",
+		},
+		{
+			name:      "export members to file",
+			args:      []string{"-g", "test-group", "-out", out},
+			transport: memberships(),
+			want:      "members written to " + out,
+		},
+		{
+			name:      "export with invalid format",
+			args:      []string{"-g", "test-group", "-format", "xml"},
+			transport: memberships(),
+			want:      "invalid format xml, must be one of csv|json",
+		},
+		{
+			name:          "export http call error",
+			args:          []string{"-g", "test-group"},
+			httpCallError: errors.New("http call error"),
+			want:          "http call error",
+		},
+		{
+			name: "export without group",
+			args: []string{},
+			want: "group cannot be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandExportConfig
+			c.GetFlagSet().Parse(tt.args)
+			instance.CloudIdentityService, _ = cloudidentity.NewService(context.Background(),
+				option.WithHTTPClient(&http.Client{
+					Transport: &MockHTTPRoundTripper{
+						Transport: tt.transport,
+						Error:     tt.httpCallError,
+					},
+				}))
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandExportConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			instance.Groups = make(map[string]*cloudidentity.LookupGroupNameResponse)
+		})
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil || !strings.HasPrefix(string(b), "member,roles,membership\nalice@example.com,") {
+		t.Errorf("exported file = %q, %v", string(b), err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 
+	"github.com/aryannr97/unfold/pkg/cache"
 	"github.com/aryannr97/unfold/pkg/commands"
 	"github.com/aryannr97/unfold/pkg/helpers"
 )
@@ -36,6 +37,8 @@ func (c commandGetConfig) GetFlagSet() *flag.FlagSet {
 // fetchCommandGetConfig fetches the command get config
 func fetchCommandGetConfig() commandGetConfig {
 	flagSet := flag.NewFlagSet(commands.Get, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindAuthFlags(flagSet)
 	return commandGetConfig{
 		Opts: struct {
 			GroupFlag *string
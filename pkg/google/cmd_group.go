@@ -0,0 +1,95 @@
+package google
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandGroupConfig represents the configuration for the group command
+type commandGroupConfig struct {
+	FlagSet   *flag.FlagSet
+	GroupOpts struct {
+		Group       *string
+		DisplayName *string
+		Description *string
+		Type        *string
+	}
+}
+
+// Execute executes the group command
+func (c commandGroupConfig) Execute() string {
+	action, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.GroupOpts.Group == "" {
+		return "[unfold] group cannot be empty"
+	}
+
+	opts := GroupOpts{
+		DisplayName: *c.GroupOpts.DisplayName,
+		Description: *c.GroupOpts.Description,
+		Type:        *c.GroupOpts.Type,
+	}
+
+	switch action {
+	case commands.Create:
+		g, err := CreateGroup(*c.GroupOpts.Group, opts)
+		if err != nil {
+			return fmt.Sprintf("[unfold] failed to create the group %s", helpers.RedValue(err.Error()))
+		}
+		return fmt.Sprintf("[unfold] successfully created the group %s", helpers.GreenValue(g.GroupKey.Id))
+	case commands.Describe:
+		g, err := DescribeGroup(*c.GroupOpts.Group)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		b, _ := json.MarshalIndent(g, "", " ")
+		return fmt.Sprintf("[unfold] group details \n%s", string(b))
+	case commands.Update:
+		g, err := UpdateGroup(*c.GroupOpts.Group, opts)
+		if err != nil {
+			return fmt.Sprintf("[unfold] failed to update the group %s", helpers.RedValue(err.Error()))
+		}
+		return fmt.Sprintf("[unfold] successfully updated the group %s", helpers.GreenValue(g.Name))
+	case commands.Delete:
+		err := DeleteGroup(*c.GroupOpts.Group)
+		if err != nil {
+			return fmt.Sprintf("[unfold] failed to delete the group %s", helpers.RedValue(err.Error()))
+		}
+		return "[unfold] successfully deleted the group"
+	}
+	return fmt.Sprintf("[unfold] provide a valid action %s|%s|%s|%s", commands.Create, commands.Describe, commands.Update, commands.Delete)
+}
+
+// GetFlagSet returns the flag set for the group command
+func (c commandGroupConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandGroupConfig fetches the command group config
+func fetchCommandGroupConfig() commandGroupConfig {
+	flagSet := flag.NewFlagSet(commands.Group, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindAuthFlags(flagSet)
+	return commandGroupConfig{
+		GroupOpts: struct {
+			Group       *string
+			DisplayName *string
+			Description *string
+			Type        *string
+		}{
+			Group:       flagSet.String("g", "", "provide a valid google group"),
+			DisplayName: flagSet.String("name", "", "display name of the group"),
+			Description: flagSet.String("desc", "", "description of the group"),
+			Type:        flagSet.String("type", "", "type of the group discussion|security, defaults to discussion on create"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,240 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+func Test_commandGroupConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	tests := []struct {
+		name          string
+		args          []string
+		customerID    string
+		transport     map[string]*http.Response
+		httpCallError error
+		errorOnIndex  int
+		want          string
+	}{
+		{
+			name:       "create group success",
+			args:       []string{"create", "-g", "test-group", "-name", "Test Group", "-type", "security"},
+			customerID: "C0123",
+			transport: map[string]*http.Response{
+				"/v1/groups": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"done": true, "response": {"name": "groups/test-group", "groupKey": {"id": "test-group@example.com"}}}`)),
+				},
+			},
+			want: "successfully created the group " + helpers.GreenValue("test-group@example.com"),
+		},
+		{
+			name:       "create group pending operation",
+			args:       []string{"create", "-g", "test-group"},
+			customerID: "C0123",
+			transport: map[string]*http.Response{
+				"/v1/groups": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"done": false}`)),
+				},
+			},
+			want: "successfully created the group " + helpers.GreenValue("test-group@example.com"),
+		},
+		{
+			name:       "create group operation error",
+			args:       []string{"create", "-g", "test-group"},
+			customerID: "C0123",
+			transport: map[string]*http.Response{
+				"/v1/groups": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"done": true, "error": {"code": 6, "message": "already exists"}}`)),
+				},
+			},
+			want: "already exists",
+		},
+		{
+			name: "create group without customer id",
+			args: []string{"create", "-g", "test-group"},
+			want: "customer id is required",
+		},
+		{
+			name:       "create group invalid type",
+			args:       []string{"create", "-g", "test-group", "-type", "mailing"},
+			customerID: "C0123",
+			want:       "invalid group type mailing",
+		},
+		{
+			name:          "create group http call error",
+			args:          []string{"create", "-g", "test-group"},
+			customerID:    "C0123",
+			httpCallError: errors.New("http call error"),
+			want:          "failed to create the group",
+		},
+		{
+			name: "describe group success",
+			args: []string{"describe", "-g", "test-group"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+				"/v1/groups/test-group": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group", "displayName": "Test Group", "labels": {"cloudidentity.googleapis.com/groups.discussion_forum": ""}}`)),
+				},
+			},
+			want: `"displayName": "Test Group"`,
+		},
+		{
+			name:          "describe group lookup error",
+			args:          []string{"describe", "-g", "test-group"},
+			httpCallError: errors.New("http call error"),
+			want:          "http call error",
+		},
+		{
+			name: "update group success",
+			args: []string{"update", "-g", "test-group", "-desc", "new description"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+				"/v1/groups/test-group": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"done": true, "response": {"name": "groups/test-group", "description": "new description"}}`)),
+				},
+			},
+			want: "successfully updated the group " + helpers.GreenValue("groups/test-group"),
+		},
+		{
+			name: "update group nothing to update",
+			args: []string{"update", "-g", "test-group"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+			},
+			want: "nothing to update",
+		},
+		{
+			name: "update group http call error",
+			args: []string{"update", "-g", "test-group", "-type", "security"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+			},
+			httpCallError: errors.New("http call error"),
+			errorOnIndex:  1,
+			want:          "failed to update the group",
+		},
+		{
+			name: "update group type merges the labels",
+			args: []string{"update", "-g", "test-group", "-type", "security"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+				"https://cloudidentity.googleapis.com/v1/groups/test-group?alt=json&prettyPrint=false": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group", "labels": {"cloudidentity.googleapis.com/groups.discussion_forum": ""}}`)),
+				},
+				"https://cloudidentity.googleapis.com/v1/groups/test-group?alt=json&prettyPrint=false&updateMask=labels": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"done": true, "response": {"name": "groups/test-group"}}`)),
+				},
+			},
+			want: "successfully updated the group " + helpers.GreenValue("groups/test-group"),
+		},
+		{
+			name: "update security group to discussion",
+			args: []string{"update", "-g", "test-group", "-type", "discussion"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+				"/v1/groups/test-group": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group", "labels": {"cloudidentity.googleapis.com/groups.discussion_forum": "", "cloudidentity.googleapis.com/groups.security": ""}}`)),
+				},
+			},
+			want: "a security group cannot be changed to discussion",
+		},
+		{
+			name: "delete group success",
+			args: []string{"delete", "-g", "test-group"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+				"/v1/groups/test-group": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"done": true}`)),
+				},
+			},
+			want: "successfully deleted the group",
+		},
+		{
+			name: "delete group http call error",
+			args: []string{"delete", "-g", "test-group"},
+			transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+			},
+			httpCallError: errors.New("http call error"),
+			errorOnIndex:  1,
+			want:          "failed to delete the group",
+		},
+		{
+			name: "group flag not provided",
+			args: []string{"describe"},
+			want: "group cannot be empty",
+		},
+		{
+			name: "invalid action",
+			args: []string{"rename", "-g", "test-group"},
+			want: "provide a valid action",
+		},
+		{
+			name: "invalid flag after action",
+			args: []string{"create", "-x"},
+			want: "flag provided but not defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandGroupConfig
+			c.GetFlagSet().Parse(tt.args)
+			Config.CustomerID = tt.customerID
+			instance.CloudIdentityService, _ = cloudidentity.NewService(context.Background(),
+				option.WithHTTPClient(&http.Client{
+					Transport: &MockHTTPRoundTripper{
+						Transport:    tt.transport,
+						Error:        tt.httpCallError,
+						ErrorOnIndex: tt.errorOnIndex,
+					},
+				}),
+			)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandGroupConfig.Execute() = %v, want %v", got, tt.want)
+			}
+			instance.Groups = make(map[string]*cloudidentity.LookupGroupNameResponse)
+		})
+	}
+}
@@ -1,13 +1,16 @@
 package google
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"golang.org/x/net/context"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/impersonate"
 	"google.golang.org/api/option"
 )
 
@@ -20,6 +23,16 @@ type GCEConfig struct {
 	ServiceAccountKeyFile string `json:"serviceAccountKeyFile" yaml:"serviceAccountKeyFile"`
 	// JwkURL is the URL where the JWK to validate the instance identity token can be found.
 	JwkURL string `json:"jwkURL" yaml:"jwkURL"`
+	// Domain is the default domain used to qualify short group names, e.g. example.com
+	Domain string `json:"domain" yaml:"domain"`
+	// CustomerID is the Google Workspace customer ID under which new groups are created.
+	CustomerID string `json:"customerID" yaml:"customerID"`
+	// CredentialSource selects the credentials used to access google APIs, keyfile (default) or adc.
+	CredentialSource string `json:"credentialSource" yaml:"credentialSource"`
+	// ImpersonateServiceAccount is the email of the service account to impersonate, optional.
+	ImpersonateServiceAccount string `json:"impersonateServiceAccount" yaml:"impersonateServiceAccount"`
+	// Subject is the user to act as through domain-wide delegation, optional.
+	Subject string `json:"subject" yaml:"subject"`
 	// clientOpts is a function that returns a client options.
 	// It is used add additional options to the client.
 	clientOpts getOpts
@@ -27,33 +40,107 @@ type GCEConfig struct {
 
 // Config contains the actual values from service.yml file
 var Config = GCEConfig{
-	ServiceAccountKeyFile: os.Getenv("GOOGLE_KEYFILE"),
-	JwkURL:                os.Getenv("GOOGLE_JWK_URL"),
+	ServiceAccountKeyFile:     os.Getenv("GOOGLE_KEYFILE"),
+	JwkURL:                    os.Getenv("GOOGLE_JWK_URL"),
+	Domain:                    os.Getenv("GOOGLE_GCP_DOMAIN"),
+	CustomerID:                os.Getenv("GOOGLE_CUSTOMER_ID"),
+	CredentialSource:          os.Getenv("GOOGLE_CREDENTIAL_SOURCE"),
+	ImpersonateServiceAccount: os.Getenv("GOOGLE_IMPERSONATE_SERVICE_ACCOUNT"),
+	Subject:                   os.Getenv("GOOGLE_SUBJECT"),
 }
 
 type getOpts func() ([]option.ClientOption, error)
 
+const (
+	// KeyFileCredentialSource uses the service account JSON key file referenced by GOOGLE_KEYFILE
+	KeyFileCredentialSource = "keyfile"
+	// ADCCredentialSource uses Application Default Credentials, i.e. the file referenced by
+	// GOOGLE_APPLICATION_CREDENTIALS (service account keys, workload identity federation configs),
+	// gcloud user credentials or the metadata server
+	ADCCredentialSource = "adc"
+
+	// cloudPlatformScope is the scope of the base credentials used for impersonation
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// bindAuthFlags registers the authentication flags on the given flag set
+func bindAuthFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&Config.ImpersonateServiceAccount, "impersonate-service-account", os.Getenv("GOOGLE_IMPERSONATE_SERVICE_ACCOUNT"), "service account email to impersonate")
+	flagSet.StringVar(&Config.Subject, "subject", os.Getenv("GOOGLE_SUBJECT"), "user email to act as through domain-wide delegation")
+}
+
+// tokenSource returns the token source for the configured credential source.
+// When a service account to impersonate is configured, the credentials of the source are
+// only used as base credentials to mint tokens for the impersonated service account.
+func (c GCEConfig) tokenSource(ctx context.Context) (oauth2.TokenSource, error) {
+	scopes := []string{cloudidentity.CloudIdentityGroupsScope}
+	subject := c.Subject
+	if c.ImpersonateServiceAccount != "" {
+		// the base credentials need to call the IAM credentials API,
+		// the subject is set on the impersonated credentials
+		scopes = []string{cloudPlatformScope}
+		subject = ""
+	}
+
+	var base oauth2.TokenSource
+	switch c.CredentialSource {
+	case "", KeyFileCredentialSource:
+		// get the serviceAccountKeyFile
+		jsonCredentials, err := os.ReadFile(c.ServiceAccountKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// parse the serviceAccountKeyFile
+		config, err := google.JWTConfigFromJSON(jsonCredentials, scopes...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse service account key file to config: %v", err)
+		}
+		config.Subject = subject
+		base = config.TokenSource(ctx)
+	case ADCCredentialSource:
+		creds, err := google.FindDefaultCredentialsWithParams(ctx, google.CredentialsParams{
+			Scopes:  scopes,
+			Subject: subject,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to find application default credentials: %v", err)
+		}
+		base = creds.TokenSource
+	default:
+		return nil, fmt.Errorf("invalid credential source %s, must be one of %s|%s", c.CredentialSource, KeyFileCredentialSource, ADCCredentialSource)
+	}
+
+	if c.ImpersonateServiceAccount == "" {
+		return base, nil
+	}
+
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: c.ImpersonateServiceAccount,
+		Scopes:          []string{cloudidentity.CloudIdentityGroupsScope},
+		Subject:         c.Subject,
+	}, option.WithTokenSource(base))
+	if err != nil {
+		return nil, fmt.Errorf("unable to impersonate service account %s: %v", c.ImpersonateServiceAccount, err)
+	}
+	return ts, nil
+}
+
 // NewService creates a new cloudidentity.service from the GCEConfig.
 func (c GCEConfig) NewService() (*Service, error) {
 	Config.ServiceAccountKeyFile = os.Getenv("GOOGLE_KEYFILE")
+	Config.CredentialSource = os.Getenv("GOOGLE_CREDENTIAL_SOURCE")
+	Config.Domain = os.Getenv("GOOGLE_GCP_DOMAIN")
+	Config.CustomerID = os.Getenv("GOOGLE_CUSTOMER_ID")
 
 	var err error
 	var ctx = context.Background()
 
-	// get the serviceAccountKeyFile
-	jsonCredentials, err := os.ReadFile(Config.ServiceAccountKeyFile)
+	ts, err := Config.tokenSource(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// parse the serviceAccountKeyFile
-	config, err := google.JWTConfigFromJSON(jsonCredentials,
-		cloudidentity.CloudIdentityGroupsScope)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse service account key file to config: %v", err)
-	}
-	ts := config.TokenSource(ctx)
-
 	opts := []option.ClientOption{option.WithTokenSource(ts)}
 
 	// if clientOpts is set, use it to get the client options
@@ -102,6 +189,12 @@ func (s *Service) GetGroup(groupID string) *cloudidentity.LookupGroupNameRespons
 	return nil
 }
 
+// RemoveGroup removes the cached cloudidentity.LookupGroupNameResponse for the groupID
+// from the Groups field of Service.
+func (s *Service) RemoveGroup(groupID string) {
+	delete(s.Groups, strings.ToLower(groupID))
+}
+
 // StartService starts the Google service
 func StartService() error {
 	var err error
@@ -45,9 +45,61 @@ func TestStartService(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "test start service with subject for domain-wide delegation",
+			modifyConfig: func() {
+				os.Setenv("GOOGLE_KEYFILE", "testdata/google-keyfile.json")
+				Config.Subject = "admin@example.com"
+			},
+			wantErr: false,
+		},
+		{
+			name: "test start service with impersonated service account",
+			modifyConfig: func() {
+				os.Setenv("GOOGLE_KEYFILE", "testdata/google-keyfile.json")
+				Config.ImpersonateServiceAccount = "unfold@test-project.iam.gserviceaccount.com"
+				Config.Subject = "admin@example.com"
+			},
+			wantErr: false,
+		},
+		{
+			name: "test start service with application default credentials",
+			modifyConfig: func() {
+				os.Setenv("GOOGLE_CREDENTIAL_SOURCE", ADCCredentialSource)
+				os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "testdata/google-keyfile.json")
+			},
+			wantErr: false,
+		},
+		{
+			name: "test start service with application default credentials and impersonation",
+			modifyConfig: func() {
+				os.Setenv("GOOGLE_CREDENTIAL_SOURCE", ADCCredentialSource)
+				os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "testdata/google-keyfile.json")
+				Config.ImpersonateServiceAccount = "unfold@test-project.iam.gserviceaccount.com"
+			},
+			wantErr: false,
+		},
+		{
+			name: "test start service error application default credentials not found",
+			modifyConfig: func() {
+				os.Setenv("GOOGLE_CREDENTIAL_SOURCE", ADCCredentialSource)
+				os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "testdata/google-keyfile-not-found.json")
+			},
+			wantErr: true,
+		},
+		{
+			name: "test start service error invalid credential source",
+			modifyConfig: func() {
+				os.Setenv("GOOGLE_CREDENTIAL_SOURCE", "password")
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_CREDENTIAL_SOURCE", "")
+			t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+			Config.ImpersonateServiceAccount, Config.Subject, Config.clientOpts = "", "", nil
 			tt.modifyConfig()
 			if err := StartService(); (err != nil) != tt.wantErr {
 				t.Errorf("StartService() error = %v, wantErr %v", err, tt.wantErr)
@@ -1,48 +1,134 @@
 package google
 
 import (
-	"os"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
 
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/plan"
 	ci "google.golang.org/api/cloudidentity/v1"
 )
 
+// groupResourcePrefix is the prefix of cloud identity group resource names
+const groupResourcePrefix = "groups/"
+
+// NormalizeGroupID normalizes the given group identifier to either the full lower-cased
+// group email or the group resource name. It accepts:
+//   - full group emails, e.g. team@example.com
+//   - short group names, qualified with the configured default domain, e.g. team
+//   - group resource names, e.g. groups/01abcd23efg
+func NormalizeGroupID(groupID string) (key string, isResourceName bool, err error) {
+	id := strings.TrimSpace(groupID)
+	if id == "" {
+		return "", false, errors.New("group cannot be empty")
+	}
+
+	if strings.HasPrefix(id, groupResourcePrefix) {
+		name := strings.TrimPrefix(id, groupResourcePrefix)
+		if name == "" || strings.ContainsAny(name, "/@") {
+			return "", false, fmt.Errorf("invalid group resource name %s, expected groups/<id>", id)
+		}
+		return id, true, nil
+	}
+
+	if strings.Contains(id, "/") {
+		return "", false, fmt.Errorf("invalid group identifier %s, expected an email, a short name or groups/<id>", id)
+	}
+
+	if strings.Contains(id, "@") {
+		local, domain, _ := strings.Cut(id, "@")
+		if local == "" || domain == "" || strings.Contains(domain, "@") {
+			return "", false, fmt.Errorf("ambiguous group identifier %s, expected a single email address", id)
+		}
+		return strings.ToLower(id), false, nil
+	}
+
+	domain := strings.TrimPrefix(Config.Domain, "@")
+	if domain == "" {
+		return "", false, fmt.Errorf("group %s has no domain, provide the full group email or set GOOGLE_GCP_DOMAIN", id)
+	}
+	return strings.ToLower(id + "@" + domain), false, nil
+}
+
 // GetGroupByID returns information about the group for the given groupID
 // from the Instance if already present or calls the google APIs for the same.
 func GetGroupByID(groupID string) (*ci.LookupGroupNameResponse, error) {
+	key, isResourceName, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resource names identify the group already, no lookup is required.
+	if isResourceName {
+		return &ci.LookupGroupNameResponse{Name: key}, nil
+	}
+
 	// If the information for the group is already present in the instance,
 	// return the value from instance.
-	if g := instance.GetGroup(groupID); g != nil {
+	if g := instance.GetGroup(key); g != nil {
+		return g, nil
+	}
+
+	// Otherwise use the group information resolved by a previous invocation, if still valid.
+	g := &ci.LookupGroupNameResponse{}
+	if cache.Get(groupCacheKey(key), g) {
+		instance.AddGroup(key, g)
 		return g, nil
 	}
 
 	svc := instance.CloudIdentityService
 
-	id := groupID + os.Getenv("GOOGLE_GCP_DOMAIN")
-	g, err := svc.Groups.Lookup().GroupKeyId(id).Do()
+	g, err = svc.Groups.Lookup().GroupKeyId(key).Do()
 	if err != nil {
 		return nil, err
 	}
 
 	// Add group information to the instance to avoid repeated calls to GCE for same information.
-	instance.AddGroup(groupID, g)
+	instance.AddGroup(key, g)
+	cache.Set(groupCacheKey(key), g, groupCacheTTL)
 	return g, nil
 }
 
+// groupCacheKey returns the on-disk cache key for the group name resolution of the normalized group key
+func groupCacheKey(key string) string {
+	return "google/group/" + key
+}
+
 // AddMemberToGroupID adds a member (by emailID) to the group of given groupID
 func AddMemberToGroupID(groupID string, emailID string) error {
+	return addMemberWithRole(groupID, emailID, MemberRole)
+}
+
+// addMemberWithRole adds a member (by emailID) to the group of given groupID with the role.
+// Managers and owners are members as well.
+func addMemberWithRole(groupID string, emailID string, role string) error {
 	// Get Group by groupID
 	g, hErr := GetGroupByID(groupID)
 	if hErr != nil {
 		return hErr
 	}
+	return createMembership(plan.Operation{Kind: plan.GoogleMembership, Mode: plan.AddMode, Group: groupID, GroupName: g.Name, Member: emailID, Role: role})
+}
 
+// createMembership adds the member of the operation to its group resource with its role
+func createMembership(op plan.Operation) error {
+	roles := []*ci.MembershipRole{{Name: MemberRole}}
+	if op.Role != MemberRole {
+		roles = append(roles, &ci.MembershipRole{Name: op.Role})
+	}
 	membership := ci.Membership{
-		PreferredMemberKey: &ci.EntityKey{Id: emailID},
-		Roles:              []*ci.MembershipRole{{Name: "MEMBER"}},
+		PreferredMemberKey: &ci.EntityKey{Id: op.Member},
+		Roles:              roles,
 	}
 
 	svc := instance.CloudIdentityService
-	_, err := svc.Groups.Memberships.Create(g.Name, &membership).Do()
+	_, err := svc.Groups.Memberships.Create(op.GroupName, &membership).Do()
+	audit.Record(audit.Entry{Action: "google membership add", Targets: []string{op.Group, op.GroupName, op.Member}, Operation: &op}, err)
 	if err != nil {
 		return err
 	}
@@ -56,12 +142,225 @@ func RemoveMemberFromGroupID(groupID string, emailID string) error {
 	if err != nil {
 		return err
 	}
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return err
+	}
 
+	return deleteMembership(plan.Operation{
+		Kind:           plan.GoogleMembership,
+		Mode:           plan.RemoveMode,
+		Group:          groupID,
+		GroupName:      g.Name,
+		Member:         emailID,
+		Role:           HighestRole(toMember(membership).Roles),
+		MembershipName: membership.Name,
+	})
+}
+
+// deleteMembership removes the membership of the operation
+func deleteMembership(op plan.Operation) error {
 	svc := instance.CloudIdentityService
-	_, err = svc.Groups.Memberships.Delete(membership.Name).Do()
+	_, err := svc.Groups.Memberships.Delete(op.MembershipName).Do()
+	audit.Record(audit.Entry{Action: "google membership remove", Targets: []string{op.Group, op.GroupName, op.Member}, Operation: &op}, err)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+const (
+	// groupCacheTTL is the duration for which a resolved group name is cached on disk
+	groupCacheTTL = 24 * time.Hour
+
+	// DiscussionGroupType is the type of a group acting as an email list / discussion forum
+	DiscussionGroupType = "discussion"
+	// SecurityGroupType is the type of a group that can be used to grant access to resources
+	SecurityGroupType = "security"
+
+	discussionForumLabel = "cloudidentity.googleapis.com/groups.discussion_forum"
+	securityLabel        = "cloudidentity.googleapis.com/groups.security"
+)
+
+// GroupOpts represents the mutable details of a google group
+type GroupOpts struct {
+	DisplayName string
+	Description string
+	Type        string
+}
+
+// groupLabels returns the cloud identity labels for the given group type.
+// Security groups must carry the discussion forum label as well.
+func groupLabels(groupType string) (map[string]string, error) {
+	switch strings.ToLower(groupType) {
+	case "", DiscussionGroupType:
+		return map[string]string{discussionForumLabel: ""}, nil
+	case SecurityGroupType:
+		return map[string]string{discussionForumLabel: "", securityLabel: ""}, nil
+	default:
+		return nil, fmt.Errorf("invalid group type %s, must be one of %s|%s", groupType, DiscussionGroupType, SecurityGroupType)
+	}
+}
+
+// mergeGroupLabels returns the current labels of a group along with the labels of the given group type.
+// Cloud Identity does not allow removing the security label, so a security group cannot change type.
+func mergeGroupLabels(current map[string]string, groupType string) (map[string]string, error) {
+	labels, err := groupLabels(groupType)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := current[securityLabel]; ok {
+		if _, ok := labels[securityLabel]; !ok {
+			return nil, fmt.Errorf("a %s group cannot be changed to %s, cloud identity does not allow removing the security label", SecurityGroupType, groupType)
+		}
+	}
+
+	merged := maps.Clone(current)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, labels)
+	return merged, nil
+}
+
+// groupFromOperation extracts the group from the response of a long running operation
+// and falls back to the given group when the operation is not done yet.
+func groupFromOperation(op *ci.Operation, fallback *ci.Group) (*ci.Group, error) {
+	if op.Error != nil {
+		return nil, fmt.Errorf("operation failed with code %d: %s", op.Error.Code, op.Error.Message)
+	}
+	if !op.Done || len(op.Response) == 0 {
+		return fallback, nil
+	}
+
+	g := &ci.Group{}
+	if err := json.Unmarshal(op.Response, g); err != nil {
+		return nil, fmt.Errorf("json decode %w", err)
+	}
+	return g, nil
+}
+
+// CreateGroup creates a new group for the given groupID under the configured customer
+func CreateGroup(groupID string, opts GroupOpts) (*ci.Group, error) {
+	if Config.CustomerID == "" {
+		return nil, errors.New("customer id is required to create a group, set GOOGLE_CUSTOMER_ID")
+	}
+
+	labels, err := groupLabels(opts.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	key, isResourceName, err := NormalizeGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if isResourceName {
+		return nil, fmt.Errorf("cannot create a group from the resource name %s, provide the group email", key)
+	}
+
+	if opts.DisplayName == "" {
+		opts.DisplayName = groupID
+	}
+
+	group := &ci.Group{
+		Parent:      "customers/" + Config.CustomerID,
+		GroupKey:    &ci.EntityKey{Id: key},
+		DisplayName: opts.DisplayName,
+		Description: opts.Description,
+		Labels:      labels,
+	}
+
+	svc := instance.CloudIdentityService
+	op, err := svc.Groups.Create(group).InitialGroupConfig("EMPTY").Do()
+	audit.Record(audit.Entry{Action: "google group create", Targets: []string{key}}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return groupFromOperation(op, group)
+}
+
+// DescribeGroup returns the full details of the group for the given groupID
+func DescribeGroup(groupID string) (*ci.Group, error) {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := instance.CloudIdentityService
+	return svc.Groups.Get(g.Name).Do()
+}
+
+// UpdateGroup updates the display name, description and type of the group for the given groupID.
+// Only the provided values are updated.
+func UpdateGroup(groupID string, opts GroupOpts) (*ci.Group, error) {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &ci.Group{}
+	mask := []string{}
+
+	if opts.DisplayName != "" {
+		group.DisplayName = opts.DisplayName
+		mask = append(mask, "display_name")
+	}
+	if opts.Description != "" {
+		group.Description = opts.Description
+		mask = append(mask, "description")
+	}
+	if len(mask) == 0 && opts.Type == "" {
+		return nil, errors.New("nothing to update, provide a display name, description or type")
+	}
+
+	svc := instance.CloudIdentityService
+	if opts.Type != "" {
+		// The labels are replaced as a whole, the type labels are merged into the current ones
+		current, err := svc.Groups.Get(g.Name).Do()
+		if err != nil {
+			return nil, err
+		}
+		group.Labels, err = mergeGroupLabels(current.Labels, opts.Type)
+		if err != nil {
+			return nil, err
+		}
+		mask = append(mask, "labels")
+	}
+
+	op, err := svc.Groups.Patch(g.Name, group).UpdateMask(strings.Join(mask, ",")).Do()
+	audit.Record(audit.Entry{Action: "google group update", Targets: []string{groupID, g.Name}}, err)
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = g.Name
+	return groupFromOperation(op, group)
+}
+
+// DeleteGroup deletes the group for the given groupID
+func DeleteGroup(groupID string) error {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return err
+	}
+
+	svc := instance.CloudIdentityService
+	op, err := svc.Groups.Delete(g.Name).Do()
+	if err == nil && op.Error != nil {
+		err = fmt.Errorf("operation failed with code %d: %s", op.Error.Code, op.Error.Message)
+	}
+	audit.Record(audit.Entry{Action: "google group delete", Targets: []string{groupID, g.Name}}, err)
+	if err != nil {
+		return err
+	}
+
+	// Remove the deleted group from the instance and the cache so that it is not resolved again.
+	if key, isResourceName, _ := NormalizeGroupID(groupID); !isResourceName {
+		instance.RemoveGroup(key)
+		cache.Delete(groupCacheKey(key))
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package google
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	cloudidentity "google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+func TestNormalizeGroupID(t *testing.T) {
+	tests := []struct {
+		name             string
+		groupID          string
+		domain           string
+		want             string
+		wantResourceName bool
+		wantErr          string
+	}{
+		{
+			name:    "short name with default domain",
+			groupID: "Team",
+			domain:  "@example.com",
+			want:    "team@example.com",
+		},
+		{
+			name:    "short name with default domain without @",
+			groupID: "team",
+			domain:  "example.com",
+			want:    "team@example.com",
+		},
+		{
+			name:    "full email ignores default domain",
+			groupID: "Team@Other.com",
+			domain:  "@example.com",
+			want:    "team@other.com",
+		},
+		{
+			name:             "resource name",
+			groupID:          "groups/01abcd23efg",
+			want:             "groups/01abcd23efg",
+			wantResourceName: true,
+		},
+		{
+			name:    "short name without default domain",
+			groupID: "team",
+			wantErr: "has no domain",
+		},
+		{
+			name:    "email with multiple domains",
+			groupID: "team@example.com@example.com",
+			wantErr: "ambiguous group identifier",
+		},
+		{
+			name:    "email without local part",
+			groupID: "@example.com",
+			wantErr: "ambiguous group identifier",
+		},
+		{
+			name:    "empty resource name",
+			groupID: "groups/",
+			wantErr: "invalid group resource name",
+		},
+		{
+			name:    "membership resource name",
+			groupID: "groups/abc/memberships/xyz",
+			wantErr: "invalid group resource name",
+		},
+		{
+			name:    "unknown resource name",
+			groupID: "customers/abc",
+			wantErr: "invalid group identifier",
+		},
+		{
+			name:    "empty group",
+			groupID: " ",
+			wantErr: "group cannot be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config.Domain = tt.domain
+			got, isResourceName, err := NormalizeGroupID(tt.groupID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NormalizeGroupID() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want || isResourceName != tt.wantResourceName {
+				t.Errorf("NormalizeGroupID() = %v, %v, %v, want %v, %v", got, isResourceName, err, tt.want, tt.wantResourceName)
+			}
+		})
+	}
+}
+
+func TestGetGroupByID_consistentKeys(t *testing.T) {
+	prepareTestEnvironment()
+	instance.CloudIdentityService, _ = cloudidentity.NewService(context.Background(),
+		option.WithHTTPClient(&http.Client{
+			Transport: &MockHTTPRoundTripper{},
+		}),
+	)
+	defer func() { instance.Groups = make(map[string]*cloudidentity.LookupGroupNameResponse) }()
+
+	// the short and full forms share the same instance entry, no lookup is performed
+	instance.AddGroup("team@example.com", &cloudidentity.LookupGroupNameResponse{Name: "groups/team"})
+	for _, id := range []string{"team", "TEAM@example.com", "groups/team"} {
+		g, err := GetGroupByID(id)
+		if err != nil || g.Name != "groups/team" {
+			t.Errorf("GetGroupByID(%s) = %v, %v, want groups/team", id, g, err)
+		}
+	}
+}
+
+func TestMergeGroupLabels(t *testing.T) {
+	const lockedLabel = "cloudidentity.googleapis.com/groups.locked"
+	tests := []struct {
+		name      string
+		current   map[string]string
+		groupType string
+		want      map[string]string
+		wantErr   string
+	}{
+		{
+			name:      "discussion to security keeps the other labels",
+			current:   map[string]string{discussionForumLabel: "", lockedLabel: ""},
+			groupType: SecurityGroupType,
+			want:      map[string]string{discussionForumLabel: "", securityLabel: "", lockedLabel: ""},
+		},
+		{
+			name:      "security stays security",
+			current:   map[string]string{discussionForumLabel: "", securityLabel: ""},
+			groupType: "Security",
+			want:      map[string]string{discussionForumLabel: "", securityLabel: ""},
+		},
+		{
+			name:      "group without labels",
+			groupType: DiscussionGroupType,
+			want:      map[string]string{discussionForumLabel: ""},
+		},
+		{
+			name:      "security to discussion",
+			current:   map[string]string{discussionForumLabel: "", securityLabel: ""},
+			groupType: DiscussionGroupType,
+			wantErr:   "a security group cannot be changed to discussion",
+		},
+		{
+			name:      "invalid type",
+			groupType: "mailing",
+			wantErr:   "invalid group type mailing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeGroupLabels(tt.current, tt.groupType)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("mergeGroupLabels() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeGroupLabels() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeGroupLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,9 @@
 package google
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/aryannr97/unfold/pkg/directory"
 	"google.golang.org/api/cloudidentity/v1"
 )
 
@@ -17,12 +17,28 @@ func CheckGroupMembershipForEmailIDs(groupID string, emailID string) (found *clo
 		return nil, hErr
 	}
 
+	memberships, err := listMemberships(g.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range memberships {
+		if strings.EqualFold(m.PreferredMemberKey.Id, strings.ToLower(emailID)) {
+			return m, nil
+		}
+	}
+
+	return nil, directory.ErrMemberNotFound
+}
+
+// listMemberships returns all the memberships of the group resource, following the page tokens
+func listMemberships(groupName string) ([]*cloudidentity.Membership, error) {
 	svc := instance.CloudIdentityService
 	var nextPageToken string
 	memberships := []*cloudidentity.Membership{}
 
 	for {
-		call := svc.Groups.Memberships.List(g.Name).PageSize(100)
+		call := svc.Groups.Memberships.List(groupName).PageSize(100)
 		if nextPageToken != "" {
 			call.PageToken(nextPageToken)
 		}
@@ -39,12 +55,5 @@ func CheckGroupMembershipForEmailIDs(groupID string, emailID string) (found *clo
 		}
 		nextPageToken = resp.NextPageToken
 	}
-
-	for _, m := range memberships {
-		if strings.EqualFold(m.PreferredMemberKey.Id, strings.ToLower(emailID)) {
-			return m, nil
-		}
-	}
-
-	return nil, fmt.Errorf("member not found")
+	return memberships, nil
 }
@@ -1,17 +1,27 @@
 package google
 
+import (
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/directory"
+)
+
 // CommandModule represents the collection of different command configs
 type CommandModule struct {
 	CommandGetConfig       commandGetConfig
-	CommandConfigureConfig commandConfigureConfig
-	CommandSearchConfig    commandSearchConfig
+	CommandConfigureConfig directory.CommandConfigureConfig
+	CommandSearchConfig    directory.CommandSearchConfig
+	CommandExportConfig    directory.CommandExportConfig
+	CommandGroupConfig     commandGroupConfig
 }
 
 // NewCommandModule returns the command module
 func NewCommandModule() *CommandModule {
+	provider := NewGroupProvider()
 	return &CommandModule{
 		CommandGetConfig:       fetchCommandGetConfig(),
-		CommandConfigureConfig: fetchCommandConfigureConfig(),
-		CommandSearchConfig:    fetchCommandSearchConfig(),
+		CommandConfigureConfig: directory.NewCommandConfigureConfig(provider, cache.BindFlags, bindAuthFlags),
+		CommandSearchConfig:    directory.NewCommandSearchConfig(provider, cache.BindFlags, bindAuthFlags),
+		CommandExportConfig:    directory.NewCommandExportConfig(provider, cache.BindFlags, bindAuthFlags),
+		CommandGroupConfig:     fetchCommandGroupConfig(),
 	}
 }
@@ -0,0 +1,127 @@
+package google
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/directory"
+	"github.com/aryannr97/unfold/pkg/plan"
+	ci "google.golang.org/api/cloudidentity/v1"
+)
+
+// PlanMembership resolves the group into the operation adding the member with the role, or removing its membership
+func PlanMembership(groupID, emailID, role string, remove bool) (*plan.Operation, error) {
+	if emailID == "" {
+		return nil, errors.New("emailID cannot be empty")
+	}
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	op := &plan.Operation{Kind: plan.GoogleMembership, Group: groupID, GroupName: g.Name, Member: strings.ToLower(emailID)}
+
+	m, err := CheckGroupMembershipForEmailIDs(groupID, emailID)
+	if err != nil && !errors.Is(err, directory.ErrMemberNotFound) {
+		return nil, err
+	}
+	if remove {
+		if m == nil {
+			return nil, fmt.Errorf("%s is not a member of the group %s", emailID, groupID)
+		}
+		op.Mode, op.MembershipName, op.Role = plan.RemoveMode, m.Name, HighestRole(toMember(m).Roles)
+		return op, nil
+	}
+	if m != nil {
+		return nil, fmt.Errorf("%s is already a member of the group %s", emailID, groupID)
+	}
+	op.Mode, op.Role = plan.AddMode, role
+	return op, nil
+}
+
+// ValidatePlannedMembership checks the group still resolves to the group resource of the operation
+// and the membership is still as planned, i.e. absent to add it and unchanged to remove it
+func ValidatePlannedMembership(op plan.Operation) error {
+	key, isResourceName, err := NormalizeGroupID(op.Group)
+	if err != nil {
+		return err
+	}
+	if !isResourceName {
+		// the group may have been recreated since the plan was written, bypass the caches
+		instance.RemoveGroup(key)
+		cache.Delete(groupCacheKey(key))
+	}
+	g, err := GetGroupByID(op.Group)
+	if err != nil {
+		return err
+	}
+	if g.Name != op.GroupName {
+		return fmt.Errorf("group %s now resolves to %s instead of %s", op.Group, g.Name, op.GroupName)
+	}
+
+	memberships, err := listMemberships(op.GroupName)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(memberships, func(m *ci.Membership) bool {
+		return m.PreferredMemberKey != nil && strings.EqualFold(m.PreferredMemberKey.Id, op.Member)
+	})
+	switch {
+	case op.Mode == plan.AddMode && i >= 0:
+		return fmt.Errorf("%s is already a member of the group %s", op.Member, op.Group)
+	case op.Mode == plan.RemoveMode && i < 0:
+		return fmt.Errorf("%s is no longer a member of the group %s", op.Member, op.Group)
+	case op.Mode == plan.RemoveMode && memberships[i].Name != op.MembershipName:
+		return fmt.Errorf("the membership of %s in the group %s is now %s instead of %s", op.Member, op.Group, memberships[i].Name, op.MembershipName)
+	}
+	return nil
+}
+
+// PlanUndo checks the membership is still as the executed operation left it and returns the operation reverting it.
+// Cloud Identity refuses to create an existing membership or delete a missing one, an executed operation
+// always changed the membership, unlike a private audience change that may leave plans unchanged.
+func PlanUndo(op plan.Operation) (*plan.Operation, error) {
+	// the group resource of the operation is used as is, a group recreated since is another group
+	m, err := CheckGroupMembershipForEmailIDs(op.GroupName, op.Member)
+	if err != nil && !errors.Is(err, directory.ErrMemberNotFound) {
+		return nil, err
+	}
+
+	undo := op
+	if op.Mode == plan.RemoveMode {
+		if m != nil {
+			return nil, fmt.Errorf("%s is a member of the group %s again", op.Member, op.Group)
+		}
+		undo.Mode, undo.MembershipName = plan.AddMode, ""
+		return &undo, nil
+	}
+
+	if m == nil {
+		return nil, fmt.Errorf("%s is no longer a member of the group %s", op.Member, op.Group)
+	}
+	if role := HighestRole(toMember(m).Roles); role != op.Role {
+		return nil, fmt.Errorf("the role of %s in the group %s changed from %s to %s", op.Member, op.Group, op.Role, role)
+	}
+	undo.Mode, undo.MembershipName = plan.RemoveMode, m.Name
+	return &undo, nil
+}
+
+// ApplyPlannedMembership creates or deletes the membership of the operation, exactly on its group resource
+func ApplyPlannedMembership(op plan.Operation) error {
+	if op.Mode == plan.RemoveMode {
+		return deleteMembership(op)
+	}
+	return createMembership(op)
+}
+
+// HighestRole returns the most privileged of the membership roles
+func HighestRole(roles []string) string {
+	for _, role := range []string{OwnerRole, ManagerRole} {
+		if slices.Contains(roles, role) {
+			return role
+		}
+	}
+	return MemberRole
+}
@@ -0,0 +1,232 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/plan"
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+// stubTransport answers each request path with a fresh body, so the same path can be requested repeatedly
+type stubTransport map[string]string
+
+func (s stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := s[req.Method+" "+req.URL.Path]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(bytes.NewBufferString(`{"error": "not found"}`))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+}
+
+// useStubTransport points the cloud identity service at the stubbed responses
+func useStubTransport(t *testing.T, s stubTransport) {
+	t.Helper()
+	prepareTestEnvironment()
+	instance.CloudIdentityService, _ = cloudidentity.NewService(context.Background(),
+		option.WithHTTPClient(&http.Client{Transport: s}))
+	instance.Groups = make(map[string]*cloudidentity.LookupGroupNameResponse)
+}
+
+const stubMemberships = `{"memberships": [{"name": "groups/test-group/memberships/123", "preferredMemberKey": {"id": "alice@example.com"}, "roles": [{"name": "MEMBER"}, {"name": "MANAGER"}]}]}`
+
+func TestPlanMembership(t *testing.T) {
+	tests := []struct {
+		name    string
+		emailID string
+		role    string
+		remove  bool
+		want    *plan.Operation
+		wantErr string
+	}{
+		{
+			name:    "plan add",
+			emailID: "Bob@example.com",
+			role:    OwnerRole,
+			want:    &plan.Operation{Kind: plan.GoogleMembership, Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "bob@example.com", Role: OwnerRole},
+		},
+		{
+			name:    "plan remove records the membership and its role",
+			emailID: "alice@example.com",
+			remove:  true,
+			want:    &plan.Operation{Kind: plan.GoogleMembership, Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", Role: ManagerRole, MembershipName: "groups/test-group/memberships/123"},
+		},
+		{
+			name:    "plan add of a member",
+			emailID: "alice@example.com",
+			role:    MemberRole,
+			wantErr: "alice@example.com is already a member of the group test-group",
+		},
+		{
+			name:    "plan remove of a non member",
+			emailID: "bob@example.com",
+			remove:  true,
+			wantErr: "bob@example.com is not a member of the group test-group",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStubTransport(t, stubTransport{
+				"GET /v1/groups:lookup":                 `{"name": "groups/test-group"}`,
+				"GET /v1/groups/test-group/memberships": stubMemberships,
+			})
+			got, err := PlanMembership("test-group", tt.emailID, tt.role, tt.remove)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("PlanMembership() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanMembership() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanMembership() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePlannedMembership(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      plan.Operation
+		lookup  string
+		wantErr string
+	}{
+		{
+			name:   "add still current",
+			op:     plan.Operation{Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "bob@example.com"},
+			lookup: `{"name": "groups/test-group"}`,
+		},
+		{
+			name:   "remove still current",
+			op:     plan.Operation{Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", MembershipName: "groups/test-group/memberships/123"},
+			lookup: `{"name": "groups/test-group"}`,
+		},
+		{
+			name:    "group recreated",
+			op:      plan.Operation{Mode: plan.AddMode, Group: "test-group", GroupName: "groups/old-group", Member: "bob@example.com"},
+			lookup:  `{"name": "groups/test-group"}`,
+			wantErr: "group test-group now resolves to groups/test-group instead of groups/old-group",
+		},
+		{
+			name:    "member added since the plan",
+			op:      plan.Operation{Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com"},
+			lookup:  `{"name": "groups/test-group"}`,
+			wantErr: "alice@example.com is already a member of the group test-group",
+		},
+		{
+			name:    "member removed since the plan",
+			op:      plan.Operation{Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "bob@example.com", MembershipName: "groups/test-group/memberships/456"},
+			lookup:  `{"name": "groups/test-group"}`,
+			wantErr: "bob@example.com is no longer a member of the group test-group",
+		},
+		{
+			name:    "membership recreated since the plan",
+			op:      plan.Operation{Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", MembershipName: "groups/test-group/memberships/456"},
+			lookup:  `{"name": "groups/test-group"}`,
+			wantErr: "the membership of alice@example.com in the group test-group is now groups/test-group/memberships/123 instead of groups/test-group/memberships/456",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStubTransport(t, stubTransport{
+				"GET /v1/groups:lookup":                 tt.lookup,
+				"GET /v1/groups/test-group/memberships": stubMemberships,
+			})
+			err := ValidatePlannedMembership(tt.op)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ValidatePlannedMembership() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ValidatePlannedMembership() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyPlannedMembership(t *testing.T) {
+	useStubTransport(t, stubTransport{
+		"DELETE /v1/groups/test-group/memberships/123": `{"done": true}`,
+	})
+	t.Setenv("UNFOLD_AUDIT_LOG", filepath.Join(t.TempDir(), "audit.jsonl"))
+	audit.Begin("apply", []string{"plan.json"})
+	t.Cleanup(func() { audit.Begin("", nil) })
+
+	op := plan.Operation{Kind: plan.GoogleMembership, Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", MembershipName: "groups/test-group/memberships/123"}
+	if err := ApplyPlannedMembership(op); err != nil {
+		t.Fatalf("ApplyPlannedMembership() error = %v", err)
+	}
+
+	entries, err := audit.List(audit.Filter{Target: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("audit.List() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != "google membership remove" || entries[0].Result != audit.ResultSucceeded || !reflect.DeepEqual(entries[0].Operation, &op) {
+		t.Errorf("ApplyPlannedMembership() recorded %+v", entries)
+	}
+}
+
+func TestPlanUndo(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      plan.Operation
+		want    *plan.Operation
+		wantErr string
+	}{
+		{
+			name: "undo an add",
+			op:   plan.Operation{Kind: plan.GoogleMembership, Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", Role: ManagerRole},
+			want: &plan.Operation{Kind: plan.GoogleMembership, Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", Role: ManagerRole, MembershipName: "groups/test-group/memberships/123"},
+		},
+		{
+			name: "undo a remove",
+			op:   plan.Operation{Kind: plan.GoogleMembership, Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "bob@example.com", Role: OwnerRole, MembershipName: "groups/test-group/memberships/456"},
+			want: &plan.Operation{Kind: plan.GoogleMembership, Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "bob@example.com", Role: OwnerRole},
+		},
+		{
+			name:    "role changed since the add",
+			op:      plan.Operation{Kind: plan.GoogleMembership, Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", Role: MemberRole},
+			wantErr: "the role of alice@example.com in the group test-group changed from MEMBER to MANAGER",
+		},
+		{
+			name:    "member removed since the add",
+			op:      plan.Operation{Kind: plan.GoogleMembership, Mode: plan.AddMode, Group: "test-group", GroupName: "groups/test-group", Member: "bob@example.com", Role: MemberRole},
+			wantErr: "bob@example.com is no longer a member of the group test-group",
+		},
+		{
+			name:    "member added since the remove",
+			op:      plan.Operation{Kind: plan.GoogleMembership, Mode: plan.RemoveMode, Group: "test-group", GroupName: "groups/test-group", Member: "alice@example.com", Role: ManagerRole, MembershipName: "groups/test-group/memberships/123"},
+			wantErr: "alice@example.com is a member of the group test-group again",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useStubTransport(t, stubTransport{
+				"GET /v1/groups/test-group/memberships": stubMemberships,
+			})
+			got, err := PlanUndo(tt.op)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("PlanUndo() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PlanUndo() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanUndo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
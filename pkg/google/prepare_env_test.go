@@ -6,8 +6,11 @@ import (
 )
 
 func prepareTestEnvironment() {
+	os.Setenv("UNFOLD_NO_CACHE", "true")
+	os.Setenv("GOOGLE_GCP_DOMAIN", "@example.com")
 	os.Setenv("GOOGLE_KEYFILE", "testdata/google-keyfile.json")
 	Config.ServiceAccountKeyFile = os.Getenv("GOOGLE_KEYFILE")
+	Config.clientOpts = nil
 	err := StartService()
 	if err != nil {
 		log.Fatalf("failed to start service: %v", err)
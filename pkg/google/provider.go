@@ -0,0 +1,93 @@
+package google
+
+import (
+	"github.com/aryannr97/unfold/pkg/directory"
+	"github.com/aryannr97/unfold/pkg/plan"
+	ci "google.golang.org/api/cloudidentity/v1"
+)
+
+const (
+	// MemberRole is the role of every member of a group
+	MemberRole = "MEMBER"
+	// ManagerRole is the role of the members managing the memberships of a group
+	ManagerRole = "MANAGER"
+	// OwnerRole is the role of the members owning a group
+	OwnerRole = "OWNER"
+)
+
+// groupProvider implements directory.GroupProvider on top of the cloud identity groups
+type groupProvider struct{}
+
+// NewGroupProvider returns the Google Workspace group provider, usable once the service is started
+func NewGroupProvider() directory.GroupProvider {
+	return groupProvider{}
+}
+
+// LookupGroup returns the group identified by its email, short name or resource name
+func (groupProvider) LookupGroup(groupID string) (*directory.Group, error) {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &directory.Group{ID: groupID, Name: g.Name}, nil
+}
+
+// ListMembers returns the members of the group
+func (groupProvider) ListMembers(groupID string) ([]directory.Member, error) {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	memberships, err := listMemberships(g.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]directory.Member, 0, len(memberships))
+	for _, m := range memberships {
+		members = append(members, toMember(m))
+	}
+	return members, nil
+}
+
+// CheckMember returns the membership of the member in the group
+func (groupProvider) CheckMember(groupID, memberID string) (*directory.Member, error) {
+	m, err := CheckGroupMembershipForEmailIDs(groupID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	member := toMember(m)
+	return &member, nil
+}
+
+// AddMember adds the member to the group with the role
+func (groupProvider) AddMember(groupID, memberID, role string) error {
+	return addMemberWithRole(groupID, memberID, role)
+}
+
+// RemoveMember removes the member from the group
+func (groupProvider) RemoveMember(groupID, memberID string) error {
+	return RemoveMemberFromGroupID(groupID, memberID)
+}
+
+// Roles returns the cloud identity membership roles
+func (groupProvider) Roles() []string {
+	return []string{MemberRole, ManagerRole, OwnerRole}
+}
+
+// toMember converts the cloud identity membership
+func toMember(m *ci.Membership) directory.Member {
+	member := directory.Member{Name: m.Name, Roles: []string{}}
+	if m.PreferredMemberKey != nil {
+		member.ID = m.PreferredMemberKey.Id
+	}
+	for _, role := range m.Roles {
+		member.Roles = append(member.Roles, role.Name)
+	}
+	return member
+}
+
+// PlanMember resolves the group into the operation adding or removing the member, for directory.Planner
+func (groupProvider) PlanMember(groupID, memberID, role string, remove bool) (*plan.Operation, error) {
+	return PlanMembership(groupID, memberID, role, remove)
+}
@@ -0,0 +1,77 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/cloudidentity/v1"
+	"google.golang.org/api/option"
+)
+
+// recordingRoundTripper records the bodies of the requests before answering them with the mock transport
+type recordingRoundTripper struct {
+	MockHTTPRoundTripper
+	bodies []string
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		r.bodies = append(r.bodies, string(b))
+	}
+	return r.MockHTTPRoundTripper.RoundTrip(req)
+}
+
+func Test_groupProvider_AddMember(t *testing.T) {
+	prepareTestEnvironment()
+	tests := []struct {
+		name string
+		role string
+		want string
+	}{
+		{
+			name: "add member",
+			role: MemberRole,
+			want: `"roles":[{"name":"MEMBER"}]`,
+		},
+		{
+			name: "add manager as member and manager",
+			role: ManagerRole,
+			want: `"roles":[{"name":"MEMBER"},{"name":"MANAGER"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingRoundTripper{MockHTTPRoundTripper: MockHTTPRoundTripper{Transport: map[string]*http.Response{
+				"/v1/groups:lookup": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "groups/test-group"}`)),
+				},
+				"/v1/groups/test-group/memberships": {
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewBufferString(`{"name": "success"}`)),
+				},
+			}}}
+			instance.CloudIdentityService, _ = cloudidentity.NewService(context.Background(),
+				option.WithHTTPClient(&http.Client{Transport: transport}))
+			instance.Groups = make(map[string]*cloudidentity.LookupGroupNameResponse)
+
+			if err := NewGroupProvider().AddMember("test-group", "alice@example.com", tt.role); err != nil {
+				t.Fatalf("AddMember() error = %v", err)
+			}
+			if got := strings.Join(transport.bodies, "\n"); !strings.Contains(got, tt.want) {
+				t.Errorf("AddMember() request = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_groupProvider_Roles(t *testing.T) {
+	if got := NewGroupProvider().Roles(); len(got) != 3 || got[0] != MemberRole {
+		t.Errorf("Roles() = %v, want MEMBER as default role", got)
+	}
+}
@@ -0,0 +1,16 @@
+package helpers
+
+import (
+	"flag"
+)
+
+// ParseAction returns the first positional argument left on the flag set as the action
+// and parses the remaining arguments, so that flags following the action are honoured,
+// e.g. unfold google group create -g <group>.
+func ParseAction(flagSet *flag.FlagSet) (string, error) {
+	if flagSet.NArg() == 0 {
+		return "", nil
+	}
+	args := flagSet.Args()
+	return args[0], flagSet.Parse(args[1:])
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantAction string
+		wantFlag   string
+		wantErr    bool
+	}{
+		{
+			name:       "action followed by flags",
+			args:       []string{"create", "-g", "test-group"},
+			wantAction: "create",
+			wantFlag:   "test-group",
+		},
+		{
+			name:       "flags followed by action",
+			args:       []string{"-g", "test-group", "delete"},
+			wantAction: "delete",
+			wantFlag:   "test-group",
+		},
+		{
+			name: "no action",
+			args: []string{"-g", "test-group"},
+		},
+		{
+			name:       "invalid flag after action",
+			args:       []string{"create", "-x"},
+			wantAction: "create",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+			group := flagSet.String("g", "", "group")
+			flagSet.Parse(tt.args) //nolint:errcheck
+
+			action, err := ParseAction(flagSet)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if action != tt.wantAction {
+				t.Errorf("ParseAction() = %v, want %v", action, tt.wantAction)
+			}
+			if !tt.wantErr && tt.wantFlag != "" && *group != tt.wantFlag {
+				t.Errorf("ParseAction() flag = %v, want %v", *group, tt.wantFlag)
+			}
+		})
+	}
+}
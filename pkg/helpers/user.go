@@ -0,0 +1,17 @@
+package helpers
+
+import (
+	"os"
+	"os/user"
+)
+
+// CurrentUser returns the name of the OS user running unfold
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+}
@@ -0,0 +1,9 @@
+package helpers
+
+import "testing"
+
+func TestCurrentUser(t *testing.T) {
+	if got := CurrentUser(); got == "" {
+		t.Errorf("CurrentUser() = %q, want the name of the OS user", got)
+	}
+}
@@ -0,0 +1,165 @@
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// version is the version of the plan file format
+	version = 1
+
+	// AzurePrivateAudience is the kind of the operations adding or removing an audience to the private audience of the plans of an offer
+	AzurePrivateAudience = "azure-private-audience"
+	// AzurePreviewAudience is the kind of the operations adding or removing an audience to the preview audience of an offer
+	AzurePreviewAudience = "azure-preview-audience"
+	// GoogleMembership is the kind of the operations adding or removing a member of a google group
+	GoogleMembership = "google-membership"
+
+	// AddMode adds the audience or member
+	AddMode = "add"
+	// RemoveMode removes the audience or member
+	RemoveMode = "remove"
+)
+
+// Operation represents a fully resolved change, executed as is when the plan is applied
+type Operation struct {
+	Kind string `json:"kind"`
+	Mode string `json:"mode"`
+
+	// Offer is the name of the offer in the azure configuration
+	Offer            string   `json:"offer,omitempty"`
+	ProductDurableID string   `json:"productDurableId,omitempty"`
+	PlanIDs          []string `json:"planIds,omitempty"`
+	// AudienceType is tenant or subscription
+	AudienceType string `json:"audienceType,omitempty"`
+	AudienceID   string `json:"audienceId,omitempty"`
+	// Label is the label of the audience added to the preview audience
+	Label string `json:"label,omitempty"`
+
+	// Group is the group as given on the command line, GroupName its resource name
+	Group     string `json:"group,omitempty"`
+	GroupName string `json:"groupName,omitempty"`
+	Member    string `json:"member,omitempty"`
+	Role      string `json:"role,omitempty"`
+	// MembershipName is the resource name of the membership removed by the operation
+	MembershipName string `json:"membershipName,omitempty"`
+
+	// Unchanged lists the plans already in the requested state when the operation was applied,
+	// the operation did not change them and undoing it leaves them as they are
+	Unchanged []string `json:"unchanged,omitempty"`
+}
+
+// String returns a one line description of the operation
+func (o Operation) String() string {
+	switch o.Kind {
+	case AzurePrivateAudience:
+		preposition := "to"
+		if o.Mode == RemoveMode {
+			preposition = "from"
+		}
+		return fmt.Sprintf("%s %s %s %s the private audience of %d plan(s) of %s (product/%s)", o.Mode, o.AudienceType, o.AudienceID, preposition, len(o.PlanIDs), o.Offer, o.ProductDurableID)
+	case AzurePreviewAudience:
+		if o.Mode == RemoveMode {
+			return fmt.Sprintf("remove %s %s from the preview audience of %s (product/%s)", o.AudienceType, o.AudienceID, o.Offer, o.ProductDurableID)
+		}
+		return fmt.Sprintf("add %s %s to the preview audience of %s (product/%s)", o.AudienceType, o.AudienceID, o.Offer, o.ProductDurableID)
+	case GoogleMembership:
+		if o.Mode == RemoveMode {
+			return fmt.Sprintf("remove %s from %s (%s)", o.Member, o.Group, o.MembershipName)
+		}
+		return fmt.Sprintf("add %s to %s (%s) as %s", o.Member, o.Group, o.GroupName, o.Role)
+	}
+	return fmt.Sprintf("%s %s", o.Mode, o.Kind)
+}
+
+// Plan represents the operations of a mutating command, to be approved and applied later
+type Plan struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"`
+	// Operations are executed in order when the plan is applied
+	Operations []Operation `json:"operations"`
+	// Hash is the sha256 of the plan without its hash. It only detects accidental changes as anyone
+	// editing the file can recompute it, the hash approved at review is required to apply the plan.
+	Hash string `json:"hash,omitempty"`
+}
+
+// New returns the plan of the operations
+func New(operations ...Operation) *Plan {
+	p := &Plan{
+		Version:    version,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		CreatedBy:  helpers.CurrentUser(),
+		Operations: operations,
+	}
+	p.Hash = p.computeHash()
+	return p
+}
+
+// computeHash returns the sha256 of the plan without its hash
+func (p Plan) computeHash() string {
+	p.Hash = ""
+	b, _ := json.Marshal(p)
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Write writes the plan to the file
+func (p *Plan) Write(path string) error {
+	b, err := json.MarshalIndent(p, "", " ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
+
+// Read reads the plan file and verifies its hash matches its content, see Verify for the approved hash
+func Read(path string) (*Plan, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Plan
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("invalid plan %s: %w", path, err)
+	}
+	if p.Version != version {
+		return nil, fmt.Errorf("unsupported plan version %d, expected %d", p.Version, version)
+	}
+	if len(p.Operations) == 0 {
+		return nil, errors.New("the plan has no operations")
+	}
+	if p.Hash != p.computeHash() {
+		return nil, fmt.Errorf("the hash of the plan does not match, %s was changed after it was written", path)
+	}
+	return &p, nil
+}
+
+// Verify checks the plan is the one approved at review, identified by its hash
+func (p *Plan) Verify(approvedHash string) error {
+	if approvedHash == "" {
+		return fmt.Errorf("provide the hash of the reviewed plan with --hash %s", p.Hash)
+	}
+	if !strings.EqualFold(p.Hash, approvedHash) {
+		return fmt.Errorf("the plan hash %s is not the approved hash %s, the plan changed since its review", p.Hash, approvedHash)
+	}
+	return nil
+}
+
+// Save writes the plan of the operations to the file and returns the summary for the output
+func Save(path string, operations ...Operation) (string, error) {
+	p := New(operations...)
+	if err := p.Write(path); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("plan with %d operation(s) written to %s, hash %s\nreview it and run unfold apply --hash %s %s to execute it", len(p.Operations), path, helpers.GreenValue(p.Hash), p.Hash, path), nil
+}
@@ -0,0 +1,125 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveRead(t *testing.T) {
+	op := Operation{Kind: AzurePrivateAudience, Mode: AddMode, Offer: "offer-a", ProductDurableID: "p1", PlanIDs: []string{"plan-1"}, AudienceType: "tenant", AudienceID: "t1"}
+	tests := []struct {
+		name    string
+		tamper  func(s string) string
+		wantErr string
+	}{
+		{
+			name: "read the saved plan",
+		},
+		{
+			name:    "plan changed after it was written",
+			tamper:  func(s string) string { return strings.Replace(s, `"t1"`, `"t2"`, 1) },
+			wantErr: "the hash of the plan does not match",
+		},
+		{
+			name:    "plan without operations",
+			tamper:  func(s string) string { return `{"version": 1, "operations": []}` },
+			wantErr: "the plan has no operations",
+		},
+		{
+			name:    "unsupported version",
+			tamper:  func(s string) string { return strings.Replace(s, `"version": 1`, `"version": 2`, 1) },
+			wantErr: "unsupported plan version 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plan.json")
+			summary, err := Save(path, op)
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if !strings.Contains(summary, "plan with 1 operation(s) written to "+path) {
+				t.Errorf("Save() = %v", summary)
+			}
+			if tt.tamper != nil {
+				b, _ := os.ReadFile(path)
+				os.WriteFile(path, []byte(tt.tamper(string(b))), 0o600) //nolint:errcheck
+			}
+
+			p, err := Read(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Read() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(p.Operations) != 1 || p.Operations[0].AudienceID != "t1" || !strings.HasPrefix(p.Hash, "sha256:") {
+				t.Errorf("Read() = %+v", p)
+			}
+		})
+	}
+}
+
+func TestOperation_String(t *testing.T) {
+	tests := []struct {
+		op   Operation
+		want string
+	}{
+		{
+			op:   Operation{Kind: AzurePrivateAudience, Mode: RemoveMode, Offer: "offer-a", ProductDurableID: "p1", PlanIDs: []string{"plan-1", "plan-2"}, AudienceType: "subscription", AudienceID: "s1"},
+			want: "remove subscription s1 from the private audience of 2 plan(s) of offer-a (product/p1)",
+		},
+		{
+			op:   Operation{Kind: AzurePreviewAudience, Mode: AddMode, Offer: "offer-a", ProductDurableID: "p1", AudienceType: "tenant", AudienceID: "t1", Label: "qa"},
+			want: "add tenant t1 to the preview audience of offer-a (product/p1)",
+		},
+		{
+			op:   Operation{Kind: GoogleMembership, Mode: AddMode, Group: "team", GroupName: "groups/123", Member: "bob@example.com", Role: "MEMBER"},
+			want: "add bob@example.com to team (groups/123) as MEMBER",
+		},
+		{
+			op:   Operation{Kind: GoogleMembership, Mode: RemoveMode, Group: "team", Member: "bob@example.com", MembershipName: "groups/123/memberships/1"},
+			want: "remove bob@example.com from team (groups/123/memberships/1)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.op.String(); got != tt.want {
+				t.Errorf("Operation.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan_Verify(t *testing.T) {
+	p := New(Operation{Kind: GoogleMembership, Mode: AddMode, Group: "team", GroupName: "groups/123", Member: "bob@example.com", Role: "MEMBER"})
+	tests := []struct {
+		name     string
+		approved string
+		wantErr  string
+	}{
+		{name: "approved hash", approved: p.Hash},
+		{name: "approved hash in upper case", approved: strings.ToUpper(p.Hash)},
+		{name: "missing hash", wantErr: "provide the hash of the reviewed plan with --hash " + p.Hash},
+		{name: "another hash", approved: "sha256:0123", wantErr: "is not the approved hash sha256:0123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Verify(tt.approved)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Plan.Verify() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Plan.Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,10 +3,17 @@ package registry
 import (
 	"flag"
 
+	"github.com/aryannr97/unfold/pkg/apply"
+	"github.com/aryannr97/unfold/pkg/audit"
 	"github.com/aryannr97/unfold/pkg/azure"
+	"github.com/aryannr97/unfold/pkg/cache"
 	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/github"
 	"github.com/aryannr97/unfold/pkg/google"
 	"github.com/aryannr97/unfold/pkg/jwt"
+	"github.com/aryannr97/unfold/pkg/scim"
+	"github.com/aryannr97/unfold/pkg/undo"
+	"github.com/aryannr97/unfold/pkg/workflow"
 )
 
 // Operation represents the operation to be executed
@@ -25,11 +32,48 @@ func New() Registry {
 			commands.Get:       azure.NewCommandModule().CommandGetConfig,
 			commands.Search:    azure.NewCommandModule().CommandSearchConfig,
 			commands.Configure: azure.NewCommandModule().CommandConfigureConfig,
+			commands.Preview:   azure.NewCommandModule().CommandPreviewConfig,
+			commands.Offer:     azure.NewCommandModule().CommandOfferConfig,
+			commands.Jobs:      azure.NewCommandModule().CommandJobsConfig,
+			commands.SaaS:      azure.NewCommandModule().CommandSaaSConfig,
+			commands.Metering:  azure.NewCommandModule().CommandMeteringConfig,
+			commands.Group:     azure.NewCommandModule().CommandGroupConfig,
 		},
 		commands.Google: {
 			commands.Get:       google.NewCommandModule().CommandGetConfig,
 			commands.Search:    google.NewCommandModule().CommandSearchConfig,
 			commands.Configure: google.NewCommandModule().CommandConfigureConfig,
+			commands.Group:     google.NewCommandModule().CommandGroupConfig,
+			commands.Export:    google.NewCommandModule().CommandExportConfig,
+		},
+		commands.SCIM: {
+			commands.Get:       scim.NewCommandModule().CommandGetConfig,
+			commands.Search:    scim.NewCommandModule().CommandSearchConfig,
+			commands.Configure: scim.NewCommandModule().CommandConfigureConfig,
+			commands.Export:    scim.NewCommandModule().CommandExportConfig,
+		},
+		commands.GitHub: {
+			commands.Get:       github.NewCommandModule().CommandGetConfig,
+			commands.Search:    github.NewCommandModule().CommandSearchConfig,
+			commands.Configure: github.NewCommandModule().CommandConfigureConfig,
+			commands.Export:    github.NewCommandModule().CommandExportConfig,
+		},
+		commands.Workflow: {
+			commands.Run:     workflow.NewCommandModule().CommandRunConfig,
+			commands.History: workflow.NewCommandModule().CommandHistoryConfig,
+		},
+		commands.Apply: {
+			commands.Value: apply.NewCommandModule().CommandApplyConfig,
+		},
+		commands.Audit: {
+			commands.List: audit.NewCommandModule().CommandListConfig,
+		},
+		commands.Undo: {
+			commands.Value: undo.NewCommandModule().CommandUndoConfig,
+		},
+		commands.Cache: {
+			commands.Show:  cache.NewCommandModule().CommandShowConfig,
+			commands.Clear: cache.NewCommandModule().CommandClearConfig,
 		},
 		commands.JWT: {
 			commands.Decode: jwt.NewCommandModule().CommandDecodeConfig,
@@ -0,0 +1,50 @@
+package scim
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandGetConfig represents the configuration for the get command
+type commandGetConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		GroupFlag *string
+	}
+}
+
+// Execute executes the get command
+func (c commandGetConfig) Execute() string {
+	if *c.Opts.GroupFlag != "" {
+		res, err := GetGroupByID(*c.Opts.GroupFlag)
+		if err != nil {
+			return fmt.Sprintf("[unfold] %s", err.Error())
+		}
+		return fmt.Sprintf("[unfold] retrieved group id %v with %d member(s)", helpers.GreenValue(res.ID), len(res.Members))
+	}
+	return "[unfold] something went wrong"
+}
+
+// GetFlagSet returns the flag set for the get command
+func (c commandGetConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandGetConfig fetches the command get config
+func fetchCommandGetConfig() commandGetConfig {
+	flagSet := flag.NewFlagSet(commands.Get, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	bindAuthFlags(flagSet)
+	return commandGetConfig{
+		Opts: struct {
+			GroupFlag *string
+		}{
+			GroupFlag: flagSet.String("g", "", "used to fetch group details for given group display name or id"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,153 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	stubGroupID = "g-1"
+	aliceID     = "u-alice"
+	bobID       = "u-bob"
+)
+
+// stubSCIMServer serves the /Groups and /Users resources of a group with alice as member.
+// PATCH requests update the members of the group, the requests are recorded in the returned slice.
+func stubSCIMServer(t *testing.T) (*httptest.Server, *[]patchRequest) {
+	var mu sync.Mutex
+	members := []GroupMember{{Value: aliceID, Display: "alice@example.com"}}
+	users := map[string]string{"alice@example.com": aliceID, "bob@example.com": bobID}
+	patches := []patchRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"], "detail": "invalid token", "status": "401"}`)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		path := strings.TrimPrefix(r.URL.Path, "/scim/v2")
+		filter := r.URL.Query().Get("filter")
+		switch {
+		case r.Method == http.MethodGet && path == "/Groups":
+			switch filter {
+			case `displayName eq "Engineering"`:
+				fmt.Fprintf(w, `{"totalResults": 1, "Resources": [{"id": "%s", "displayName": "Engineering"}]}`, stubGroupID)
+			case `displayName eq "Duplicates"`:
+				fmt.Fprint(w, `{"totalResults": 2, "Resources": [{"id": "a"}, {"id": "b"}]}`)
+			default:
+				fmt.Fprint(w, `{"totalResults": 0, "Resources": []}`)
+			}
+		case r.Method == http.MethodGet && path == "/Groups/"+stubGroupID:
+			json.NewEncoder(w).Encode(Group{ID: stubGroupID, DisplayName: "Engineering", Members: members}) //nolint:errcheck
+		case r.Method == http.MethodPatch && path == "/Groups/"+stubGroupID:
+			var req patchRequest
+			json.NewDecoder(r.Body).Decode(&req) //nolint:errcheck
+			patches = append(patches, req)
+			for _, op := range req.Operations {
+				switch op.Op {
+				case "add":
+					members = append(members, op.Value...)
+				case "remove":
+					kept := []GroupMember{}
+					for _, m := range members {
+						if op.Path != fmt.Sprintf("members[value eq %q]", m.Value) {
+							kept = append(kept, m)
+						}
+					}
+					members = kept
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && path == "/Users":
+			for userName, id := range users {
+				if filter == fmt.Sprintf("userName eq %q", userName) {
+					fmt.Fprintf(w, `{"totalResults": 1, "Resources": [{"id": "%s", "userName": "%s"}]}`, id, userName)
+					return
+				}
+			}
+			fmt.Fprint(w, `{"totalResults": 0, "Resources": []}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"], "status": "404"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &patches
+}
+
+func useStubSCIMServer(t *testing.T, server *httptest.Server) {
+	baseURL, httpClient := instance.BaseURL, instance.httpClient
+	t.Cleanup(func() {
+		instance.BaseURL, instance.httpClient = baseURL, httpClient
+	})
+	instance.BaseURL, instance.httpClient = server.URL+"/scim/v2", server.Client()
+}
+
+func Test_commandGetConfig_Execute(t *testing.T) {
+	prepareTestEnvironment()
+	server, _ := stubSCIMServer(t)
+	useStubSCIMServer(t, server)
+
+	tests := []struct {
+		name  string
+		args  []string
+		token string
+		want  string
+	}{
+		{
+			name: "get group by display name",
+			args: []string{"-g", "Engineering"},
+			want: "retrieved group id " + helpers.GreenValue(stubGroupID) + " with 1 member(s)",
+		},
+		{
+			name: "get group by id",
+			args: []string{"-g", stubGroupID},
+			want: "retrieved group id " + helpers.GreenValue(stubGroupID),
+		},
+		{
+			name: "get unknown group",
+			args: []string{"-g", "Marketing"},
+			want: "scim group not found: Marketing",
+		},
+		{
+			name: "get ambiguous group name",
+			args: []string{"-g", "Duplicates"},
+			want: "2 groups are named Duplicates, use the group id a|b",
+		},
+		{
+			name:  "get group with invalid token",
+			args:  []string{"-g", "Engineering"},
+			token: "invalid",
+			want:  "scim returned 401 for GET /scim/v2/Groups",
+		},
+		{
+			name: "get group flag not provided",
+			args: []string{},
+			want: "something went wrong",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandGetConfig
+			c.GetFlagSet().Parse(tt.args)
+			if tt.token != "" {
+				token := instance.token
+				instance.token = tt.token
+				defer func() { instance.token = token }()
+			}
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandGetConfig.Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package scim
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// SCIMConfig holds the endpoint and credentials of the SCIM 2.0 service provider
+type SCIMConfig struct {
+	// BaseURL is the SCIM base URL under which /Groups and /Users are served, e.g. https://example.com/scim/v2
+	BaseURL string `json:"baseURL" yaml:"baseURL"`
+	// Token is the bearer token sent with every request
+	Token string `json:"token" yaml:"token"`
+}
+
+// Config contains the SCIM configuration as read from the environment
+var Config = SCIMConfig{
+	BaseURL: os.Getenv("SCIM_BASE_URL"),
+	Token:   os.Getenv("SCIM_TOKEN"),
+}
+
+// bindAuthFlags registers the endpoint flag on the given flag set, the token is only read from
+// SCIM_TOKEN so that it does not show up in the shell history or the process list
+func bindAuthFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&Config.BaseURL, "base-url", os.Getenv("SCIM_BASE_URL"), "SCIM base URL serving /Groups and /Users")
+}
+
+// Service holds the http client used to call the SCIM service provider
+type Service struct {
+	BaseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// instance holds the Service as constructed from the SCIMConfig
+var instance *Service
+
+// NewService creates a new Service from the SCIMConfig
+func (c SCIMConfig) NewService() (*Service, error) {
+	if c.BaseURL == "" {
+		return nil, errors.New("SCIM_BASE_URL is not set")
+	}
+	if c.Token == "" {
+		return nil, errors.New("SCIM_TOKEN is not set")
+	}
+	return &Service{
+		BaseURL:    strings.TrimSuffix(c.BaseURL, "/"),
+		token:      c.Token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// StartService starts the SCIM service
+func StartService() error {
+	var err error
+	instance, err = Config.NewService()
+	return err
+}
@@ -0,0 +1,59 @@
+package scim
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestSCIMConfig_NewService(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      SCIMConfig
+		wantBaseURL string
+		wantErr     string
+	}{
+		{
+			name:        "trailing slash is trimmed",
+			config:      SCIMConfig{BaseURL: "https://example.com/scim/v2/", Token: "token"},
+			wantBaseURL: "https://example.com/scim/v2",
+		},
+		{
+			name:    "base url not set",
+			config:  SCIMConfig{Token: "token"},
+			wantErr: "SCIM_BASE_URL is not set",
+		},
+		{
+			name:    "token not set",
+			config:  SCIMConfig{BaseURL: "https://example.com/scim/v2"},
+			wantErr: "SCIM_TOKEN is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.config.NewService()
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("SCIMConfig.NewService() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SCIMConfig.NewService() error = %v", err)
+			}
+			if got.BaseURL != tt.wantBaseURL || got.token != tt.config.Token {
+				t.Errorf("SCIMConfig.NewService() = %+v, want base url %v", got, tt.wantBaseURL)
+			}
+		})
+	}
+}
+
+func Test_bindAuthFlags(t *testing.T) {
+	flagSet := flag.NewFlagSet("scim", flag.ContinueOnError)
+	bindAuthFlags(flagSet)
+	if flagSet.Lookup("token") != nil {
+		t.Error("bindAuthFlags() registered -token, want the token read from SCIM_TOKEN only")
+	}
+	if flagSet.Lookup("base-url") == nil {
+		t.Error("bindAuthFlags() did not register -base-url")
+	}
+}
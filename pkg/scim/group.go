@@ -0,0 +1,263 @@
+package scim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/directory"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+const (
+	// contentType is the media type of SCIM requests and responses
+	contentType = "application/scim+json"
+	// patchOpSchema is the schema of the PATCH request messages
+	patchOpSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+
+	// groupCacheTTL is the duration for which the id of a group name is cached on disk
+	groupCacheTTL = time.Hour
+)
+
+var (
+	// ErrGroupNotFound is returned when no group matches the display name or id
+	ErrGroupNotFound = errors.New("scim group not found")
+	// errSCIMNotFound is returned by scimRequest when the resource does not exist
+	errSCIMNotFound = errors.New("scim resource not found")
+)
+
+// Group represents a SCIM group resource
+type Group struct {
+	ID          string        `json:"id"`
+	DisplayName string        `json:"displayName"`
+	Members     []GroupMember `json:"members,omitempty"`
+}
+
+// GroupMember represents a member of a SCIM group
+type GroupMember struct {
+	// Value is the id of the member resource
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// User represents a SCIM user resource
+type User struct {
+	ID       string `json:"id"`
+	UserName string `json:"userName"`
+}
+
+// listResponse represents the resources returned by a filtered SCIM query
+type listResponse[T any] struct {
+	TotalResults int `json:"totalResults"`
+	Resources    []T `json:"Resources"`
+}
+
+// patchOperation represents a single operation of a SCIM PATCH request
+type patchOperation struct {
+	Op    string        `json:"op"`
+	Path  string        `json:"path"`
+	Value []GroupMember `json:"value,omitempty"`
+}
+
+// patchRequest represents a SCIM PATCH request
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+// scimRequest calls the SCIM service provider and decodes the response into out when given
+func scimRequest(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, instance.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+instance.token)
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := instance.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errSCIMNotFound
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		b, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("scim returned %v for %s %s with response %v", resp.StatusCode, method, req.URL.Path, helpers.GetErrorResponseBody(b))
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("json decode %s", err.Error())
+	}
+	return nil
+}
+
+// filterValue returns the value quoted as a SCIM filter string
+func filterValue(value string) string {
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+// GetGroupByID returns the group identified by its display name or id
+func GetGroupByID(group string) (*Group, error) {
+	if group == "" {
+		return nil, errors.New("group cannot be empty")
+	}
+
+	id, err := resolveGroupID(group)
+	if err != nil {
+		return nil, err
+	}
+
+	var res Group
+	if err := scimRequest(http.MethodGet, "/Groups/"+url.PathEscape(id), nil, &res); err != nil {
+		if errors.Is(err, errSCIMNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, group)
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+// resolveGroupID returns the id of the group with the display name, from the on-disk cache if still valid.
+// SCIM ids are opaque, so the group itself is returned as id when no group has the display name.
+func resolveGroupID(name string) (string, error) {
+	key := groupCacheKey(name)
+	var id string
+	if cache.Get(key, &id) {
+		return id, nil
+	}
+
+	query := url.Values{}
+	query.Set("filter", "displayName eq "+filterValue(name))
+	query.Set("attributes", "id,displayName")
+
+	var res listResponse[Group]
+	if err := scimRequest(http.MethodGet, "/Groups?"+query.Encode(), nil, &res); err != nil {
+		return "", err
+	}
+
+	switch len(res.Resources) {
+	case 0:
+		return name, nil
+	case 1:
+		cache.Set(key, res.Resources[0].ID, groupCacheTTL)
+		return res.Resources[0].ID, nil
+	}
+	ids := make([]string, 0, len(res.Resources))
+	for _, g := range res.Resources {
+		ids = append(ids, g.ID)
+	}
+	return "", fmt.Errorf("%d groups are named %s, use the group id %s", len(ids), name, strings.Join(ids, "|"))
+}
+
+// groupCacheKey returns the on-disk cache key for the id of the group name
+func groupCacheKey(name string) string {
+	return "scim/group/" + strings.ToLower(name)
+}
+
+// resolveUserID returns the id of the user with the user name
+func resolveUserID(userName string) (string, error) {
+	if userName == "" {
+		return "", errors.New("user cannot be empty")
+	}
+
+	query := url.Values{}
+	query.Set("filter", "userName eq "+filterValue(userName))
+	query.Set("attributes", "id,userName")
+
+	var res listResponse[User]
+	if err := scimRequest(http.MethodGet, "/Users?"+query.Encode(), nil, &res); err != nil {
+		return "", err
+	}
+	if len(res.Resources) == 0 {
+		return "", fmt.Errorf("user %s not found", userName)
+	}
+	return res.Resources[0].ID, nil
+}
+
+// CheckGroupMembership returns the member of the group with the user name, directory.ErrMemberNotFound when not a member
+func CheckGroupMembership(group, userName string) (*GroupMember, error) {
+	g, err := GetGroupByID(group)
+	if err != nil {
+		return nil, err
+	}
+	return findMember(g, userName)
+}
+
+// findMember returns the member of the group with the user name, directory.ErrMemberNotFound when not a member
+func findMember(g *Group, userName string) (*GroupMember, error) {
+	userID, err := resolveUserID(userName)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range g.Members {
+		if m.Value == userID {
+			return &m, nil
+		}
+	}
+	return nil, directory.ErrMemberNotFound
+}
+
+// AddMemberToGroup adds the user with the user name to the group
+func AddMemberToGroup(group, userName string) error {
+	g, err := GetGroupByID(group)
+	if err != nil {
+		return err
+	}
+	userID, err := resolveUserID(userName)
+	if err != nil {
+		return err
+	}
+	err = patchMembers(g.ID, patchOperation{Op: "add", Path: "members", Value: []GroupMember{{Value: userID}}})
+	audit.Record(audit.Entry{Action: "scim membership add", Targets: []string{group, g.ID, userName, userID}}, err)
+	return err
+}
+
+// RemoveMemberFromGroup removes the user with the user name from the group
+func RemoveMemberFromGroup(group, userName string) error {
+	g, err := GetGroupByID(group)
+	if err != nil {
+		return err
+	}
+	m, err := findMember(g, userName)
+	if err != nil {
+		return err
+	}
+	err = patchMembers(g.ID, patchOperation{Op: "remove", Path: fmt.Sprintf("members[value eq %s]", filterValue(m.Value))})
+	audit.Record(audit.Entry{Action: "scim membership remove", Targets: []string{group, g.ID, userName, m.Value}}, err)
+	return err
+}
+
+// patchMembers applies the operation on the members of the group
+func patchMembers(groupID string, op patchOperation) error {
+	body := patchRequest{Schemas: []string{patchOpSchema}, Operations: []patchOperation{op}}
+	return scimRequest(http.MethodPatch, "/Groups/"+url.PathEscape(groupID), body, nil)
+}
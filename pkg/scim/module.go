@@ -0,0 +1,25 @@
+package scim
+
+import (
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/directory"
+)
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandGetConfig       commandGetConfig
+	CommandConfigureConfig directory.CommandConfigureConfig
+	CommandSearchConfig    directory.CommandSearchConfig
+	CommandExportConfig    directory.CommandExportConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	provider := NewGroupProvider()
+	return &CommandModule{
+		CommandGetConfig:       fetchCommandGetConfig(),
+		CommandConfigureConfig: directory.NewCommandConfigureConfig(provider, cache.BindFlags, bindAuthFlags),
+		CommandSearchConfig:    directory.NewCommandSearchConfig(provider, cache.BindFlags, bindAuthFlags),
+		CommandExportConfig:    directory.NewCommandExportConfig(provider, cache.BindFlags, bindAuthFlags),
+	}
+}
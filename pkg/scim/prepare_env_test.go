@@ -0,0 +1,15 @@
+package scim
+
+import (
+	"log"
+	"os"
+)
+
+func prepareTestEnvironment() {
+	os.Setenv("UNFOLD_NO_CACHE", "true")
+	Config = SCIMConfig{BaseURL: "http://localhost/scim/v2/", Token: "test-token"}
+	err := StartService()
+	if err != nil {
+		log.Fatalf("failed to start service: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package scim
+
+import (
+	"github.com/aryannr97/unfold/pkg/directory"
+)
+
+// MemberRole is the only role of the members of a SCIM group
+const MemberRole = "member"
+
+// groupProvider implements directory.GroupProvider on top of the SCIM /Groups and /Users resources
+type groupProvider struct{}
+
+// NewGroupProvider returns the SCIM group provider, usable once the service is started
+func NewGroupProvider() directory.GroupProvider {
+	return groupProvider{}
+}
+
+// LookupGroup returns the group identified by its display name or id
+func (groupProvider) LookupGroup(groupID string) (*directory.Group, error) {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &directory.Group{ID: groupID, Name: g.ID, DisplayName: g.DisplayName}, nil
+}
+
+// ListMembers returns the members of the group, identified by their display value when the service provider sets it
+func (groupProvider) ListMembers(groupID string) ([]directory.Member, error) {
+	g, err := GetGroupByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]directory.Member, 0, len(g.Members))
+	for _, m := range g.Members {
+		id := m.Display
+		if id == "" {
+			id = m.Value
+		}
+		members = append(members, directory.Member{ID: id, Name: m.Value, Roles: []string{MemberRole}})
+	}
+	return members, nil
+}
+
+// CheckMember returns the membership of the user name in the group
+func (groupProvider) CheckMember(groupID, memberID string) (*directory.Member, error) {
+	m, err := CheckGroupMembership(groupID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	return &directory.Member{ID: memberID, Name: m.Value, Roles: []string{MemberRole}}, nil
+}
+
+// AddMember adds the user name to the group, SCIM groups only know plain members
+func (groupProvider) AddMember(groupID, memberID, _ string) error {
+	return AddMemberToGroup(groupID, memberID)
+}
+
+// RemoveMember removes the user name from the group
+func (groupProvider) RemoveMember(groupID, memberID string) error {
+	return RemoveMemberFromGroup(groupID, memberID)
+}
+
+// Roles returns the single SCIM membership role
+func (groupProvider) Roles() []string {
+	return []string{MemberRole}
+}
@@ -0,0 +1,111 @@
+package scim
+
+import (
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// operation is a command of the module as registered in the registry
+type operation interface {
+	Execute() string
+	GetFlagSet() *flag.FlagSet
+}
+
+func Test_groupProvider_commands(t *testing.T) {
+	prepareTestEnvironment()
+
+	tests := []struct {
+		name      string
+		command   func(*CommandModule) operation
+		args      []string
+		want      string
+		wantPatch string
+	}{
+		{
+			name:    "search member",
+			command: func(m *CommandModule) operation { return m.CommandSearchConfig },
+			args:    []string{"-g", "Engineering", "-id", "alice@example.com"},
+			want:    "emailID is found to be " + helpers.GreenValue(MemberRole) + " of the group with membership name " + helpers.GreenValue(aliceID),
+		},
+		{
+			name:    "search non member",
+			command: func(m *CommandModule) operation { return m.CommandSearchConfig },
+			args:    []string{"-g", "Engineering", "-id", "bob@example.com"},
+			want:    "member not found",
+		},
+		{
+			name:    "search unknown user",
+			command: func(m *CommandModule) operation { return m.CommandSearchConfig },
+			args:    []string{"-g", stubGroupID, "-id", "carol@example.com"},
+			want:    "user carol@example.com not found",
+		},
+		{
+			name:      "add member",
+			command:   func(m *CommandModule) operation { return m.CommandConfigureConfig },
+			args:      []string{"-g", "Engineering", "-id", "bob@example.com"},
+			want:      "successfully added the member to the given group",
+			wantPatch: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"add","path":"members","value":[{"value":"u-bob"}]}]}`,
+		},
+		{
+			name:    "add member with unsupported role",
+			command: func(m *CommandModule) operation { return m.CommandConfigureConfig },
+			args:    []string{"-g", "Engineering", "-id", "bob@example.com", "-role", "OWNER"},
+			want:    "invalid role OWNER, use member",
+		},
+		{
+			name:      "remove member",
+			command:   func(m *CommandModule) operation { return m.CommandConfigureConfig },
+			args:      []string{"-r", "-g", stubGroupID, "-id", "alice@example.com"},
+			want:      "successfully removed the member from the group",
+			wantPatch: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"remove","path":"members[value eq \"u-alice\"]"}]}`,
+		},
+		{
+			name:    "remove non member",
+			command: func(m *CommandModule) operation { return m.CommandConfigureConfig },
+			args:    []string{"-r", "-g", stubGroupID, "-id", "bob@example.com"},
+			want:    "unable to remove the member " + helpers.RedValue("member not found"),
+		},
+		{
+			name:    "export members",
+			command: func(m *CommandModule) operation { return m.CommandExportConfig },
+			args:    []string{"-g", "Engineering"},
+			want:    "member,roles,membership\nalice@example.com,member,u-alice",
+		},
+		{
+			name:    "export unknown group",
+			command: func(m *CommandModule) operation { return m.CommandExportConfig },
+			args:    []string{"-g", "Marketing"},
+			want:    "scim group not found: Marketing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, patches := stubSCIMServer(t)
+			useStubSCIMServer(t, server)
+
+			m := NewCommandModule()
+			c := tt.command(m)
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+			if tt.wantPatch == "" {
+				if len(*patches) != 0 {
+					t.Errorf("unexpected patch requests %+v", *patches)
+				}
+				return
+			}
+			if len(*patches) != 1 {
+				t.Fatalf("got %d patch requests, want 1", len(*patches))
+			}
+			b, _ := json.Marshal((*patches)[0])
+			if string(b) != tt.wantPatch {
+				t.Errorf("patch request = %s, want %s", b, tt.wantPatch)
+			}
+		})
+	}
+}
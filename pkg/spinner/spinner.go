@@ -2,8 +2,10 @@ package spinner
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 )
 
@@ -25,6 +27,15 @@ var (
 	classicFrames    = []string{"|", "/", "-", "\\"}
 	dualBallFrames   = []string{" ·", "● ", "●●", " ●", "· "}
 	circleFrames     = []string{"◐", "◓", "◑", "◒"}
+
+	// screen serializes the frames of the spinner with its pauses
+	screen struct {
+		sync.Mutex
+		// paused is set while the user is prompted
+		paused bool
+		// out is the writer showing a frame, nil when no frame is shown
+		out io.Writer
+	}
 )
 
 // Spinner represents the cli spinner
@@ -33,11 +44,11 @@ type Spinner struct {
 	stop     chan bool
 	done     chan bool
 	exitFunc func(int)
+	out      io.Writer
 }
 
 // Start starts the spinner
 func (s *Spinner) Start() {
-	fmt.Print("\033[?25l") // Hide cursor
 	s.run()
 }
 
@@ -67,6 +78,28 @@ func Get(spinnerType Type) *Spinner {
 		stop:     make(chan bool, 1),
 		done:     make(chan bool),
 		exitFunc: os.Exit,
+		out:      os.Stdout,
+	}
+}
+
+// Pause stops rendering the spinner and clears its frame so that the user can be prompted,
+// the returned function resumes the spinner once the user answered
+func Pause() (resume func()) {
+	screen.Lock()
+	defer screen.Unlock()
+
+	if screen.paused {
+		return func() {}
+	}
+	screen.paused = true
+	if screen.out != nil {
+		fmt.Fprint(screen.out, "\r \r\033[?25h") // Clear the frame and show the cursor
+		screen.out = nil
+	}
+	return func() {
+		screen.Lock()
+		defer screen.Unlock()
+		screen.paused = false
 	}
 }
 
@@ -77,21 +110,45 @@ func (s *Spinner) run() {
 	for {
 		select {
 		case <-osCue:
+			screen.Lock()
+			screen.out = nil
+			screen.Unlock()
 			ShowCursor()
 			s.exitFunc(0)
+			return
 		case <-s.stop:
-			fmt.Printf("\r \r")
+			screen.Lock()
+			if screen.out != nil {
+				fmt.Fprint(s.out, "\r \r")
+				screen.out = nil
+			}
+			screen.Unlock()
 			ShowCursor()
 			s.done <- true
 			return
 		default:
-			fmt.Printf("\r%s", s.frames[i%len(s.frames)])
+			s.render(s.frames[i%len(s.frames)])
 			i++
 			time.Sleep(100 * time.Millisecond)
 		}
 	}
 }
 
+// render shows the frame unless the spinner is paused, hiding the cursor on the first frame shown
+func (s *Spinner) render(frame string) {
+	screen.Lock()
+	defer screen.Unlock()
+
+	if screen.paused {
+		return
+	}
+	if screen.out == nil {
+		fmt.Fprint(s.out, "\033[?25l") // Hide cursor
+		screen.out = s.out
+	}
+	fmt.Fprintf(s.out, "\r%s", frame)
+}
+
 // ShowCursor shows the cursor
 func ShowCursor() {
 	fmt.Print("\033[?25h") // Show cursor
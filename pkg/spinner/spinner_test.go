@@ -1,13 +1,34 @@
 package spinner
 
 import (
+	"bytes"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
 )
 
+// terminal is the writer shared by the spinner and a prompt
+type terminal struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (t *terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.Write(p)
+}
+
+func (t *terminal) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.buf.String()
+}
+
 func TestGet(t *testing.T) {
 	type args struct {
 		spinnerType Type
@@ -105,3 +126,30 @@ func Test_Spinner(t *testing.T) {
 		})
 	}
 }
+
+func TestPause(t *testing.T) {
+	term := &terminal{}
+	s := Get(BrailDot)
+	s.out = term
+	go s.Start()
+	time.Sleep(250 * time.Millisecond)
+
+	resume := Pause()
+	if !strings.HasSuffix(term.String(), "\r \r\033[?25h") {
+		t.Errorf("Pause() did not clear the frame and show the cursor, terminal = %q", term.String())
+	}
+	before := len(term.String())
+	prompt := "proceed? [y/N] "
+	term.Write([]byte(prompt)) //nolint:errcheck
+	time.Sleep(350 * time.Millisecond)
+	if got := term.String()[before:]; got != prompt {
+		t.Errorf("spinner frames interleaved with the prompt, terminal = %q, want %q", got, prompt)
+	}
+
+	resume()
+	time.Sleep(250 * time.Millisecond)
+	s.Clear()
+	if got := term.String()[before+len(prompt):]; !strings.Contains(got, "\033[?25l\r") {
+		t.Errorf("spinner not resumed after the prompt, terminal = %q", got)
+	}
+}
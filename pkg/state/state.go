@@ -0,0 +1,149 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// mu guards the state files against concurrent access within the process,
+// the lock file of a state file guards it against the other processes
+var mu sync.Mutex
+
+// Dir returns the directory holding the state files.
+// UNFOLD_STATE_DIR takes precedence over the user config directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("UNFOLD_STATE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "unfold"), nil
+}
+
+// Path returns the path of the named state file
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Load decodes the named state file into v, a missing file leaves v untouched
+func Load(name string, v any) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return load(name, v)
+}
+
+// Update loads the named state file into v, applies fn and saves v when fn succeeds.
+// The state file is locked for the other unfold processes until it is saved.
+func Update(name string, v any, fn func() error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	unlock, err := lockFile(name)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := load(name, v); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return save(name, v)
+}
+
+// lockFile takes an exclusive advisory lock on the lock file next to the named state file,
+// waiting for the other processes to release it, and returns the function releasing the lock
+func lockFile(name string) (func(), error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close() //nolint:errcheck
+		return nil, fmt.Errorf("unable to lock the state file %s: %w", path, err)
+	}
+	return func() {
+		unlock(f) //nolint:errcheck
+		f.Close() //nolint:errcheck
+	}, nil
+}
+
+// load reads the named state file into v
+func load(name string, v any) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// save writes v atomically to the named state file
+func save(name string, v any) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(path, b)
+}
+
+// WriteFileAtomic writes b to a temporary file next to path and renames it over path,
+// readers see either the previous or the new content but never a partial write
+func WriteFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,162 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+type record struct {
+	Name string `json:"name"`
+}
+
+func TestLoadUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		fn       func(records *[]record) error
+		want     int
+		wantErr  bool
+	}{
+		{
+			name: "missing state file",
+			fn: func(records *[]record) error {
+				*records = append(*records, record{Name: "first"})
+				return nil
+			},
+			want: 1,
+		},
+		{
+			name:     "existing state file",
+			existing: `[{"name": "first"}]`,
+			fn: func(records *[]record) error {
+				*records = append(*records, record{Name: "second"})
+				return nil
+			},
+			want: 2,
+		},
+		{
+			name:     "update aborted",
+			existing: `[{"name": "first"}]`,
+			fn: func(records *[]record) error {
+				*records = append(*records, record{Name: "second"})
+				return errors.New("aborted")
+			},
+			want:    1,
+			wantErr: true,
+		},
+		{
+			name:     "corrupted state file",
+			existing: `[`,
+			fn:       func(*[]record) error { return nil },
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UNFOLD_STATE_DIR", t.TempDir())
+			if tt.existing != "" {
+				path, _ := Path("test")
+				os.WriteFile(path, []byte(tt.existing), 0o600) //nolint:errcheck
+			}
+
+			records := []record{}
+			if err := Update("test", &records, func() error { return tt.fn(&records) }); (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			loaded := []record{}
+			if err := Load("test", &loaded); err != nil && tt.want > 0 {
+				t.Errorf("Load() error = %v", err)
+			}
+			if len(loaded) != tt.want {
+				t.Errorf("Load() = %v, want %d records", loaded, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdate_concurrentProcesses(t *testing.T) {
+	const updates = 50
+	if os.Getenv("UNFOLD_STATE_TEST_PROCESS") != "" {
+		for i := range updates {
+			records := []record{}
+			err := Update("test", &records, func() error {
+				records = append(records, record{Name: fmt.Sprintf("%s-%d", os.Getenv("UNFOLD_STATE_TEST_PROCESS"), i)})
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+		}
+		return
+	}
+
+	dir := t.TempDir()
+	cmds := make([]*exec.Cmd, 0, 3)
+	for p := range 3 {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestUpdate_concurrentProcesses$")
+		cmd.Env = append(os.Environ(), "UNFOLD_STATE_DIR="+dir, fmt.Sprintf("UNFOLD_STATE_TEST_PROCESS=p%d", p))
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		cmds = append(cmds, cmd)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	t.Setenv("UNFOLD_STATE_DIR", dir)
+	loaded := []record{}
+	if err := Load("test", &loaded); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded) != 3*updates {
+		t.Errorf("Load() = %d records, want %d, concurrent updates were lost", len(loaded), 3*updates)
+	}
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("UNFOLD_STATE_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+	t.Setenv("HOME", "/tmp/home")
+	dir, err := Dir()
+	if err != nil {
+		t.Fatalf("Dir() error = %v", err)
+	}
+	if filepath.Base(dir) != "unfold" {
+		t.Errorf("Dir() = %v, want unfold directory", dir)
+	}
+
+	t.Setenv("UNFOLD_STATE_DIR", "/tmp/state")
+	if dir, _ := Dir(); dir != "/tmp/state" {
+		t.Errorf("Dir() = %v, want /tmp/state", dir)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.json")
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFileAtomic(path, []byte(content)); err != nil {
+			t.Fatalf("WriteFileAtomic() error = %v", err)
+		}
+		if b, _ := os.ReadFile(path); string(b) != content {
+			t.Errorf("WriteFileAtomic() wrote %q, want %q", b, content)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("WriteFileAtomic() left %d files in the directory, want 1", len(entries))
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "file.json"), nil); err == nil {
+		t.Error("WriteFileAtomic() error = nil, want error for a missing directory")
+	}
+}
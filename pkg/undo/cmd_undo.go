@@ -0,0 +1,90 @@
+package undo
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+	"github.com/aryannr97/unfold/pkg/spinner"
+)
+
+var (
+	// stdin is the reader the confirmation is read from
+	stdin io.Reader = os.Stdin
+	// stderr is the writer the confirmation is asked on
+	stderr io.Writer = os.Stderr
+)
+
+// commandUndoConfig represents the configuration for the undo command
+type commandUndoConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		Yes *bool
+	}
+}
+
+// Execute executes the undo command, reverting the access change of the audit entry once confirmed
+func (c commandUndoConfig) Execute() string {
+	id, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	e, err := Select(id)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	undo, err := Prepare(e)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if !c.confirm(e, undo) {
+		return "[unfold] undo cancelled, nothing was changed"
+	}
+
+	output, err := Undo(e, undo)
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to undo %s %s", e.ID, helpers.RedValue(err.Error()))
+	}
+	return fmt.Sprintf("[unfold] undid %s %s\n%s %s", e.ID, e.Action, undo, helpers.GreenValue(output))
+}
+
+// confirm asks whether the operation reverting the entry is executed, unless confirmed by the flags
+func (c commandUndoConfig) confirm(e *audit.Entry, undo *plan.Operation) bool {
+	if *c.Opts.Yes {
+		return true
+	}
+	// the spinner would overwrite the prompt and hide the answer
+	defer spinner.Pause()()
+	fmt.Fprintf(stderr, "\n[unfold] undo %s %s by %s at %s with\n %s\nproceed? [y/N] ",
+		e.ID, e.Action, e.User, e.Time.Local().Format(time.DateTime), undo)
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// GetFlagSet returns the flag set for the undo command
+func (c commandUndoConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandUndoConfig fetches the command undo config
+func fetchCommandUndoConfig() commandUndoConfig {
+	flagSet := flag.NewFlagSet(commands.Undo, flag.ContinueOnError)
+	return commandUndoConfig{
+		Opts: struct {
+			Yes *bool
+		}{
+			Yes: flagSet.Bool("y", false, "undo without asking for confirmation"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,228 @@
+package undo
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/apply"
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// membership returns the google membership operation of the member
+func membership(mode, member string) *plan.Operation {
+	return &plan.Operation{Kind: plan.GoogleMembership, Mode: mode, Group: "team", GroupName: "groups/team", Member: member, Role: "MEMBER"}
+}
+
+// useAuditLog writes the entries to a temporary audit log, the first entry the oldest
+func useAuditLog(t *testing.T, entries ...audit.Entry) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("UNFOLD_AUDIT_LOG", path)
+	t.Setenv("UNFOLD_AUDIT_SYSLOG", "")
+	t.Setenv("UNFOLD_AUDIT_HTTP_URL", "")
+	audit.Begin("undo", nil)
+	t.Cleanup(func() { audit.Begin("", nil) })
+
+	start := time.Now().UTC().Add(-time.Hour)
+	var b strings.Builder
+	for i, e := range entries {
+		e.Time = start.Add(time.Duration(i) * time.Minute)
+		line, _ := json.Marshal(e)
+		b.Write(append(line, '\n'))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// useFakeKinds replaces the kinds and the execution by fakes, the members in diverged refuse the undo
+func useFakeKinds(t *testing.T, diverged map[string]bool, applyErr error) *[]plan.Operation {
+	t.Helper()
+	applied := []plan.Operation{}
+	savedKinds, savedServices, savedApplyPlan := kinds, services, applyPlan
+	t.Cleanup(func() { kinds, services, applyPlan = savedKinds, savedServices, savedApplyPlan })
+
+	kinds = map[string]kind{
+		plan.GoogleMembership: {service: "fake", plan: func(op plan.Operation) (*plan.Operation, error) {
+			if diverged[op.Member] {
+				return nil, errors.New(op.Member + " is no longer a member of the group team")
+			}
+			undo := op
+			undo.Mode = plan.RemoveMode
+			if op.Mode == plan.RemoveMode {
+				undo.Mode = plan.AddMode
+			}
+			return &undo, nil
+		}, settled: func(e *audit.Entry) error {
+			if e.AzureJobID == "job-running" {
+				return errors.New("job job-running still running with status running, retry once it completed")
+			}
+			return nil
+		}},
+	}
+	services = map[string]func() error{"fake": func() error { return nil }}
+	applyPlan = func(p *plan.Plan) ([]apply.OperationResult, error) {
+		op := p.Operations[0]
+		audit.Record(audit.Entry{Action: "google membership " + op.Mode, Targets: []string{op.Group, op.Member}, Operation: &op}, applyErr)
+		if applyErr != nil {
+			return []apply.OperationResult{{Operation: op, Err: applyErr}}, errors.New("operation 1 failed")
+		}
+		applied = append(applied, op)
+		return []apply.OperationResult{{Operation: op, Output: "done"}}, nil
+	}
+	return &applied
+}
+
+func Test_commandUndoConfig_Execute(t *testing.T) {
+	entries := []audit.Entry{
+		{ID: "add-alice", Action: "google membership add", Result: audit.ResultSucceeded, Operation: membership(plan.AddMode, "alice@example.com")},
+		{ID: "remove-bob", Action: "google membership remove", Result: audit.ResultSucceeded, Operation: membership(plan.RemoveMode, "bob@example.com")},
+		{ID: "add-carol", Action: "google membership add", Result: audit.ResultFailed, Error: "http call error", Operation: membership(plan.AddMode, "carol@example.com")},
+		{ID: "publish", Action: "azure offer publish-preview", Result: audit.ResultSucceeded},
+	}
+	tests := []struct {
+		name        string
+		args        []string
+		entries     []audit.Entry
+		diverged    map[string]bool
+		applyErr    error
+		answer      string
+		want        string
+		wantApplied string
+	}{
+		{
+			name:        "undo the last access change",
+			args:        []string{"-y"},
+			entries:     entries,
+			want:        "undid remove-bob google membership remove\nadd bob@example.com to team (groups/team) as MEMBER",
+			wantApplied: "add bob@example.com",
+		},
+		{
+			name:        "undo the entry once confirmed",
+			args:        []string{"add-alice"},
+			entries:     entries,
+			answer:      "y\n",
+			want:        "undid add-alice",
+			wantApplied: "remove alice@example.com",
+		},
+		{
+			name:    "undo cancelled",
+			args:    []string{"add-alice"},
+			entries: entries,
+			answer:  "n\n",
+			want:    "undo cancelled, nothing was changed",
+		},
+		{
+			name:     "refuse a diverged state",
+			args:     []string{"-y", "add-alice"},
+			entries:  entries,
+			diverged: map[string]bool{"alice@example.com": true},
+			want:     "the state diverged since add-alice, refusing to undo it: alice@example.com is no longer a member of the group team",
+		},
+		{
+			name:    "refuse while the job of the change is still running",
+			args:    []string{"-y", "add-dave"},
+			entries: append(entries, audit.Entry{ID: "add-dave", Action: "google membership add", Result: audit.ResultSucceeded, AzureJobID: "job-running", Operation: membership(plan.AddMode, "dave@example.com")}),
+			want:    "unable to undo add-dave yet: job job-running still running with status running",
+		},
+		{
+			name:    "refuse a failed operation",
+			args:    []string{"-y", "add-carol"},
+			entries: entries,
+			want:    "add-carol google membership add failed, there is nothing to undo",
+		},
+		{
+			name:    "refuse an operation which cannot be undone",
+			args:    []string{"-y", "publish"},
+			entries: entries,
+			want:    "publish azure offer publish-preview cannot be undone",
+		},
+		{
+			name:    "refuse an operation already undone",
+			args:    []string{"-y", "remove-bob"},
+			entries: append(entries, audit.Entry{ID: "undo-bob", Action: "google membership add", Result: audit.ResultSucceeded, Operation: membership(plan.AddMode, "bob@example.com"), Undoes: "remove-bob"}),
+			want:    "remove-bob was already undone by undo-bob",
+		},
+		{
+			name:        "skip the undone operations and the undos",
+			args:        []string{"-y"},
+			entries:     append(entries, audit.Entry{ID: "undo-bob", Action: "google membership add", Result: audit.ResultSucceeded, Operation: membership(plan.AddMode, "bob@example.com"), Undoes: "remove-bob"}),
+			want:        "undid add-alice",
+			wantApplied: "remove alice@example.com",
+		},
+		{
+			name:    "unknown entry",
+			args:    []string{"-y", "unknown"},
+			entries: entries,
+			want:    "audit entry unknown not found",
+		},
+		{
+			name:    "nothing to undo",
+			args:    []string{"-y"},
+			entries: entries[2:],
+			want:    "no access change left to undo in the audit log",
+		},
+		{
+			name:     "undo failed",
+			args:     []string{"-y", "add-alice"},
+			entries:  entries,
+			applyErr: errors.New("http call error"),
+			want:     "unable to undo add-alice",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useAuditLog(t, tt.entries...)
+			applied := useFakeKinds(t, tt.diverged, tt.applyErr)
+			stdin, stderr = strings.NewReader(tt.answer), &strings.Builder{}
+			t.Cleanup(func() { stdin, stderr = os.Stdin, os.Stderr })
+
+			c := fetchCommandUndoConfig()
+			c.GetFlagSet().Parse(tt.args)
+			if got := c.Execute(); !strings.Contains(got, tt.want) {
+				t.Errorf("commandUndoConfig.Execute() = %v, want %v", got, tt.want)
+			}
+
+			got := []string{}
+			for _, op := range *applied {
+				got = append(got, op.Mode+" "+op.Member)
+			}
+			if strings.Join(got, ",") != tt.wantApplied {
+				t.Errorf("commandUndoConfig.Execute() applied %v, want %v", got, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestUndo_RecordsTheUndoneEntry(t *testing.T) {
+	entries := []audit.Entry{
+		{ID: "add-alice", Action: "google membership add", Result: audit.ResultSucceeded, Operation: membership(plan.AddMode, "alice@example.com")},
+	}
+	useAuditLog(t, entries...)
+	useFakeKinds(t, nil, nil)
+
+	e, err := Select("")
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	undo, err := Prepare(e)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if _, err := Undo(e, undo); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+
+	if _, err := Select(""); err == nil {
+		t.Errorf("Select() after the undo returned no error, want nothing left to undo")
+	}
+	if _, err := Select("add-alice"); err == nil || !strings.Contains(err.Error(), "was already undone by") {
+		t.Errorf("Select() error = %v, want already undone", err)
+	}
+}
@@ -0,0 +1,13 @@
+package undo
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandUndoConfig commandUndoConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	return &CommandModule{
+		CommandUndoConfig: fetchCommandUndoConfig(),
+	}
+}
@@ -0,0 +1,117 @@
+package undo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aryannr97/unfold/pkg/apply"
+	"github.com/aryannr97/unfold/pkg/audit"
+	"github.com/aryannr97/unfold/pkg/azure"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/google"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// kind reverts the executed operations of a kind
+type kind struct {
+	// service is the module started before the state is checked
+	service string
+	// plan checks the state is still as the executed operation left it and returns the operation reverting it
+	plan func(op plan.Operation) (*plan.Operation, error)
+	// settled checks the change of the entry has completed before its state is checked, when set
+	settled func(e *audit.Entry) error
+}
+
+var (
+	// kinds are the operations which can be undone
+	kinds = map[string]kind{
+		plan.AzurePrivateAudience: {
+			service: commands.Azure,
+			plan:    azure.PlanUndo,
+			settled: func(e *audit.Entry) error { return azure.CheckJobCompleted(e.AzureJobID) },
+		},
+		plan.GoogleMembership: {service: commands.Google, plan: google.PlanUndo},
+	}
+
+	// services start the modules used by the operations
+	services = map[string]func() error{
+		commands.Azure:  azure.StartService,
+		commands.Google: google.StartService,
+	}
+
+	// applyPlan executes the operations reverting the entry
+	applyPlan = apply.Apply
+)
+
+// Select returns the entry of the audit log to undo, the last access change not undone yet when the id is empty
+func Select(id string) (*audit.Entry, error) {
+	entries, err := audit.List(audit.Filter{})
+	if err != nil {
+		return nil, err
+	}
+	undone := map[string]string{}
+	for _, e := range entries {
+		if e.Undoes != "" && e.Result == audit.ResultSucceeded {
+			undone[e.Undoes] = e.ID
+		}
+	}
+
+	if id == "" {
+		for i := range entries {
+			e := &entries[i]
+			if e.Operation != nil && e.Result == audit.ResultSucceeded && e.Undoes == "" && undone[e.ID] == "" {
+				return e, nil
+			}
+		}
+		return nil, errors.New("no access change left to undo in the audit log")
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		if e.ID != id {
+			continue
+		}
+		switch {
+		case e.Operation == nil:
+			return nil, fmt.Errorf("%s %s cannot be undone, only private audience and group membership changes can", e.ID, e.Action)
+		case e.Result != audit.ResultSucceeded:
+			return nil, fmt.Errorf("%s %s failed, there is nothing to undo", e.ID, e.Action)
+		case undone[e.ID] != "":
+			return nil, fmt.Errorf("%s was already undone by %s", e.ID, undone[e.ID])
+		}
+		return e, nil
+	}
+	return nil, fmt.Errorf("audit entry %s not found", id)
+}
+
+// Prepare starts the module of the entry and returns the operation reverting it,
+// refused when the state diverged since the entry was recorded
+func Prepare(e *audit.Entry) (*plan.Operation, error) {
+	k, ok := kinds[e.Operation.Kind]
+	if !ok {
+		return nil, fmt.Errorf("%s cannot be undone, unsupported kind %s", e.ID, e.Operation.Kind)
+	}
+	if err := services[k.service](); err != nil {
+		return nil, fmt.Errorf("unable to start the %s service: %w", k.service, err)
+	}
+	if k.settled != nil {
+		if err := k.settled(e); err != nil {
+			return nil, fmt.Errorf("unable to undo %s yet: %w", e.ID, err)
+		}
+	}
+	undo, err := k.plan(*e.Operation)
+	if err != nil {
+		return nil, fmt.Errorf("the state diverged since %s, refusing to undo it: %w", e.ID, err)
+	}
+	return undo, nil
+}
+
+// Undo executes the operation reverting the entry and records it in the audit log as undoing the entry
+func Undo(e *audit.Entry, undo *plan.Operation) (string, error) {
+	audit.Undoing(e.ID)
+	results, err := applyPlan(plan.New(*undo))
+	if err != nil {
+		return "", results[len(results)-1].Err
+	}
+	return results[0].Output, nil
+}
@@ -0,0 +1,274 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/azure"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/directory"
+	"github.com/aryannr97/unfold/pkg/google"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// Options holds the settings applied to every step of a run
+type Options struct {
+	// JobTimeout is how long an azure step waits for its job to complete, 0 does not wait
+	JobTimeout time.Duration
+}
+
+// action is a command usable as workflow step
+type action struct {
+	// service is the module started before the steps run
+	service string
+	// flags are the flags accepted in the with block of the step
+	flags []string
+	// validate checks the flags of the step before anything runs
+	validate func(with map[string]string) error
+	// apply runs the step and returns its output along with the flags of the compensating step
+	apply func(with map[string]string, opts Options) (string, map[string]string, error)
+	// plan resolves the step into the operation executed by unfold apply
+	plan func(with map[string]string) (*plan.Operation, error)
+}
+
+var (
+	// actions are the commands usable as workflow step
+	actions = map[string]action{
+		commands.Azure + " " + commands.Configure: {
+			service:  commands.Azure,
+			flags:    []string{"o", "sid", "tid", "r"},
+			validate: validateAzureConfigure,
+			apply:    azureConfigure,
+			plan:     planAzureConfigure,
+		},
+		commands.Google + " " + commands.Configure: {
+			service:  commands.Google,
+			flags:    []string{"g", "id", "role", "r"},
+			validate: validateGoogleConfigure,
+			apply:    googleConfigure,
+			plan:     planGoogleConfigure,
+		},
+	}
+
+	// services start the modules used by the steps
+	services = map[string]func() error{
+		commands.Azure:  azure.StartService,
+		commands.Google: google.StartService,
+	}
+)
+
+const (
+	// plansKey holds the plans of a compensating azure configure step, restricted to those changed by the step
+	plansKey = "plans"
+	// jobKey holds the job of the compensated azure configure step when it was not seen completing,
+	// the compensating step waits for it before reverting the change
+	jobKey = "job"
+)
+
+// waitForJob waits for the private audience job to complete
+var waitForJob = azure.WaitForJob
+
+// removeFlag returns the value of the r flag of the step
+func removeFlag(with map[string]string) (bool, error) {
+	if with["r"] == "" {
+		return false, nil
+	}
+	r, err := strconv.ParseBool(with["r"])
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q of flag r, use true|false", with["r"])
+	}
+	return r, nil
+}
+
+// inverse returns the flags of the step with the r flag flipped and the overrides applied
+func inverse(with map[string]string, remove bool, overrides map[string]string) map[string]string {
+	inv := maps.Clone(with)
+	inv["r"] = strconv.FormatBool(!remove)
+	maps.Copy(inv, overrides)
+	return inv
+}
+
+// validateAzureConfigure checks the flags of an azure configure step
+func validateAzureConfigure(with map[string]string) error {
+	if with["o"] == "" {
+		return errors.New("offer o cannot be empty")
+	}
+	if (with["sid"] == "") == (with["tid"] == "") {
+		return errors.New("provide exactly one of sid|tid")
+	}
+	_, err := removeFlag(with)
+	return err
+}
+
+// azureConfigure adds or removes the subscription or tenant to the private audience of the offer
+// and waits for the job to complete. The compensating step reverts the change on the plans it changed,
+// there is none when the private audience of every plan already was as requested.
+func azureConfigure(with map[string]string, opts Options) (string, map[string]string, error) {
+	remove, err := removeFlag(with)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := awaitCompensatedJob(with, opts); err != nil {
+		return "", nil, err
+	}
+	mode := plan.AddMode
+	if remove {
+		mode = plan.RemoveMode
+	}
+	audType, id := "sub", with["sid"]
+	if id == "" {
+		audType, id = "tenant", with["tid"]
+	}
+
+	op, err := azure.PlanConfiguration(with["o"], id, audType, mode)
+	if err != nil {
+		return "", nil, err
+	}
+	if with[plansKey] != "" {
+		if op.PlanIDs, err = restrictPlans(op.PlanIDs, strings.Split(with[plansKey], ",")); err != nil {
+			return "", nil, err
+		}
+	}
+
+	res, err := azure.ApplyPlannedConfiguration(*op)
+	if err != nil {
+		return "", nil, err
+	}
+	changed := slices.DeleteFunc(slices.Clone(op.PlanIDs), func(planID string) bool { return slices.Contains(res.UnchangedPlans, planID) })
+	if len(changed) == 0 {
+		return fmt.Sprintf("job %s submitted, the private audience of every plan already was as requested", res.AzureJobID), nil, nil
+	}
+	overrides := map[string]string{plansKey: strings.Join(changed, ","), jobKey: res.AzureJobID}
+	return awaitJob(res.AzureJobID, inverse(with, remove, overrides), opts)
+}
+
+// awaitJob waits for the job of an azure configure step and returns the output of the step along with its compensation.
+// The compensation keeps the job while it was not seen completing, and is returned along with the error of a job
+// completing without success as it may have changed some of the plans.
+func awaitJob(jobID string, compensation map[string]string, opts Options) (string, map[string]string, error) {
+	output := fmt.Sprintf("job %s submitted", jobID)
+	if opts.JobTimeout <= 0 {
+		return output, compensation, nil
+	}
+	job, err := waitForJob(jobID, opts.JobTimeout)
+	if err != nil {
+		// the job may still complete, the compensating step waits for it
+		return output, compensation, err
+	}
+	delete(compensation, jobKey)
+	if !strings.EqualFold(job.JobResult, "succeeded") && !strings.EqualFold(job.JobResult, "success") {
+		return output, compensation, fmt.Errorf("job %s completed with result %s", job.JobID, job.JobResult)
+	}
+	return fmt.Sprintf("job %s %s", job.JobID, job.JobResult), compensation, nil
+}
+
+// awaitCompensatedJob waits for the job of the compensated step before its change is reverted,
+// as Partner Center would run both jobs on the same plans. The compensation fails while the job runs.
+func awaitCompensatedJob(with map[string]string, opts Options) error {
+	jobID := with[jobKey]
+	if jobID == "" {
+		return nil
+	}
+	if _, err := waitForJob(jobID, opts.JobTimeout); err != nil {
+		return fmt.Errorf("the job %s of the step has not completed, revert its change once it has: %w", jobID, err)
+	}
+	return nil
+}
+
+// planAzureConfigure resolves the offer of the step into the private audience operation
+func planAzureConfigure(with map[string]string) (*plan.Operation, error) {
+	remove, err := removeFlag(with)
+	if err != nil {
+		return nil, err
+	}
+	mode := plan.AddMode
+	if remove {
+		mode = plan.RemoveMode
+	}
+	audType, id := "sub", with["sid"]
+	if id == "" {
+		audType, id = "tenant", with["tid"]
+	}
+	return azure.PlanConfiguration(with["o"], id, audType, mode)
+}
+
+// restrictPlans returns the plans of the compensated step, which must still be plans of the offer
+func restrictPlans(offerPlans, plans []string) ([]string, error) {
+	for _, planID := range plans {
+		if !slices.Contains(offerPlans, planID) {
+			return nil, fmt.Errorf("%s is no longer a plan of the offer", planID)
+		}
+	}
+	return plans, nil
+}
+
+// validateGoogleConfigure checks the flags of a google configure step
+func validateGoogleConfigure(with map[string]string) error {
+	if with["g"] == "" || with["id"] == "" {
+		return errors.New("group g and email id cannot be empty")
+	}
+	if role := with["role"]; role != "" && !slices.Contains(google.NewGroupProvider().Roles(), strings.ToUpper(role)) {
+		return fmt.Errorf("invalid role %s, use %s", role, strings.Join(google.NewGroupProvider().Roles(), "|"))
+	}
+	_, err := removeFlag(with)
+	return err
+}
+
+// googleConfigure adds the member to the group with the role or removes it, checking the membership first.
+// The compensating step of a removal restores the highest role of the member, there is none when the
+// member already was in the requested state.
+func googleConfigure(with map[string]string, _ Options) (string, map[string]string, error) {
+	remove, err := removeFlag(with)
+	if err != nil {
+		return "", nil, err
+	}
+	provider := google.NewGroupProvider()
+
+	m, err := provider.CheckMember(with["g"], with["id"])
+	if err != nil && !errors.Is(err, directory.ErrMemberNotFound) {
+		return "", nil, err
+	}
+
+	if remove {
+		if m == nil {
+			return fmt.Sprintf("%s already is not a member of %s", with["id"], with["g"]), nil, nil
+		}
+		if err := provider.RemoveMember(with["g"], with["id"]); err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("removed %s from %s", with["id"], with["g"]), inverse(with, remove, map[string]string{"role": google.HighestRole(m.Roles)}), nil
+	}
+
+	role := strings.ToUpper(with["role"])
+	if role == "" {
+		role = google.MemberRole
+	}
+	if m != nil {
+		if current := google.HighestRole(m.Roles); current != role {
+			return "", nil, fmt.Errorf("%s already is a member of %s as %s", with["id"], with["g"], current)
+		}
+		return fmt.Sprintf("%s already is a member of %s as %s", with["id"], with["g"], role), nil, nil
+	}
+	if err := provider.AddMember(with["g"], with["id"], role); err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("added %s to %s as %s", with["id"], with["g"], role), inverse(with, remove, nil), nil
+}
+
+// planGoogleConfigure resolves the group and membership of the step into the membership operation
+func planGoogleConfigure(with map[string]string) (*plan.Operation, error) {
+	remove, err := removeFlag(with)
+	if err != nil {
+		return nil, err
+	}
+	role := strings.ToUpper(with["role"])
+	if role == "" {
+		role = google.MemberRole
+	}
+	return google.PlanMembership(with["g"], with["id"], role, remove)
+}
@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/azure"
+)
+
+func Test_restrictPlans(t *testing.T) {
+	offerPlans := []string{"plan/p/plan-a", "plan/p/plan-b"}
+
+	got, err := restrictPlans(offerPlans, []string{"plan/p/plan-b"})
+	if err != nil || !reflect.DeepEqual(got, []string{"plan/p/plan-b"}) {
+		t.Errorf("restrictPlans() = %v, %v, want plan-b", got, err)
+	}
+	if _, err := restrictPlans(offerPlans, []string{"plan/p/plan-c"}); err == nil || err.Error() != "plan/p/plan-c is no longer a plan of the offer" {
+		t.Errorf("restrictPlans() error = %v, want plan-c rejected", err)
+	}
+}
+
+// useFakeJobs replaces the job wait with the given job states, a running job is reported as still running
+func useFakeJobs(t *testing.T, jobs map[string]*azure.MSEnableAccountsRes) {
+	saved := waitForJob
+	t.Cleanup(func() { waitForJob = saved })
+	waitForJob = func(jobID string, _ time.Duration) (*azure.MSEnableAccountsRes, error) {
+		job := jobs[jobID]
+		if job.JobStatus != "completed" {
+			return job, fmt.Errorf("job %s is still %s", jobID, job.JobStatus)
+		}
+		return job, nil
+	}
+}
+
+func Test_awaitJob(t *testing.T) {
+	useFakeJobs(t, map[string]*azure.MSEnableAccountsRes{
+		"job-ok":      {JobID: "job-ok", JobStatus: "completed", JobResult: "succeeded"},
+		"job-failed":  {JobID: "job-failed", JobStatus: "completed", JobResult: "failed"},
+		"job-running": {JobID: "job-running", JobStatus: "running"},
+	})
+	compensation := func(jobID string) map[string]string {
+		return map[string]string{"o": "offer-a", "r": "true", plansKey: "plan/p/plan-a", jobKey: jobID}
+	}
+
+	tests := []struct {
+		name             string
+		jobID            string
+		timeout          time.Duration
+		want             string
+		wantCompensation map[string]string
+		wantErr          string
+	}{
+		{
+			name:             "job succeeded",
+			jobID:            "job-ok",
+			timeout:          time.Minute,
+			want:             "job job-ok succeeded",
+			wantCompensation: map[string]string{"o": "offer-a", "r": "true", plansKey: "plan/p/plan-a"},
+		},
+		{
+			name:             "job failed keeps the compensation of the changed plans",
+			jobID:            "job-failed",
+			timeout:          time.Minute,
+			want:             "job job-failed submitted",
+			wantCompensation: map[string]string{"o": "offer-a", "r": "true", plansKey: "plan/p/plan-a"},
+			wantErr:          "job job-failed completed with result failed",
+		},
+		{
+			name:             "job still running keeps the job",
+			jobID:            "job-running",
+			timeout:          time.Minute,
+			want:             "job job-running submitted",
+			wantCompensation: compensation("job-running"),
+			wantErr:          "job job-running is still running",
+		},
+		{
+			name:             "job not awaited keeps the job",
+			jobID:            "job-running",
+			want:             "job job-running submitted",
+			wantCompensation: compensation("job-running"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotCompensation, err := awaitJob(tt.jobID, compensation(tt.jobID), Options{JobTimeout: tt.timeout})
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Errorf("awaitJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("awaitJob() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotCompensation, tt.wantCompensation) {
+				t.Errorf("awaitJob() compensation = %v, want %v", gotCompensation, tt.wantCompensation)
+			}
+		})
+	}
+}
+
+func Test_awaitCompensatedJob(t *testing.T) {
+	useFakeJobs(t, map[string]*azure.MSEnableAccountsRes{
+		"job-ok":      {JobID: "job-ok", JobStatus: "completed", JobResult: "succeeded"},
+		"job-running": {JobID: "job-running", JobStatus: "running"},
+	})
+
+	if err := awaitCompensatedJob(map[string]string{"o": "offer-a"}, Options{}); err != nil {
+		t.Errorf("awaitCompensatedJob() without job error = %v", err)
+	}
+	if err := awaitCompensatedJob(map[string]string{jobKey: "job-ok"}, Options{}); err != nil {
+		t.Errorf("awaitCompensatedJob() of completed job error = %v", err)
+	}
+	err := awaitCompensatedJob(map[string]string{jobKey: "job-running"}, Options{JobTimeout: time.Minute})
+	if err == nil || !strings.Contains(err.Error(), "the job job-running of the step has not completed") {
+		t.Errorf("awaitCompensatedJob() of running job error = %v, want not completed", err)
+	}
+}
@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+// commandHistoryConfig represents the configuration for the history command
+type commandHistoryConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		Limit *int
+	}
+}
+
+// Execute executes the history command, listing the recent runs or showing the steps of a run
+func (c commandHistoryConfig) Execute() string {
+	id, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	records, err := ListRuns()
+	if err != nil {
+		return fmt.Sprintf("[unfold] unable to read the workflow runs %s", helpers.RedValue(err.Error()))
+	}
+
+	if id != "" {
+		for i := range records {
+			if records[i].ID == id {
+				return fmt.Sprintf("[unfold] %s", formatRun(&records[i]))
+			}
+		}
+		return fmt.Sprintf("[unfold] workflow run %s not found", id)
+	}
+
+	if len(records) == 0 {
+		return "[unfold] no workflow runs recorded"
+	}
+	if *c.Opts.Limit > 0 && len(records) > *c.Opts.Limit {
+		records = records[:*c.Opts.Limit]
+	}
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		name := r.Workflow
+		if name == "" {
+			name = r.File
+		}
+		lines = append(lines, fmt.Sprintf("%s  %-20s %-16s %d step(s)  %s", r.ID, name, stepStatusValue(r.Status), len(r.Steps), r.User))
+	}
+	return fmt.Sprintf("[unfold] workflow runs\n%s", strings.Join(lines, "\n"))
+}
+
+// GetFlagSet returns the flag set for the history command
+func (c commandHistoryConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandHistoryConfig fetches the command history config
+func fetchCommandHistoryConfig() commandHistoryConfig {
+	flagSet := flag.NewFlagSet(commands.History, flag.ContinueOnError)
+	return commandHistoryConfig{
+		Opts: struct {
+			Limit *int
+		}{
+			Limit: flagSet.Int("n", 20, "number of recent runs to list, 0 lists all"),
+		},
+		FlagSet: flagSet,
+	}
+}
@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+)
+
+func Test_commandHistoryConfig_Execute(t *testing.T) {
+	t.Setenv("UNFOLD_STATE_DIR", t.TempDir())
+	steps := []StepResult{{Name: "engineer", Run: "google configure", Status: StatusSucceeded}}
+	first := &RunRecord{ID: "20261001T100000Z-0a1b", Workflow: "onboard", Status: StatusSucceeded, StartedAt: time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC), Steps: steps}
+	second := &RunRecord{ID: "20261002T100000Z-2c3d", Workflow: "offboard", Status: StatusFailed, StartedAt: time.Date(2026, 10, 2, 10, 0, 0, 0, time.UTC), Steps: steps}
+	recordRun(first)
+	recordRun(second)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		notWant string
+	}{
+		{
+			name: "list runs most recent first",
+			args: []string{},
+			want: []string{"workflow runs\n" + second.ID + "  offboard", first.ID + "  onboard"},
+		},
+		{
+			name:    "list limited runs",
+			args:    []string{"-n", "1"},
+			want:    []string{second.ID},
+			notWant: first.ID,
+		},
+		{
+			name: "show run",
+			args: []string{first.ID},
+			want: []string{"workflow onboard " + helpers.GreenValue(StatusSucceeded), " 1. engineer [google configure]"},
+		},
+		{
+			name: "unknown run",
+			args: []string{"20000101T000000Z-0000"},
+			want: []string{"workflow run 20000101T000000Z-0000 not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCommandModule().CommandHistoryConfig
+			c.GetFlagSet().Parse(tt.args)
+			got := c.Execute()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandHistoryConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Errorf("commandHistoryConfig.Execute() = %v, not want %v", got, tt.notWant)
+			}
+		})
+	}
+}
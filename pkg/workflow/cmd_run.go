@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/cache"
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+	"github.com/aryannr97/unfold/pkg/spinner"
+)
+
+var (
+	// stdin is the reader the rollback confirmation is read from
+	stdin io.Reader = os.Stdin
+	// stderr is the writer the rollback confirmation is asked on
+	stderr io.Writer = os.Stderr
+)
+
+// varsFlag collects the repeated -var name=value flags
+type varsFlag map[string]string
+
+// String returns the variables as name=value pairs
+func (v varsFlag) String() string {
+	pairs := make([]string, 0, len(v))
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Set adds the name=value variable
+func (v varsFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid variable %q, use name=value", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// commandRunConfig represents the configuration for the run command
+type commandRunConfig struct {
+	FlagSet *flag.FlagSet
+	Opts    struct {
+		Vars       varsFlag
+		Rollback   *bool
+		NoRollback *bool
+		JobTimeout *time.Duration
+		PlanOut    *string
+	}
+}
+
+// Execute executes the run command
+func (c commandRunConfig) Execute() string {
+	file, err := helpers.ParseAction(c.FlagSet)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+	if file == "" {
+		return "[unfold] provide the workflow file, e.g. unfold workflow run onboard.yaml"
+	}
+	if *c.Opts.Rollback && *c.Opts.NoRollback {
+		return "[unfold] --rollback and --no-rollback are mutually exclusive"
+	}
+
+	w, err := Load(file, c.Opts.Vars)
+	if err != nil {
+		return fmt.Sprintf("[unfold] %s", err.Error())
+	}
+
+	if *c.Opts.PlanOut != "" {
+		ops, err := Plan(w)
+		if err != nil {
+			return fmt.Sprintf("[unfold] unable to plan the workflow %s\n%s", w.Name, helpers.RedValue(err.Error()))
+		}
+		summary, err := plan.Save(*c.Opts.PlanOut, ops...)
+		if err != nil {
+			return fmt.Sprintf("[unfold] unable to write the plan %s", helpers.RedValue(err.Error()))
+		}
+		return fmt.Sprintf("[unfold] %s", summary)
+	}
+
+	runner := Runner{Options: Options{JobTimeout: *c.Opts.JobTimeout}, Confirm: c.confirm}
+	return fmt.Sprintf("[unfold] %s", formatRun(runner.Run(w, file)))
+}
+
+// confirm asks whether the steps of the failed run which changed the access are rolled back, unless decided by the flags
+func (c commandRunConfig) confirm(completed int) bool {
+	switch {
+	case *c.Opts.Rollback:
+		return true
+	case *c.Opts.NoRollback:
+		return false
+	}
+	// the spinner would overwrite the prompt and hide the answer
+	defer spinner.Pause()()
+	fmt.Fprintf(stderr, "\n[unfold] the workflow failed, roll back the %d step(s) which changed the access? [y/N] ", completed)
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// formatRun renders the outcome of the run and of each of its steps
+func formatRun(record *RunRecord) string {
+	var b strings.Builder
+	status := helpers.GreenValue(record.Status)
+	if record.Status != StatusSucceeded {
+		status = helpers.RedValue(record.Status)
+	}
+	name := record.Workflow
+	if name == "" {
+		name = record.File
+	}
+	fmt.Fprintf(&b, "workflow %s %s, run %s", name, status, record.ID)
+	if record.Error != "" {
+		fmt.Fprintf(&b, ": %s", record.Error)
+	}
+	for i, step := range record.Steps {
+		fmt.Fprintf(&b, "\n %d. %s [%s] %s", i+1, step.Name, step.Run, stepStatusValue(step.Status))
+		if step.Output != "" {
+			fmt.Fprintf(&b, "\n    %s", step.Output)
+		}
+		if step.Error != "" {
+			fmt.Fprintf(&b, "\n    %s", helpers.RedValue(step.Error))
+		}
+		if step.CompensationOutput != "" {
+			fmt.Fprintf(&b, "\n    rollback: %s", step.CompensationOutput)
+		}
+		if step.CompensationError != "" {
+			fmt.Fprintf(&b, "\n    rollback: %s", helpers.RedValue(step.CompensationError))
+		}
+	}
+	return b.String()
+}
+
+// stepStatusValue colors the status of a step
+func stepStatusValue(status string) string {
+	switch status {
+	case StatusSucceeded, StatusCompensated:
+		return helpers.GreenValue(status)
+	case StatusFailed, StatusCompensationFailed:
+		return helpers.RedValue(status)
+	}
+	return status
+}
+
+// GetFlagSet returns the flag set for the run command
+func (c commandRunConfig) GetFlagSet() *flag.FlagSet {
+	return c.FlagSet
+}
+
+// fetchCommandRunConfig fetches the command run config
+func fetchCommandRunConfig() commandRunConfig {
+	flagSet := flag.NewFlagSet(commands.Run, flag.ContinueOnError)
+	cache.BindFlags(flagSet)
+	c := commandRunConfig{FlagSet: flagSet}
+	c.Opts.Vars = varsFlag{}
+	flagSet.Var(c.Opts.Vars, "var", "set a variable of the workflow as name=value, repeatable")
+	c.Opts.Rollback = flagSet.Bool("rollback", false, "roll back the completed steps on failure without asking")
+	c.Opts.NoRollback = flagSet.Bool("no-rollback", false, "keep the completed steps on failure without asking")
+	c.Opts.JobTimeout = flagSet.Duration("job-timeout", 15*time.Minute, "how long an azure step waits for its job to complete, 0 does not wait")
+	c.Opts.PlanOut = flagSet.String("plan-out", "", "write the resolved operations of the steps to the plan file instead of running them")
+	return c
+}
@@ -0,0 +1,138 @@
+package workflow
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+func Test_commandRunConfig_Execute(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "onboard.yaml")
+	os.WriteFile(file, []byte(`
+name: onboard
+vars:
+  offer: offer-a
+steps:
+  - name: private audience
+    run: azure configure
+    with: {o: "${offer}", tid: "${tenant}"}
+  - name: engineer
+    run: google configure
+    with: {g: partners, id: "${engineer}"}
+`), 0o600)
+
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		want  []string
+	}{
+		{
+			name: "run succeeds",
+			args: []string{file, "-var", "tenant=t-1", "-var", "engineer=eng@customer.com"},
+			want: []string{
+				"workflow onboard " + helpers.GreenValue(StatusSucceeded) + ", run ",
+				" 1. private audience [azure configure] " + helpers.GreenValue(StatusSucceeded) + "\n    azure configure done",
+				" 2. engineer [google configure] " + helpers.GreenValue(StatusSucceeded),
+			},
+		},
+		{
+			name:  "failure rolled back once confirmed",
+			args:  []string{file, "-var", "tenant=t-1", "-var", "engineer=fail"},
+			input: "y\n",
+			want: []string{
+				"workflow onboard " + helpers.RedValue(StatusRolledBack) + ", run ",
+				": step 2 engineer failed",
+				" 1. private audience [azure configure] " + helpers.GreenValue(StatusCompensated) + "\n    azure configure done\n    rollback: azure configure done",
+				" 2. engineer [google configure] " + helpers.RedValue(StatusFailed) + "\n    " + helpers.RedValue("google configure failed"),
+			},
+		},
+		{
+			name:  "failure kept when not confirmed",
+			args:  []string{file, "-var", "tenant=t-1", "-var", "engineer=fail"},
+			input: "\n",
+			want:  []string{"workflow onboard " + helpers.RedValue(StatusFailed), " 1. private audience [azure configure] " + helpers.GreenValue(StatusSucceeded)},
+		},
+		{
+			name: "failure rolled back with the flag",
+			args: []string{file, "--rollback", "-var", "tenant=t-1", "-var", "engineer=fail"},
+			want: []string{"workflow onboard " + helpers.RedValue(StatusRolledBack)},
+		},
+		{
+			name: "failure kept with the flag",
+			args: []string{file, "--no-rollback", "-var", "tenant=t-1", "-var", "engineer=fail"},
+			want: []string{"workflow onboard " + helpers.RedValue(StatusFailed)},
+		},
+		{
+			name: "plan written instead of running",
+			args: []string{file, "-var", "tenant=t-1", "-var", "engineer=eng@customer.com", "--plan-out", filepath.Join(dir, "plan.json")},
+			want: []string{"plan with 2 operation(s) written to " + filepath.Join(dir, "plan.json")},
+		},
+		{
+			name: "plan refused when a step cannot be planned",
+			args: []string{file, "-var", "tenant=t-1", "-var", "engineer=fail", "--plan-out", filepath.Join(dir, "refused.json")},
+			want: []string{"unable to plan the workflow onboard", "step 2 engineer: google configure cannot be planned"},
+		},
+		{
+			name: "unset variables",
+			args: []string{file, "-var", "tenant=t-1"},
+			want: []string{"variables engineer are not set, use -var name=value"},
+		},
+		{
+			name: "invalid variable",
+			args: []string{file, "-var", "tenant"},
+			want: []string{`invalid variable "tenant", use name=value`},
+		},
+		{
+			name: "conflicting rollback flags",
+			args: []string{file, "--rollback", "--no-rollback"},
+			want: []string{"--rollback and --no-rollback are mutually exclusive"},
+		},
+		{
+			name: "missing file",
+			args: []string{filepath.Join(dir, "missing.yaml")},
+			want: []string{"no such file or directory"},
+		},
+		{
+			name: "file not provided",
+			args: []string{},
+			want: []string{"provide the workflow file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := useFakeActions(t)
+			stdin, stderr = strings.NewReader(tt.input), io.Discard
+			t.Cleanup(func() { stdin, stderr = os.Stdin, os.Stderr })
+
+			c := NewCommandModule().CommandRunConfig
+			c.GetFlagSet().Parse(tt.args)
+			got := c.Execute()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("commandRunConfig.Execute() = %v, want %v", got, want)
+				}
+			}
+			if strings.HasPrefix(tt.name, "plan") && len(*calls) != 0 {
+				t.Errorf("commandRunConfig.Execute() ran steps %v instead of planning them", *calls)
+			}
+		})
+	}
+
+	p, err := plan.Read(filepath.Join(dir, "plan.json"))
+	if err != nil {
+		t.Fatalf("plan.Read() error = %v", err)
+	}
+	if len(p.Operations) != 2 || p.Operations[0].Kind != "azure configure" || p.Operations[1].Member != "map[g:partners id:eng@customer.com]" {
+		t.Errorf("plan operations = %+v, want the steps in order", p.Operations)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "refused.json")); !os.IsNotExist(err) {
+		t.Errorf("refused plan was written, stat error = %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package workflow
+
+// CommandModule represents the collection of different command configs
+type CommandModule struct {
+	CommandRunConfig     commandRunConfig
+	CommandHistoryConfig commandHistoryConfig
+}
+
+// NewCommandModule returns the command module
+func NewCommandModule() *CommandModule {
+	return &CommandModule{
+		CommandRunConfig:     fetchCommandRunConfig(),
+		CommandHistoryConfig: fetchCommandHistoryConfig(),
+	}
+}
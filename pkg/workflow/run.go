@@ -0,0 +1,213 @@
+package workflow
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aryannr97/unfold/pkg/helpers"
+	"github.com/aryannr97/unfold/pkg/plan"
+	"github.com/aryannr97/unfold/pkg/state"
+)
+
+const (
+	// runHistoryName is the name of the state file holding the workflow runs
+	runHistoryName = "workflow-runs"
+	// maxRunRecords bounds the run history, the oldest runs are dropped first
+	maxRunRecords = 100
+
+	// StatusSucceeded is the status of a step or run which completed
+	StatusSucceeded = "succeeded"
+	// StatusFailed is the status of a step or run which failed
+	StatusFailed = "failed"
+	// StatusSkipped is the status of a step not run because an earlier step failed
+	StatusSkipped = "skipped"
+	// StatusCompensated is the status of a completed step reverted after a failure
+	StatusCompensated = "compensated"
+	// StatusCompensationFailed is the status of a completed step which could not be reverted
+	StatusCompensationFailed = "compensation-failed"
+	// StatusRolledBack is the status of a failed run whose completed steps were reverted
+	StatusRolledBack = "rolled-back"
+	// StatusRollbackFailed is the status of a failed run whose completed steps were not all reverted
+	StatusRollbackFailed = "rollback-failed"
+)
+
+// StepResult represents the outcome of a step of a run
+type StepResult struct {
+	Name      string            `json:"name"`
+	Run       string            `json:"run"`
+	With      map[string]string `json:"with,omitempty"`
+	Status    string            `json:"status"`
+	Output    string            `json:"output,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	StartedAt *time.Time        `json:"startedAt,omitempty"`
+	EndedAt   *time.Time        `json:"endedAt,omitempty"`
+	// Compensation holds the flags of the step reverting this one, set once the step changed something,
+	// including a failed step whose change may still complete, e.g. an azure job still running at the timeout
+	Compensation       map[string]string `json:"compensation,omitempty"`
+	CompensationOutput string            `json:"compensationOutput,omitempty"`
+	CompensationError  string            `json:"compensationError,omitempty"`
+}
+
+// RunRecord represents a run of a workflow
+type RunRecord struct {
+	ID        string       `json:"id"`
+	Workflow  string       `json:"workflow"`
+	File      string       `json:"file"`
+	User      string       `json:"user"`
+	Status    string       `json:"status"`
+	Error     string       `json:"error,omitempty"`
+	StartedAt time.Time    `json:"startedAt"`
+	EndedAt   time.Time    `json:"endedAt"`
+	Steps     []StepResult `json:"steps"`
+}
+
+// Runner runs the steps of workflows
+type Runner struct {
+	Options
+	// Confirm is asked whether the steps of a failed run having a compensation are rolled back
+	Confirm func(completed int) bool
+}
+
+// Run runs the steps of the workflow in order. When a step fails, the remaining steps are skipped
+// and, once confirmed, the steps having a compensation are compensated in reverse order. A step which
+// changed nothing has no compensation. The run is recorded in the local run history.
+func (r Runner) Run(w *Workflow, file string) *RunRecord {
+	record := &RunRecord{
+		Workflow:  w.Name,
+		File:      file,
+		User:      helpers.CurrentUser(),
+		StartedAt: time.Now().UTC(),
+		Steps:     make([]StepResult, len(w.Steps)),
+	}
+	record.ID = runID(record.StartedAt)
+	for i, step := range w.Steps {
+		record.Steps[i] = StepResult{Name: step.title(), Run: step.Run, With: step.With, Status: StatusSkipped}
+	}
+	defer recordRun(record)
+
+	if err := startServices(w); err != nil {
+		record.Status, record.Error = StatusFailed, err.Error()
+		record.EndedAt = time.Now().UTC()
+		return record
+	}
+
+	completed := 0
+	for i, step := range w.Steps {
+		result := &record.Steps[i]
+		startedAt := time.Now().UTC()
+		output, compensation, err := actions[step.Run].apply(step.With, r.Options)
+		endedAt := time.Now().UTC()
+		result.StartedAt, result.EndedAt, result.Output, result.Compensation = &startedAt, &endedAt, output, compensation
+		if compensation != nil {
+			completed++
+		}
+		if err != nil {
+			result.Status, result.Error = StatusFailed, err.Error()
+			record.Status, record.Error = StatusFailed, fmt.Sprintf("step %d %s failed", i+1, result.Name)
+			break
+		}
+		result.Status = StatusSucceeded
+	}
+
+	if record.Status == "" {
+		record.Status = StatusSucceeded
+	} else if completed > 0 && r.Confirm != nil && r.Confirm(completed) {
+		record.Status = r.compensate(w, record)
+	}
+	record.EndedAt = time.Now().UTC()
+	return record
+}
+
+// Plan resolves the steps of the workflow into the operations of a plan, executed in order by unfold apply
+// once reviewed. The plan has no rollback, unfold apply stops at the first failure.
+func Plan(w *Workflow) ([]plan.Operation, error) {
+	if err := startServices(w); err != nil {
+		return nil, err
+	}
+	ops := make([]plan.Operation, 0, len(w.Steps))
+	var errs []error
+	for i, step := range w.Steps {
+		op, err := actions[step.Run].plan(step.With)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("step %d %s: %w", i+1, step.title(), err))
+			continue
+		}
+		ops = append(ops, *op)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// runID returns a unique id of a run started at the time, sortable by start time
+func runID(startedAt time.Time) string {
+	b := make([]byte, 2)
+	rand.Read(b) //nolint:errcheck
+	return fmt.Sprintf("%s-%s", startedAt.Format("20060102T150405Z"), hex.EncodeToString(b))
+}
+
+// compensate reverts the steps having a compensation in reverse order and returns the status of the run
+func (r Runner) compensate(w *Workflow, record *RunRecord) string {
+	status := StatusRolledBack
+	for i := len(record.Steps) - 1; i >= 0; i-- {
+		result := &record.Steps[i]
+		if result.Compensation == nil {
+			continue
+		}
+		output, _, err := actions[w.Steps[i].Run].apply(result.Compensation, r.Options)
+		result.CompensationOutput = output
+		if err != nil {
+			result.Status, result.CompensationError = StatusCompensationFailed, err.Error()
+			status = StatusRollbackFailed
+			continue
+		}
+		result.Status = StatusCompensated
+	}
+	return status
+}
+
+// startServices starts the modules used by the steps of the workflow
+func startServices(w *Workflow) error {
+	started := map[string]bool{}
+	for _, step := range w.Steps {
+		service := actions[step.Run].service
+		if started[service] {
+			continue
+		}
+		if err := services[service](); err != nil {
+			return fmt.Errorf("unable to start the %s service: %w", service, err)
+		}
+		started[service] = true
+	}
+	return nil
+}
+
+// recordRun appends the run to the local run history. The history is best effort,
+// failures to persist the run must not hide the outcome of the already executed steps.
+func recordRun(record *RunRecord) {
+	records := []RunRecord{}
+	state.Update(runHistoryName, &records, func() error { //nolint:errcheck
+		records = append(records, *record)
+		if len(records) > maxRunRecords {
+			records = records[len(records)-maxRunRecords:]
+		}
+		return nil
+	})
+}
+
+// ListRuns returns the runs of the local run history, the most recent first
+func ListRuns() ([]RunRecord, error) {
+	records := []RunRecord{}
+	if err := state.Load(runHistoryName, &records); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartedAt.After(records[j].StartedAt)
+	})
+	return records, nil
+}
@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aryannr97/unfold/pkg/commands"
+	"github.com/aryannr97/unfold/pkg/plan"
+)
+
+// useFakeActions replaces the step commands with fakes recording their calls as "run flags".
+// A step fails when one of its flags has the value fail, a compensation fails on the value stuck.
+// A step changes nothing on the value noop, and fails after its change on the value timeout.
+func useFakeActions(t *testing.T) *[]string {
+	t.Setenv("UNFOLD_STATE_DIR", t.TempDir())
+	calls := []string{}
+	fake := func(run string) func(map[string]string, Options) (string, map[string]string, error) {
+		return func(with map[string]string, _ Options) (string, map[string]string, error) {
+			calls = append(calls, fmt.Sprintf("%s %v", run, with))
+			remove, _ := removeFlag(with)
+			for _, v := range with {
+				switch {
+				case v == "fail" || (v == "stuck" && with["r"] == "true"):
+					return "", nil, errors.New(run + " failed")
+				case v == "noop":
+					return run + " unchanged", nil, nil
+				case v == "timeout" && with["r"] != "true":
+					return run + " submitted", inverse(with, remove, nil), errors.New(run + " timed out")
+				}
+			}
+			return run + " done", inverse(with, remove, nil), nil
+		}
+	}
+
+	fakePlan := func(run string) func(map[string]string) (*plan.Operation, error) {
+		return func(with map[string]string) (*plan.Operation, error) {
+			for _, v := range with {
+				if v == "fail" {
+					return nil, errors.New(run + " cannot be planned")
+				}
+			}
+			mode := plan.AddMode
+			if remove, _ := removeFlag(with); remove {
+				mode = plan.RemoveMode
+			}
+			return &plan.Operation{Kind: run, Mode: mode, Member: fmt.Sprint(with)}, nil
+		}
+	}
+
+	savedActions, savedServices := actions, services
+	t.Cleanup(func() { actions, services = savedActions, savedServices })
+	actions = map[string]action{}
+	for run, a := range savedActions {
+		a.apply, a.plan = fake(run), fakePlan(run)
+		actions[run] = a
+	}
+	services = map[string]func() error{
+		commands.Azure:  func() error { return nil },
+		commands.Google: func() error { return nil },
+	}
+	return &calls
+}
+
+func TestRunner_Run(t *testing.T) {
+	steps := func(ids ...string) *Workflow {
+		w := &Workflow{Name: "onboard", Steps: []Step{{Name: "offer", Run: "azure configure", With: map[string]string{"o": "offer-a", "tid": "t-1"}}}}
+		for _, id := range ids {
+			w.Steps = append(w.Steps, Step{Run: "google configure", With: map[string]string{"g": "partners", "id": id}})
+		}
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		workflow     *Workflow
+		rollback     bool
+		want         string
+		wantStatuses []string
+		wantCalls    []string
+	}{
+		{
+			name:         "all steps succeed",
+			workflow:     steps("a@customer.com"),
+			want:         StatusSucceeded,
+			wantStatuses: []string{StatusSucceeded, StatusSucceeded},
+			wantCalls: []string{
+				"azure configure map[o:offer-a tid:t-1]",
+				"google configure map[g:partners id:a@customer.com]",
+			},
+		},
+		{
+			name:         "failure without rollback",
+			workflow:     steps("a@customer.com", "fail", "b@customer.com"),
+			want:         StatusFailed,
+			wantStatuses: []string{StatusSucceeded, StatusSucceeded, StatusFailed, StatusSkipped},
+			wantCalls: []string{
+				"azure configure map[o:offer-a tid:t-1]",
+				"google configure map[g:partners id:a@customer.com]",
+				"google configure map[g:partners id:fail]",
+			},
+		},
+		{
+			name:         "failure with rollback in reverse order",
+			workflow:     steps("a@customer.com", "fail", "b@customer.com"),
+			rollback:     true,
+			want:         StatusRolledBack,
+			wantStatuses: []string{StatusCompensated, StatusCompensated, StatusFailed, StatusSkipped},
+			wantCalls: []string{
+				"azure configure map[o:offer-a tid:t-1]",
+				"google configure map[g:partners id:a@customer.com]",
+				"google configure map[g:partners id:fail]",
+				"google configure map[g:partners id:a@customer.com r:true]",
+				"azure configure map[o:offer-a r:true tid:t-1]",
+			},
+		},
+		{
+			name:         "steps which changed nothing are not rolled back",
+			workflow:     steps("noop", "a@customer.com", "fail"),
+			rollback:     true,
+			want:         StatusRolledBack,
+			wantStatuses: []string{StatusCompensated, StatusSucceeded, StatusCompensated, StatusFailed},
+			wantCalls: []string{
+				"azure configure map[o:offer-a tid:t-1]",
+				"google configure map[g:partners id:noop]",
+				"google configure map[g:partners id:a@customer.com]",
+				"google configure map[g:partners id:fail]",
+				"google configure map[g:partners id:a@customer.com r:true]",
+				"azure configure map[o:offer-a r:true tid:t-1]",
+			},
+		},
+		{
+			name:         "failed step which changed something is rolled back",
+			workflow:     steps("a@customer.com", "timeout", "b@customer.com"),
+			rollback:     true,
+			want:         StatusRolledBack,
+			wantStatuses: []string{StatusCompensated, StatusCompensated, StatusCompensated, StatusSkipped},
+			wantCalls: []string{
+				"azure configure map[o:offer-a tid:t-1]",
+				"google configure map[g:partners id:a@customer.com]",
+				"google configure map[g:partners id:timeout]",
+				"google configure map[g:partners id:timeout r:true]",
+				"google configure map[g:partners id:a@customer.com r:true]",
+				"azure configure map[o:offer-a r:true tid:t-1]",
+			},
+		},
+		{
+			name:         "failed compensation does not stop the rollback",
+			workflow:     steps("stuck", "fail"),
+			rollback:     true,
+			want:         StatusRollbackFailed,
+			wantStatuses: []string{StatusCompensated, StatusCompensationFailed, StatusFailed},
+			wantCalls: []string{
+				"azure configure map[o:offer-a tid:t-1]",
+				"google configure map[g:partners id:stuck]",
+				"google configure map[g:partners id:fail]",
+				"google configure map[g:partners id:stuck r:true]",
+				"azure configure map[o:offer-a r:true tid:t-1]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := useFakeActions(t)
+			confirmed := 0
+			runner := Runner{Confirm: func(completed int) bool {
+				confirmed = completed
+				return tt.rollback
+			}}
+
+			got := runner.Run(tt.workflow, "onboard.yaml")
+			if got.Status != tt.want {
+				t.Errorf("Runner.Run() status = %v, want %v", got.Status, tt.want)
+			}
+			statuses := []string{}
+			for _, s := range got.Steps {
+				statuses = append(statuses, s.Status)
+			}
+			if !reflect.DeepEqual(statuses, tt.wantStatuses) {
+				t.Errorf("Runner.Run() step statuses = %v, want %v", statuses, tt.wantStatuses)
+			}
+			if !reflect.DeepEqual(*calls, tt.wantCalls) {
+				t.Errorf("Runner.Run() calls = %v, want %v", *calls, tt.wantCalls)
+			}
+			if tt.want != StatusSucceeded && confirmed == 0 {
+				t.Errorf("Runner.Run() did not ask to roll back the completed steps")
+			}
+
+			runs, err := ListRuns()
+			if err != nil || len(runs) != 1 || runs[0].ID != got.ID || runs[0].Status != got.Status {
+				t.Errorf("ListRuns() = %+v, %v, want the recorded run %s", runs, err, got.ID)
+			}
+		})
+	}
+}
+
+func TestRunner_Run_serviceFailure(t *testing.T) {
+	calls := useFakeActions(t)
+	services[commands.Google] = func() error { return errors.New("GOOGLE_KEYFILE is not set") }
+
+	w := &Workflow{Steps: []Step{
+		{Run: "azure configure", With: map[string]string{"o": "offer-a", "tid": "t-1"}},
+		{Run: "google configure", With: map[string]string{"g": "partners", "id": "a@customer.com"}},
+	}}
+	got := Runner{}.Run(w, "onboard.yaml")
+	if got.Status != StatusFailed || !strings.Contains(got.Error, "unable to start the google service: GOOGLE_KEYFILE is not set") {
+		t.Errorf("Runner.Run() = %v %v, want the service failure", got.Status, got.Error)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("Runner.Run() ran steps %v before the services started", *calls)
+	}
+}
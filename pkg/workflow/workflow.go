@@ -0,0 +1,133 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// varRegex matches the ${name} references to the variables of a workflow
+var varRegex = regexp.MustCompile(`\$\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}`)
+
+// Workflow represents a sequence of configure steps across modules, declared in a YAML file
+type Workflow struct {
+	Name string `yaml:"name"`
+	// Vars are the variables referenced as ${name} by the steps, with their default values
+	Vars  map[string]string `yaml:"vars"`
+	Steps []Step            `yaml:"steps"`
+}
+
+// Step represents a single command of a workflow
+type Step struct {
+	Name string `yaml:"name"`
+	// Run is the command of the step, e.g. azure configure
+	Run string `yaml:"run"`
+	// With holds the flags of the command without their leading dash, e.g. o: offer-a
+	With map[string]string `yaml:"with"`
+}
+
+// title returns the name of the step, or its command when unnamed
+func (s Step) title() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.Run
+}
+
+// Load reads the workflow file and resolves the variable references of its steps,
+// the given variables take precedence over the defaults of the file
+func Load(path string, vars map[string]string) (*Workflow, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b, vars)
+}
+
+// Parse decodes the workflow and resolves the variable references of its steps
+func Parse(b []byte, vars map[string]string) (*Workflow, error) {
+	var w Workflow
+	if err := yaml.UnmarshalStrict(b, &w); err != nil {
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+	if len(w.Steps) == 0 {
+		return nil, errors.New("invalid workflow: no steps")
+	}
+
+	values := map[string]string{}
+	for k, v := range w.Vars {
+		values[k] = v
+	}
+	for k, v := range vars {
+		values[k] = v
+	}
+
+	missing := map[string]bool{}
+	var stepMissing bool
+	resolve := func(s string) string {
+		return varRegex.ReplaceAllStringFunc(s, func(ref string) string {
+			name := varRegex.FindStringSubmatch(ref)[1]
+			v := values[name]
+			if v == "" {
+				missing[name] = true
+				stepMissing = true
+			}
+			return v
+		})
+	}
+
+	var errs []error
+	for i := range w.Steps {
+		step := &w.Steps[i]
+		stepMissing = false
+		step.Name = resolve(step.Name)
+		step.Run = strings.Join(strings.Fields(step.Run), " ")
+		for k, v := range step.With {
+			step.With[k] = resolve(v)
+		}
+		// the flags of a step referencing unset variables are only validated once they are set
+		if stepMissing {
+			continue
+		}
+		if err := validateStep(*step); err != nil {
+			errs = append(errs, fmt.Errorf("step %d %s: %w", i+1, step.title(), err))
+		}
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		errs = append(errs, fmt.Errorf("variables %s are not set, use -var name=value", strings.Join(names, ", ")))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// validateStep checks the command of the step is supported and its flags are known
+func validateStep(step Step) error {
+	a, ok := actions[step.Run]
+	if !ok {
+		supported := make([]string, 0, len(actions))
+		for run := range actions {
+			supported = append(supported, run)
+		}
+		sort.Strings(supported)
+		return fmt.Errorf("unsupported command %q, use one of %s", step.Run, strings.Join(supported, "|"))
+	}
+	for k := range step.With {
+		if !slices.Contains(a.flags, k) {
+			return fmt.Errorf("unknown flag %s, %s accepts %s", k, step.Run, strings.Join(a.flags, "|"))
+		}
+	}
+	return a.validate(step.With)
+}
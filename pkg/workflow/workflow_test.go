@@ -0,0 +1,112 @@
+package workflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	onboard := `
+name: onboard
+vars:
+  offer: offer-a
+  tenant: ""
+steps:
+  - name: add ${tenant} to ${offer}
+    run: azure   configure
+    with:
+      o: ${offer}
+      tid: ${ tenant }
+  - run: google configure
+    with:
+      g: partners
+      id: ${engineer}
+      role: manager
+`
+	tests := []struct {
+		name    string
+		yaml    string
+		vars    map[string]string
+		want    []Step
+		wantErr []string
+	}{
+		{
+			name: "variables from the file and the command line",
+			yaml: onboard,
+			vars: map[string]string{"tenant": "t-1", "engineer": "eng@customer.com"},
+			want: []Step{
+				{Name: "add t-1 to offer-a", Run: "azure configure", With: map[string]string{"o": "offer-a", "tid": "t-1"}},
+				{Run: "google configure", With: map[string]string{"g": "partners", "id": "eng@customer.com", "role": "manager"}},
+			},
+		},
+		{
+			name: "command line overrides the defaults",
+			yaml: onboard,
+			vars: map[string]string{"offer": "offer-b", "tenant": "t-1", "engineer": "eng@customer.com"},
+			want: []Step{
+				{Name: "add t-1 to offer-b", Run: "azure configure", With: map[string]string{"o": "offer-b", "tid": "t-1"}},
+				{Run: "google configure", With: map[string]string{"g": "partners", "id": "eng@customer.com", "role": "manager"}},
+			},
+		},
+		{
+			name:    "unset variables",
+			yaml:    onboard,
+			wantErr: []string{"variables engineer, tenant are not set, use -var name=value"},
+		},
+		{
+			name:    "no steps",
+			yaml:    "name: empty",
+			wantErr: []string{"invalid workflow: no steps"},
+		},
+		{
+			name:    "unknown field",
+			yaml:    "steps:\n  - run: azure configure\n    args: {}",
+			wantErr: []string{"invalid workflow", "field args not found"},
+		},
+		{
+			name: "invalid steps",
+			yaml: `
+steps:
+  - run: azure preview
+  - run: azure configure
+    with: {o: offer-a, sid: s-1, tid: t-1}
+  - run: google configure
+    with: {g: partners, id: eng@customer.com, role: admin}
+  - run: google configure
+    with: {g: partners, id: eng@customer.com, r: maybe}
+  - run: google configure
+    with: {g: partners, sid: s-1}
+`,
+			wantErr: []string{
+				`step 1 azure preview: unsupported command "azure preview", use one of azure configure|google configure`,
+				"step 2 azure configure: provide exactly one of sid|tid",
+				"step 3 google configure: invalid role admin, use MEMBER|MANAGER|OWNER",
+				`step 4 google configure: invalid value "maybe" of flag r, use true|false`,
+				"step 5 google configure: unknown flag sid, google configure accepts g|id|role|r",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.yaml), tt.vars)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("Parse() error = nil, want %v", tt.wantErr)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("Parse() error = %v, want %v", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Steps, tt.want) {
+				t.Errorf("Parse() steps = %+v, want %+v", got.Steps, tt.want)
+			}
+		})
+	}
+}